@@ -0,0 +1,138 @@
+package palantir
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AccessibleEnvVar enables AccessibleMode for every handler when set to "1"
+const AccessibleEnvVar = "PALANTIR_ACCESSIBLE"
+
+// accessibleProgressStep is the percentage interval at which progress is announced in
+// accessible mode, so screen readers are not flooded with updates
+const accessibleProgressStep = 10
+
+// accessibleLabels are the words that introduce each level's messages in accessible mode
+var accessibleLabels = map[OutputLevel]string{
+	LevelHeader:    "Section: ",
+	LevelStage:     "Stage: ",
+	LevelInfo:      "Info: ",
+	LevelSuccess:   "Success: ",
+	LevelWarning:   "Warning: ",
+	LevelError:     "Error: ",
+	LevelAvailable: "Available: ",
+	LevelProgress:  "Progress: ",
+}
+
+// accessible reports whether output should be screen-reader friendly: AccessibleMode is
+// set or the PALANTIR_ACCESSIBLE environment variable is "1"
+func (c *OutputConfig) accessible() bool {
+	return c.AccessibleMode || os.Getenv(AccessibleEnvVar) == "1"
+}
+
+// formatAccessibleMessage introduces a message with its level's word, such as "Error: "
+func formatAccessibleMessage(level OutputLevel, message string) string {
+	return accessibleLabels[level] + strings.TrimSpace(message) + "\n"
+}
+
+// formatAccessibleProgress phrases progress as a sentence, such as
+// "Progress: Downloading, 30 percent complete"
+func formatAccessibleProgress(current, total int, message string) string {
+	percent := 100
+	if total > 0 {
+		percent = min(max(current*100/total, 0), 100)
+	}
+	if message == "" {
+		return fmt.Sprintf("%s%d percent complete\n", accessibleLabels[LevelProgress], percent)
+	}
+	return fmt.Sprintf("%s%s, %d percent complete\n", accessibleLabels[LevelProgress], message, percent)
+}
+
+// announce reports whether a progress update reaches a new accessibleProgressStep and
+// should be printed in accessible mode. Final updates are always announced and end the
+// sequence, so the next update starts a new one.
+func (p *progressState) announce(current, total int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if current >= total {
+		p.announced = 0
+		return true
+	}
+
+	step := current*100/total/accessibleProgressStep + 1
+	if step == p.announced {
+		return false
+	}
+	p.announced = step
+	return true
+}
+
+// accessibleQuestion phrases a Confirm prompt as a full question with its default answer
+func accessibleQuestion(message string) string {
+	return fmt.Sprintf("Question: %s? Answer yes or no, the default is no: ", strings.TrimRight(message, "? "))
+}
+
+// printAccessibleTree prints node and its descendants as indented "level 2: dir1, directory"
+// lines instead of connectors. The root is level 1 and is only printed with ShowRoot.
+// It reports whether the line budget allowed every line to be printed.
+func printAccessibleTree(node *TreeNode, level int, options BuildOptions) bool {
+	if level > 1 || options.ShowRoot {
+		text := stripANSI(withNodeComment(styleTreeNode(node, options), node, options))
+		line := fmt.Sprintf("%slevel %d: %s, %s", strings.Repeat("  ", level-1), level, text, accessibleNodeKind(node))
+		if !printTreeLine(line, options) {
+			return false
+		}
+	}
+
+	for _, child := range node.Children {
+		if options.lineBudget.exhausted() {
+			options.lineBudget.truncated = true // The remaining children are withheld
+			return false
+		}
+		if !printAccessibleTree(child, level+1, options) {
+			return false
+		}
+	}
+	return true
+}
+
+// accessibleNodeKind names what a node holds, such as "directory" or "value"
+func accessibleNodeKind(node *TreeNode) string {
+	switch data := node.Data.(type) {
+	case FileNode:
+		switch {
+		case data.IsDir:
+			return "directory"
+		case data.LinkTarget != "":
+			return "symbolic link"
+		default:
+			return "file"
+		}
+	case YAMLNode:
+		switch data.NodeType {
+		case "object":
+			return "object"
+		case "array":
+			return "list"
+		case "alias":
+			return "alias"
+		case "cycle":
+			return "cycle"
+		}
+		if data.IsDir || len(node.Children) > 0 {
+			return "object"
+		}
+		return "value"
+	case DiffNode:
+		if data.IsDir {
+			return "object"
+		}
+		return "value"
+	}
+	if len(node.Children) > 0 {
+		return "group"
+	}
+	return "item"
+}
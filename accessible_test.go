@@ -0,0 +1,86 @@
+package palantir
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// newAccessibleHandler returns a handler in accessible mode with colors and emojis
+// requested, which accessible mode must override, writing every level to buf
+func newAccessibleHandler(buf *bytes.Buffer) OutputHandler {
+	return NewOutputHandler(NewConfigBuilder().WithAccessibleMode().WithCIMode(CIModeNone).WithWriter(buf).Build())
+}
+
+func TestAccessibleModeMessages(t *testing.T) {
+	setupSupportedTerminal(t)
+	var buf bytes.Buffer
+	handler := newAccessibleHandler(&buf)
+
+	handler.PrintHeaderWithSubtitle("Deploy", "Rolling out version 2")
+	handler.PrintStage("Building images")
+	handler.PrintInfo("Using cache")
+	handler.PrintSuccess("Images built")
+	handler.PrintWarning("Registry is slow")
+	handler.PrintError("Push failed")
+	handler.PrintAlreadyAvailable("Base image")
+
+	assertGolden(t, "accessible_messages", buf.String())
+}
+
+func TestAccessibleModeProgress(t *testing.T) {
+	setupSupportedTerminal(t)
+	var buf bytes.Buffer
+	handler := newAccessibleHandler(&buf)
+
+	for i := 0; i <= 40; i++ {
+		handler.PrintProgress(i, 40, "Uploading")
+	}
+	handler.PrintProgress(1, 3, "")
+
+	assertGolden(t, "accessible_progress", buf.String())
+}
+
+func TestAccessibleModeConfirm(t *testing.T) {
+	var buf bytes.Buffer
+	config := NewConfigBuilder().WithAccessibleMode().WithWriter(&buf).WithReader(strings.NewReader("yes\n")).Build()
+
+	if !NewOutputHandler(config).Confirm("Delete the cache?") {
+		t.Error("Expected the answer to confirm")
+	}
+	expected := "Question: Delete the cache? Answer yes or no, the default is no: "
+	if buf.String() != expected {
+		t.Errorf("Prompt = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestAccessibleModeTree(t *testing.T) {
+	setupSupportedTerminal(t)
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true, AccessibleMode: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	root := &TreeNode{Name: "project", Data: FileNode{Name: "project", IsDir: true}, Children: []*TreeNode{
+		{Name: "dir1", Data: FileNode{Name: "dir1", IsDir: true}, Children: []*TreeNode{
+			{Name: "main.go", Data: FileNode{Name: "main.go"}},
+		}},
+		{Name: "README.md", Data: FileNode{Name: "README.md"}},
+	}}
+
+	output := captureOutput(func() {
+		renderTree(root, newBuildOptions([]BuildOption{WithShowRoot()}))
+	})
+	assertGolden(t, "accessible_tree", output)
+}
+
+func TestAccessibleModeFromEnvironment(t *testing.T) {
+	t.Setenv(AccessibleEnvVar, "1")
+	var buf bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithWriter(&buf).Build())
+
+	handler.PrintSuccess("Done")
+	if buf.String() != "Success: Done\n" {
+		t.Errorf("Output = %q, want %q", buf.String(), "Success: Done\n")
+	}
+}
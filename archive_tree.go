@@ -0,0 +1,220 @@
+package palantir
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ArchiveTreeBuilder builds trees from the entries of zip, tar and gzip-compressed tar
+// archives without extracting them. Entry names are sanitized so absolute paths and ".."
+// elements stay inside the archive's root, and the filesystem is never written to.
+type ArchiveTreeBuilder struct{}
+
+// NewArchiveTreeBuilder creates a TreeBuilder for archives
+func NewArchiveTreeBuilder() *ArchiveTreeBuilder {
+	return &ArchiveTreeBuilder{}
+}
+
+// Build reads the archive at archivePath and returns the tree of its entries, rooted at
+// a node named after the archive. FileNode paths are slash-separated paths within the archive.
+func (b *ArchiveTreeBuilder) Build(archivePath string) (*TreeNode, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	return b.BuildFromReader(file, archivePath)
+}
+
+// BuildFromReader reads an archive from r and returns the tree of its entries. The format
+// is sniffed from the content, falling back to the extension of name, which also names
+// the root. Zip archives are read into memory, because their index is at the end.
+func (b *ArchiveTreeBuilder) BuildFromReader(r io.Reader, name string) (*TreeNode, error) {
+	root := &TreeNode{
+		Name: filepath.Base(name),
+		Data: FileNode{Name: filepath.Base(name), Path: name, IsDir: true},
+	}
+
+	buffered := bufio.NewReader(r)
+	header, _ := buffered.Peek(512)
+
+	var err error
+	switch sniffArchiveFormat(header, name) {
+	case archiveZip:
+		err = addZipEntries(root, buffered)
+	case archiveTarGz:
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(buffered); err == nil {
+			err = addTarEntries(root, gz)
+		}
+	case archiveTar:
+		err = addTarEntries(root, buffered)
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return root, nil
+}
+
+// archiveFormat identifies the container format of an archive
+type archiveFormat int
+
+const (
+	archiveUnknown archiveFormat = iota
+	archiveZip
+	archiveTar
+	archiveTarGz
+)
+
+// sniffArchiveFormat detects an archive's format from its first bytes, falling back to
+// the extension of name
+func sniffArchiveFormat(header []byte, name string) archiveFormat {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return archiveZip
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return archiveTarGz
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return archiveTar
+	}
+
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return archiveZip
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return archiveTarGz
+	case strings.HasSuffix(lower, ".tar"):
+		return archiveTar
+	default:
+		return archiveUnknown
+	}
+}
+
+// addZipEntries adds the entries of a zip archive to root
+func addZipEntries(root *TreeNode, r io.Reader) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range archive.File {
+		info := file.FileInfo()
+		addArchiveEntry(root, file.Name, info.IsDir(), int64(file.UncompressedSize64), file.Modified)
+	}
+	return nil
+}
+
+// addTarEntries adds the entries of a tar stream to root
+func addTarEntries(root *TreeNode, r io.Reader) error {
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeXGlobalHeader, tar.TypeXHeader:
+			continue // Metadata, not entries
+		}
+		addArchiveEntry(root, header.Name, header.Typeflag == tar.TypeDir, header.Size, header.ModTime)
+	}
+}
+
+// sanitizeArchivePath cleans an entry name into a slash-separated path inside the archive,
+// dropping leading slashes, drive letters and ".." elements that would escape it. It
+// returns an empty string for names that resolve to the root.
+func sanitizeArchivePath(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if len(name) >= 2 && name[1] == ':' {
+		name = name[2:] // Windows drive letter
+	}
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// addArchiveEntry adds an entry to the tree like addPathEntry, after sanitizing its name.
+// Hidden entries are skipped like in filesystem trees.
+func addArchiveEntry(root *TreeNode, name string, isDir bool, size int64, modTime time.Time) {
+	entryPath := sanitizeArchivePath(name)
+	if entryPath == "" {
+		return
+	}
+	parts := strings.Split(entryPath, "/")
+	for _, part := range parts {
+		if strings.HasPrefix(part, ".") {
+			return
+		}
+	}
+	addPathEntry(root, parts, isDir, size, modTime.Unix())
+}
+
+// addPathEntry adds the entry at the path of parts to the tree, creating missing parent
+// directories. Later entries replace earlier ones with the same path. FileNode paths are
+// slash-separated.
+func addPathEntry(root *TreeNode, parts []string, isDir bool, size int64, modTime int64) {
+
+	current := root
+	for i, part := range parts {
+		child := current.ChildNamed(part)
+		if child == nil {
+			child = &TreeNode{Name: part, Data: FileNode{Name: part, Path: strings.Join(parts[:i+1], "/"), IsDir: true}}
+			current.Children = append(current.Children, child)
+		} else if i < len(parts)-1 && !getIsDir(child.Data) {
+			// An earlier file entry is also a parent, so it has to be shown as a directory
+			fileNode := child.Data.(FileNode)
+			fileNode.IsDir, fileNode.Size = true, 0
+			child.Data = fileNode
+		}
+		current = child
+	}
+
+	// Entries below the path keep it a directory even when it is listed as a file
+	fileNode := current.Data.(FileNode)
+	fileNode.ModTime = modTime
+	if !isDir && len(current.Children) == 0 {
+		fileNode.IsDir, fileNode.Size = false, size
+	}
+	current.Data = fileNode
+}
+
+// ShowArchiveHierarchy displays the layout of a zip, tar or tar.gz archive without extracting it
+func ShowArchiveHierarchy(archivePath string, opts ...BuildOption) error {
+	root, err := NewArchiveTreeBuilder().Build(archivePath)
+	if err != nil {
+		return err
+	}
+	err, _ = showFileTree(root, newBuildOptions(opts))
+	return err
+}
+
+// ShowArchiveHierarchyFromReader displays the layout of an archive read from r. name is
+// used to label the tree and as a hint for the format.
+func ShowArchiveHierarchyFromReader(r io.Reader, name string, opts ...BuildOption) error {
+	root, err := NewArchiveTreeBuilder().BuildFromReader(r, name)
+	if err != nil {
+		return err
+	}
+	err, _ = showFileTree(root, newBuildOptions(opts))
+	return err
+}
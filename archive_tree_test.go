@@ -0,0 +1,167 @@
+package palantir
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// archiveEntries are written to every test archive. Parents of nested entries are not
+// listed, and two names try to escape the archive's root.
+var archiveEntries = []struct {
+	name string
+	body string
+}{
+	{"release/bin/tool", "binary"},
+	{"release/docs/README.md", "# readme"},
+	{"release/LICENSE", "MIT"},
+	{"../evil", "escape"},
+	{"/etc/passwd", "root"},
+	{"release/.hidden", "skip"},
+}
+
+func buildZipArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, entry := range archiveEntries {
+		file, err := writer.Create(entry.name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", entry.name, err)
+		}
+		file.Write([]byte(entry.body))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to write zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func buildTarArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for _, entry := range archiveEntries {
+		header := &tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.body)), Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to add %s: %v", entry.name, err)
+		}
+		writer.Write([]byte(entry.body))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to write tar: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func buildTarGzArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write(buildTarArchive(t))
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to write gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveTreeBuilderFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"release.zip", buildZipArchive(t)},
+		{"release.tar", buildTarArchive(t)},
+		{"release.tar.gz", buildTarGzArchive(t)},
+		{"download", buildTarGzArchive(t)}, // Sniffed without an extension
+	}
+
+	expected := []string{"etc", "etc/passwd", "release", "release/bin", "release/bin/tool", "release/docs", "release/docs/README.md", "release/LICENSE", "evil"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := NewArchiveTreeBuilder().BuildFromReader(bytes.NewReader(tt.content), tt.name)
+			if err != nil {
+				t.Fatalf("BuildFromReader() error = %v", err)
+			}
+			sortTree(root)
+
+			if root.Name != tt.name {
+				t.Errorf("Expected the root to be named %q, got %q", tt.name, root.Name)
+			}
+			if paths := root.Flatten("/", false); !reflect.DeepEqual(paths, expected) {
+				t.Errorf("Archive paths = %q, want %q", paths, expected)
+			}
+
+			readme := root.FindByPath("release", "docs", "README.md").Data.(FileNode)
+			if readme.Size != 8 || readme.IsDir || readme.Path != "release/docs/README.md" {
+				t.Errorf("Expected README.md with its size from the header, got %+v", readme)
+			}
+			if docs := root.FindByPath("release", "docs").Data.(FileNode); !docs.IsDir {
+				t.Errorf("Expected the missing parent docs to be created as a directory, got %+v", docs)
+			}
+		})
+	}
+}
+
+func TestSanitizeArchivePath(t *testing.T) {
+	tests := map[string]string{
+		"a/b.txt":           "a/b.txt",
+		"./a/b.txt":         "a/b.txt",
+		"../evil":           "evil",
+		"a/../../../evil":   "evil",
+		"/etc/passwd":       "etc/passwd",
+		`C:\Windows\system`: "Windows/system",
+		"..":                "",
+		"dir/":              "dir",
+	}
+	for name, expected := range tests {
+		if result := sanitizeArchivePath(name); result != expected {
+			t.Errorf("sanitizeArchivePath(%q) = %q, want %q", name, result, expected)
+		}
+	}
+}
+
+func TestShowArchiveHierarchy(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	archivePath := filepath.Join(t.TempDir(), "release.zip")
+	if err := os.WriteFile(archivePath, buildZipArchive(t), 0644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	output := captureOutput(func() {
+		if err := ShowArchiveHierarchy(archivePath, WithShowRoot()); err != nil {
+			t.Errorf("ShowArchiveHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "release.zip\n" +
+		"├── etc\n" +
+		"│   └── passwd\n" +
+		"├── release\n" +
+		"│   ├── bin\n" +
+		"│   │   └── tool\n" +
+		"│   ├── docs\n" +
+		"│   │   └── README.md\n" +
+		"│   └── LICENSE\n" +
+		"└── evil\n"
+	if output != expected {
+		t.Errorf("ShowArchiveHierarchy() output = %q, want %q", output, expected)
+	}
+}
+
+func TestShowArchiveHierarchyErrors(t *testing.T) {
+	if err := ShowArchiveHierarchy("/nonexistent/release.zip"); err == nil {
+		t.Error("Expected an error for a missing archive")
+	}
+	if err := ShowArchiveHierarchyFromReader(bytes.NewReader([]byte("plain text")), "notes.txt"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+	if err := ShowArchiveHierarchyFromReader(bytes.NewReader([]byte("PK\x03\x04broken")), "broken.zip"); err == nil {
+		t.Error("Expected an error for a corrupt archive")
+	}
+}
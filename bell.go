@@ -0,0 +1,38 @@
+package palantir
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// isTerminal reports whether w writes to a terminal. It is a variable so tests can
+// pretend a writer is one.
+var isTerminal = func(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Bell rings the terminal bell by writing the BEL character, such as to signal that a
+// long job finished. Nothing is written when output is disabled or the LevelInfo writer
+// is not a terminal, where the character would only end up in a file or pipe.
+func (oh *outputHandler) Bell() {
+	defer recoverPanic(oh.config)
+
+	if oh.config.DisableOutput || !isTerminal(oh.destinationFor(LevelInfo)) {
+		return
+	}
+	fmt.Fprint(oh.writerFor(LevelInfo), "\a")
+}
+
+// bellOnDone rings the bell after a success message under BellOnDone, unless quiet mode
+// hid the message
+func (oh *outputHandler) bellOnDone() {
+	if oh.config.BellOnDone && !oh.isQuieted(LevelSuccess) {
+		oh.Bell()
+	}
+}
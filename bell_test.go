@@ -0,0 +1,93 @@
+package palantir
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubTerminal makes isTerminal report only the given writer as a terminal
+func stubTerminal(t *testing.T, terminal io.Writer) {
+	original := isTerminal
+	isTerminal = func(w io.Writer) bool { return w == terminal }
+	t.Cleanup(func() { isTerminal = original })
+}
+
+func TestBellOnlyRingsTerminals(t *testing.T) {
+	var tty, file bytes.Buffer
+	stubTerminal(t, &tty)
+
+	NewOutputHandler(NewConfigBuilder().WithWriter(&tty).Build()).Bell()
+	NewOutputHandler(NewConfigBuilder().WithWriter(&file).Build()).Bell()
+
+	if tty.String() != "\a" {
+		t.Errorf("Expected a terminal to get the bell, got %q", tty.String())
+	}
+	if file.Len() != 0 {
+		t.Errorf("Expected no bell for a writer that is not a terminal, got %q", file.String())
+	}
+
+	disabled := NewOutputHandler(NewConfigBuilder().WithWriter(&tty).WithOutputDisabled().Build())
+	disabled.Bell()
+	if tty.String() != "\a" {
+		t.Errorf("Expected no bell with output disabled, got %q", tty.String())
+	}
+}
+
+func TestBellOnDone(t *testing.T) {
+	var tty bytes.Buffer
+	stubTerminal(t, &tty)
+	config := NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithBellOnDone().WithWriter(&tty).Build()
+	handler := NewOutputHandler(config)
+
+	handler.PrintInfo("working")
+	handler.PrintSuccess("done")
+	handler.PrintSuccessWithDuration("deployed", 1500*time.Millisecond)
+
+	expected := "working\n[SUCCESS] done\n\a[SUCCESS] deployed (1.5s)\n\a"
+	if tty.String() != expected {
+		t.Errorf("Output = %q, want %q", tty.String(), expected)
+	}
+
+	tty.Reset()
+	config.QuietMode = true
+	handler.PrintSuccess("hidden")
+	if strings.Contains(tty.String(), "\a") {
+		t.Errorf("Expected no bell for a success hidden by quiet mode, got %q", tty.String())
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if isTerminal(w) {
+		t.Error("Expected a pipe not to be a terminal")
+	}
+	if isTerminal(&bytes.Buffer{}) {
+		t.Error("Expected a buffer not to be a terminal")
+	}
+}
+
+func TestBellThroughWrappers(t *testing.T) {
+	var tty bytes.Buffer
+	stubTerminal(t, &tty)
+	inner := NewOutputHandler(NewConfigBuilder().WithWriter(&tty).Build())
+
+	NewPrefixedHandler(inner, "api").Bell()
+	NewLevelFilterHandler(inner, LevelInfo).Bell()
+	NewLevelFilterHandler(inner, LevelError).Bell()
+	NewSlogHandler(slog.New(slog.NewTextHandler(&tty, nil))).Bell()
+
+	if tty.String() != "\a\a" {
+		t.Errorf("Expected the bell from the prefixed and info-allowing handlers only, got %q", tty.String())
+	}
+}
@@ -0,0 +1,142 @@
+package palantir
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// coalescer batches the output of a handler under OutputConfig.FlushInterval, so bursts of
+// short messages cost one write instead of one per line. Pending output is written by a
+// background goroutine every interval, before Confirm prompts, and on Flush and Close.
+// Output for a different writer first flushes what is pending, so nothing is reordered.
+// It is shared by a handler and the handlers returned by its WithPrefix.
+type coalescer struct {
+	mu      sync.Mutex
+	dest    io.Writer // Where the pending output goes
+	pending bytes.Buffer
+	running bool // Set once the flushing goroutine was started
+	closed  bool // Set by close; later output is written directly
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// coalescedWriter is an io.Writer adding its output to a coalescer for dest
+type coalescedWriter struct {
+	c      *coalescer
+	dest   io.Writer
+	config *OutputConfig
+}
+
+func (w coalescedWriter) Write(p []byte) (int, error) {
+	return w.c.write(w.dest, p, w.config)
+}
+
+// write adds p to the pending output for dest, starting the flushing goroutine on first use
+func (c *coalescer) write(dest io.Writer, p []byte, config *OutputConfig) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return dest.Write(p)
+	}
+	if c.dest != dest {
+		if err := c.flushLocked(); err != nil {
+			return 0, err
+		}
+		c.dest = dest
+	}
+	c.pending.Write(p)
+
+	if !c.running {
+		c.running = true
+		c.done, c.stopped = make(chan struct{}), make(chan struct{})
+		go c.run(config.FlushInterval, config)
+	}
+	return len(p), nil
+}
+
+// run flushes the pending output every interval until close is called
+func (c *coalescer) run(interval time.Duration, config *OutputConfig) {
+	defer close(c.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			func() {
+				defer recoverPanic(config)
+				c.flush()
+			}()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// flush writes the pending output
+func (c *coalescer) flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushLocked()
+}
+
+// flushLocked writes the pending output with c.mu held
+func (c *coalescer) flushLocked() error {
+	if c.pending.Len() == 0 {
+		return nil
+	}
+	_, err := c.dest.Write(c.pending.Bytes())
+	c.pending.Reset()
+	return err
+}
+
+// close stops the flushing goroutine and writes the pending output. Output written after
+// close is no longer batched.
+func (c *coalescer) close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	err := c.flushLocked()
+	running := c.running
+	if running {
+		close(c.done)
+	}
+	c.mu.Unlock()
+
+	if running {
+		<-c.stopped
+	}
+	return err
+}
+
+// Flush writes any output batched under OutputConfig.FlushInterval
+func (oh *outputHandler) Flush() error {
+	if oh.output == nil {
+		return nil
+	}
+	return oh.output.flush()
+}
+
+// Close stops and clears the spinners of running steps, ends a GitHub Actions group left
+// open by the last header, writes any output batched under OutputConfig.FlushInterval and
+// stops the goroutine flushing it. The handler remains usable, writing its output directly.
+func (oh *outputHandler) Close() error {
+	oh.spinners.stopAll()
+	oh.endGroup()
+	if oh.output == nil {
+		return nil
+	}
+	return oh.output.close()
+}
+
+// flushGlobalOutput flushes the global handler before output that bypasses it, such as
+// trees, so batched messages are not printed after it
+func flushGlobalOutput() {
+	GetGlobalOutputHandler().Flush()
+}
@@ -0,0 +1,212 @@
+package palantir
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingWriter records writes tagged with its name into a log shared by several
+// writers, and is safe for use by the flushing goroutine
+type recordingWriter struct {
+	name string
+	log  *writeLog
+}
+
+type writeLog struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (w recordingWriter) Write(p []byte) (int, error) {
+	w.log.mu.Lock()
+	defer w.log.mu.Unlock()
+	w.log.writes = append(w.log.writes, w.name+":"+string(p))
+	return len(p), nil
+}
+
+// snapshot returns the writes recorded so far
+func (l *writeLog) snapshot() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.writes...)
+}
+
+func TestFlushIntervalEventuallyFlushesEverything(t *testing.T) {
+	log := &writeLog{}
+	config := NewConfigBuilder().WithFormatting(false).WithCIMode(CIModeNone).
+		WithWriter(recordingWriter{name: "out", log: log}).WithFlushInterval(5 * time.Millisecond).Build()
+	handler := NewOutputHandler(config)
+	t.Cleanup(func() { handler.Close() })
+
+	var expected strings.Builder
+	for i := 0; i < 100; i++ {
+		handler.PrintInfo("message %d", i)
+		expected.WriteString(fmt.Sprintf("message %d\n", i))
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		writes := log.snapshot()
+		got := strings.ReplaceAll(strings.Join(writes, ""), "out:", "")
+		if got == expected.String() {
+			if len(writes) >= 100 {
+				t.Errorf("Expected the messages to be coalesced into fewer writes, got %d", len(writes))
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Output was not flushed in time, got %q", got)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFlushIntervalKeepsOrderAcrossWriters(t *testing.T) {
+	log := &writeLog{}
+	config := &OutputConfig{
+		FlushInterval: time.Hour, // Only flushed by switching writers and Close
+		LevelWriters: map[OutputLevel]io.Writer{
+			LevelInfo:  recordingWriter{name: "stdout", log: log},
+			LevelError: recordingWriter{name: "stderr", log: log},
+		},
+	}
+	handler := NewOutputHandler(config)
+
+	handler.PrintInfo("one")
+	handler.PrintInfo("two")
+	handler.PrintError("three")
+	handler.WithPrefix("api").PrintInfo("four")
+	if writes := log.snapshot(); len(writes) != 2 {
+		t.Fatalf("Expected output to be written only when the writer changes, got %q", writes)
+	}
+
+	if err := handler.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	expected := []string{"stdout:one\ntwo\n", "stderr:[ERROR] three\n", "stdout:[api] four\n"}
+	if got := log.snapshot(); strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Writes = %q, want %q", got, expected)
+	}
+
+	// After Close, output is written directly
+	handler.PrintInfo("five")
+	if got := log.snapshot(); len(got) != 4 || got[3] != "stdout:five\n" {
+		t.Errorf("Expected output after Close to be written directly, got %q", got)
+	}
+	if err := handler.Close(); err != nil {
+		t.Errorf("Second Close() error = %v", err)
+	}
+}
+
+func TestCloseFlushesBufferedHandler(t *testing.T) {
+	log := &writeLog{}
+	config := NewConfigBuilder().
+		WithWriter(recordingWriter{name: "stdout", log: log}).
+		WithColors(false).
+		WithFlushInterval(time.Hour). // Only flushed by Close
+		Build()
+
+	handlers := map[string]func(OutputHandler) OutputHandler{
+		"Default":     func(h OutputHandler) OutputHandler { return h },
+		"Prefixed":    func(h OutputHandler) OutputHandler { return NewPrefixedHandler(h, "api") },
+		"LevelFilter": func(h OutputHandler) OutputHandler { return NewLevelFilterHandler(h, LevelInfo) },
+	}
+	for name, wrap := range handlers {
+		t.Run(name, func(t *testing.T) {
+			log.mu.Lock()
+			log.writes = nil
+			log.mu.Unlock()
+
+			var handler OutputHandler = wrap(NewOutputHandler(config))
+			handler.PrintInfo("starting")
+			if writes := log.snapshot(); len(writes) != 0 {
+				t.Fatalf("Expected output to be buffered before Close, got %q", writes)
+			}
+
+			if err := handler.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if writes := log.snapshot(); len(writes) != 1 || !strings.HasSuffix(writes[0], "starting\n") {
+				t.Errorf("Expected Close to flush the buffered output, got %q", writes)
+			}
+		})
+	}
+}
+
+func TestFlushIntervalFlushesBeforeConfirm(t *testing.T) {
+	log := &writeLog{}
+	answer := strings.NewReader("y\n")
+	reader := readerFunc(func(p []byte) (int, error) {
+		if got := strings.Join(log.snapshot(), ""); !strings.Contains(got, "Continue?") {
+			t.Errorf("Expected the prompt to be flushed before reading, got %q", got)
+		}
+		return answer.Read(p)
+	})
+	config := &OutputConfig{FlushInterval: time.Hour, Reader: reader, LevelWriters: map[OutputLevel]io.Writer{
+		LevelInfo: recordingWriter{name: "out", log: log},
+	}}
+	handler := NewOutputHandler(config)
+	t.Cleanup(func() { handler.Close() })
+
+	handler.PrintInfo("About to deploy")
+	if !handler.Confirm("Continue?") {
+		t.Error("Expected the answer to confirm")
+	}
+}
+
+func TestFlushGlobalOutputThroughWrappers(t *testing.T) {
+	log := &writeLog{}
+	inner := NewOutputHandler(NewConfigBuilder().
+		WithWriter(recordingWriter{name: "stdout", log: log}).
+		WithColors(false).
+		WithFlushInterval(time.Hour). // Only flushed by flushGlobalOutput
+		Build())
+	t.Cleanup(func() { SetGlobalOutputHandler(NewDefaultOutputHandler()) })
+
+	SetGlobalOutputHandler(NewLevelFilterHandler(NewPrefixedHandler(inner, "api").WithWidth(0), LevelInfo))
+	GetGlobalOutputHandler().PrintInfo("starting")
+	if writes := log.snapshot(); len(writes) != 0 {
+		t.Fatalf("Expected output to be buffered before flushing, got %q", writes)
+	}
+
+	flushGlobalOutput()
+	if writes := log.snapshot(); len(writes) != 1 || writes[0] != "stdout:[api] starting\n" {
+		t.Errorf("Expected the wrapped handler to be flushed, got %q", writes)
+	}
+}
+
+// readerFunc adapts a function to io.Reader
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+func benchmarkPrintInfo(b *testing.B, interval time.Duration) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatalf("Failed to open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithWriter(devNull).WithFlushInterval(interval).Build())
+	defer handler.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.PrintInfo("processed item %d", i)
+	}
+}
+
+func BenchmarkPrintInfoUnbuffered(b *testing.B) {
+	benchmarkPrintInfo(b, 0)
+}
+
+func BenchmarkPrintInfoFlushInterval(b *testing.B) {
+	benchmarkPrintInfo(b, 10*time.Millisecond)
+}
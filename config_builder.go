@@ -0,0 +1,198 @@
+package palantir
+
+import (
+	"io"
+	"time"
+)
+
+// ConfigBuilder builds an OutputConfig with chained calls, starting from the defaults
+// used by NewDefaultOutputHandler:
+//
+//	config := NewConfigBuilder().WithEmojis(false).WithVerbose().WithWriter(os.Stderr).Build()
+type ConfigBuilder struct {
+	config OutputConfig
+}
+
+// NewConfigBuilder creates a builder starting with colors, emojis and formatting enabled
+func NewConfigBuilder() *ConfigBuilder {
+	return &ConfigBuilder{config: *defaultOutputConfig()}
+}
+
+// WithColors enables or disables colored output
+func (b *ConfigBuilder) WithColors(enabled bool) *ConfigBuilder {
+	b.config.UseColors = enabled
+	return b
+}
+
+// WithEmojis enables or disables emoji level prefixes
+func (b *ConfigBuilder) WithEmojis(enabled bool) *ConfigBuilder {
+	b.config.UseEmojis = enabled
+	return b
+}
+
+// WithFormatting enables or disables level prefixes and styling
+func (b *ConfigBuilder) WithFormatting(enabled bool) *ConfigBuilder {
+	b.config.UseFormatting = enabled
+	return b
+}
+
+// WithColorizeLevelOnly colors only the level prefix of messages, leaving the text plain
+func (b *ConfigBuilder) WithColorizeLevelOnly() *ConfigBuilder {
+	b.config.ColorizeLevelOnly = true
+	return b
+}
+
+// WithAutoDetectEmoji falls back to text prefixes on terminals unlikely to render emoji
+func (b *ConfigBuilder) WithAutoDetectEmoji() *ConfigBuilder {
+	b.config.AutoDetectEmoji = true
+	return b
+}
+
+// WithAccessibleMode enables screen-reader friendly output
+func (b *ConfigBuilder) WithAccessibleMode() *ConfigBuilder {
+	b.config.AccessibleMode = true
+	return b
+}
+
+// WithFlushInterval batches output, writing it at most once per interval
+func (b *ConfigBuilder) WithFlushInterval(interval time.Duration) *ConfigBuilder {
+	b.config.FlushInterval = interval
+	return b
+}
+
+// WithOnPanic selects what RecoverAndPrint does after printing a panic
+func (b *ConfigBuilder) WithOnPanic(action PanicAction) *ConfigBuilder {
+	b.config.OnPanic = action
+	return b
+}
+
+// WithNumberStages numbers stages, such as "[2/5] build"
+func (b *ConfigBuilder) WithNumberStages() *ConfigBuilder {
+	b.config.NumberStages = true
+	return b
+}
+
+// WithBellOnDone rings the terminal bell after success messages
+func (b *ConfigBuilder) WithBellOnDone() *ConfigBuilder {
+	b.config.BellOnDone = true
+	return b
+}
+
+// WithLevelStyle overrides the prefixes and color of a level
+func (b *ConfigBuilder) WithLevelStyle(level OutputLevel, style LevelStyle) *ConfigBuilder {
+	if b.config.LevelStyles == nil {
+		b.config.LevelStyles = make(map[OutputLevel]LevelStyle)
+	}
+	b.config.LevelStyles[level] = style
+	return b
+}
+
+// WithExitCodeFunc maps the number of errors printed to the exit code suggested by ExitCode
+func (b *ConfigBuilder) WithExitCodeFunc(fn func(errorCount int) int) *ConfigBuilder {
+	b.config.ExitCodeFunc = fn
+	return b
+}
+
+// WithCIMode selects whether headers, errors and warnings are written as CI workflow
+// commands. CIModeAuto, the default, writes them when GITHUB_ACTIONS is "true";
+// CIModeGitHubActions always writes them; CIModeNone always writes terminal output.
+func (b *ConfigBuilder) WithCIMode(mode CIMode) *ConfigBuilder {
+	b.config.CIMode = mode
+	return b
+}
+
+// WithVerbose enables verbose mode
+func (b *ConfigBuilder) WithVerbose() *ConfigBuilder {
+	b.config.VerboseMode = true
+	return b
+}
+
+// WithQuiet only prints warnings and errors
+func (b *ConfigBuilder) WithQuiet() *ConfigBuilder {
+	b.config.QuietMode = true
+	return b
+}
+
+// WithOutputDisabled suppresses all output
+func (b *ConfigBuilder) WithOutputDisabled() *ConfigBuilder {
+	b.config.DisableOutput = true
+	return b
+}
+
+// WithWriter sends the output of every level to w
+func (b *ConfigBuilder) WithWriter(w io.Writer) *ConfigBuilder {
+	for level := LevelInfo; level <= LevelAvailable; level++ {
+		b.WithLevelWriter(level, w)
+	}
+	return b
+}
+
+// WithLevelWriter sends the output of one level to w, such as errors to os.Stderr
+func (b *ConfigBuilder) WithLevelWriter(level OutputLevel, w io.Writer) *ConfigBuilder {
+	if b.config.LevelWriters == nil {
+		b.config.LevelWriters = make(map[OutputLevel]io.Writer)
+	}
+	b.config.LevelWriters[level] = w
+	return b
+}
+
+// WithReader reads the answers to Confirm from r instead of standard input
+func (b *ConfigBuilder) WithReader(r io.Reader) *ConfigBuilder {
+	b.config.Reader = r
+	return b
+}
+
+// WithWrapWidth sets the terminal width used to measure output
+func (b *ConfigBuilder) WithWrapWidth(width int) *ConfigBuilder {
+	b.config.WrapWidth = width
+	return b
+}
+
+// WithTreeStyle sets the connector characters used to draw trees
+func (b *ConfigBuilder) WithTreeStyle(style TreeConnectorStyle) *ConfigBuilder {
+	b.config.TreeStyle = style
+	return b
+}
+
+// WithASCIIOnly draws trees with plain ASCII connectors
+func (b *ConfigBuilder) WithASCIIOnly() *ConfigBuilder {
+	b.config.ASCIIOnly = true
+	return b
+}
+
+// WithSuppressRepeats replaces repeated lines with a summary
+func (b *ConfigBuilder) WithSuppressRepeats() *ConfigBuilder {
+	b.config.SuppressRepeats = true
+	return b
+}
+
+// WithRecoverPanics reports panics raised while printing instead of crashing
+func (b *ConfigBuilder) WithRecoverPanics() *ConfigBuilder {
+	b.config.RecoverPanics = true
+	return b
+}
+
+// WithMinUpdateInterval coalesces progress updates arriving sooner than interval
+func (b *ConfigBuilder) WithMinUpdateInterval(interval time.Duration) *ConfigBuilder {
+	b.config.MinUpdateInterval = interval
+	return b
+}
+
+// Build returns the configured OutputConfig. Each call returns a new copy, so the builder
+// can be changed and built again without affecting earlier configs.
+func (b *ConfigBuilder) Build() *OutputConfig {
+	config := b.config
+	if b.config.LevelWriters != nil {
+		config.LevelWriters = make(map[OutputLevel]io.Writer, len(b.config.LevelWriters))
+		for level, writer := range b.config.LevelWriters {
+			config.LevelWriters[level] = writer
+		}
+	}
+	if b.config.LevelStyles != nil {
+		config.LevelStyles = make(map[OutputLevel]LevelStyle, len(b.config.LevelStyles))
+		for level, style := range b.config.LevelStyles {
+			config.LevelStyles[level] = style
+		}
+	}
+	return &config
+}
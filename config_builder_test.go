@@ -0,0 +1,71 @@
+package palantir
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigBuilderDefaults(t *testing.T) {
+	config := NewConfigBuilder().Build()
+	if !reflect.DeepEqual(config, NewDefaultOutputHandler().Config()) {
+		t.Errorf("Build() = %+v, want the default handler's config", config)
+	}
+	if !config.UseColors || !config.UseEmojis || !config.UseFormatting || config.DisableOutput || config.QuietMode {
+		t.Errorf("Expected colors, emojis and formatting by default, got %+v", config)
+	}
+}
+
+func TestConfigBuilderChaining(t *testing.T) {
+	reader := strings.NewReader("y\n")
+	config := NewConfigBuilder().
+		WithColors(false).
+		WithEmojis(false).
+		WithVerbose().
+		WithCIMode(CIModeNone).
+		WithColorizeLevelOnly().
+		WithTreeStyle(TreeStyleRounded).
+		WithWrapWidth(100).
+		WithMinUpdateInterval(time.Second).
+		WithReader(reader).
+		WithLevelWriter(LevelError, os.Stderr).
+		Build()
+
+	expected := &OutputConfig{
+		UseFormatting:     true,
+		VerboseMode:       true,
+		ColorizeLevelOnly: true,
+		TreeStyle:         TreeStyleRounded,
+		WrapWidth:         100,
+		MinUpdateInterval: time.Second,
+		Reader:            reader,
+		LevelWriters:      map[OutputLevel]io.Writer{LevelError: os.Stderr},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Build() = %+v, want %+v", config, expected)
+	}
+}
+
+func TestConfigBuilderWithWriter(t *testing.T) {
+	var buf bytes.Buffer
+	builder := NewConfigBuilder().WithFormatting(false).WithWriter(&buf)
+	handler := NewOutputHandler(builder.Build())
+
+	handler.PrintInfo("info")
+	handler.PrintError("error")
+	handler.PrintSuccess("success")
+	if buf.String() != "info\n[ERROR] error\n[SUCCESS] success\n" {
+		t.Errorf("Expected every level on the writer, got %q", buf.String())
+	}
+
+	// Later changes to the builder leave earlier configs alone
+	first := builder.Build()
+	builder.WithLevelWriter(LevelError, os.Stderr).WithQuiet()
+	if first.LevelWriters[LevelError] != &buf || first.QuietMode {
+		t.Errorf("Expected the built config to be unaffected, got %+v", first)
+	}
+}
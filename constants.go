@@ -11,39 +11,58 @@ const (
 	ColorCyan   = "\033[36m" // Cyan foreground
 	ColorWhite  = "\033[37m" // White foreground
 	ColorBold   = "\033[1m"  // Bold text
+	ColorDim    = "\033[2m"  // Dimmed (faint) text
 )
 
 var (
 	// outputColors is a map of output levels to their corresponding colors
 	outputColors = map[OutputLevel]string{
-		LevelHeader:  ColorCyan,
-		LevelStage:   ColorBlue,
-		LevelSuccess: ColorGreen,
-		LevelError:   ColorRed,
-		LevelWarning: ColorYellow,
-		LevelInfo:    "",
+		LevelHeader:    ColorCyan,
+		LevelStage:     ColorBlue,
+		LevelSuccess:   ColorGreen,
+		LevelError:     ColorRed,
+		LevelWarning:   ColorYellow,
+		LevelInfo:      "",
+		LevelAvailable: ColorBlue,
 	}
 
 	// outputEmojis is a map of output levels to their corresponding emojis
 	outputEmojis = map[OutputLevel]string{
-		LevelHeader:  "",
-		LevelStage:   "🔧 ",
-		LevelSuccess: "✅ ",
-		LevelError:   "❌ ",
-		LevelWarning: "⚠️  ",
-		LevelInfo:    "",
+		LevelHeader:    "",
+		LevelStage:     "🔧 ",
+		LevelSuccess:   "✅ ",
+		LevelError:     "❌ ",
+		LevelWarning:   "⚠️  ",
+		LevelInfo:      "",
+		LevelAvailable: "💙 ",
 	}
 
 	// outputPrefixes is a map of output levels to their corresponding prefixes
 	outputPrefixes = map[OutputLevel]string{
-		LevelHeader:  headerFormat,
-		LevelStage:   "[STAGE] ",
-		LevelSuccess: "[SUCCESS] ",
-		LevelError:   "[ERROR] ",
-		LevelWarning: "[WARNING] ",
-		LevelInfo:    "",
+		LevelHeader:    headerFormat,
+		LevelStage:     "[STAGE] ",
+		LevelSuccess:   "[SUCCESS] ",
+		LevelError:     "[ERROR] ",
+		LevelWarning:   "[WARNING] ",
+		LevelInfo:      "",
+		LevelAvailable: "[AVAILABLE] ",
 	}
 
 	coloredHeaderFormat = "\n%s%s=== %s ===%s\n"
 	headerFormat        = "\n=== %s ===\n"
 )
+
+// LevelStyle describes how the messages of a level are labeled and colored
+type LevelStyle struct {
+	Prefix string // Label without emojis, such as "[INFO] "; empty for none
+	Emoji  string // Label with emojis, such as "ℹ️  "; empty for none
+	Color  string // Color of the message, such as ColorCyan; empty prints it plain, without bold
+}
+
+// levelStyle returns the style of a level: its entry in LevelStyles, or else its default
+func (c *OutputConfig) levelStyle(level OutputLevel) LevelStyle {
+	if style, ok := c.LevelStyles[level]; ok {
+		return style
+	}
+	return LevelStyle{Prefix: outputPrefixes[level], Emoji: outputEmojis[level], Color: outputColors[level]}
+}
@@ -0,0 +1,281 @@
+package palantir
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ParseDataToTree converts an in-memory Go value, such as a decoded config or API
+// response, to a TreeNode structure built from YAMLNodes. Maps become objects with their
+// keys formatted by fmt and sorted, structs become objects of their exported fields,
+// slices and arrays become arrays, and pointers and interfaces are followed. A value that contains itself is cut off with a "cycle" node.
+func ParseDataToTree(v interface{}, opts ...BuildOption) (*TreeNode, error) {
+	return parseDataToTree(v, "root", nil, opts)
+}
+
+// BuildTreeFromStruct converts a struct, or a pointer to one, to a tree rooted at a node
+// named name, like ParseDataToTree. Fields are named by their yaml or json tag, and nil
+// pointers and interfaces become "<nil>" scalars, as fmt prints them.
+func BuildTreeFromStruct(name string, v interface{}) (*TreeNode, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got %T", v)
+	}
+	return parseDataToTree(v, name, nilStructValue, nil)
+}
+
+// nilStructValue is the value BuildTreeFromStruct shows for nil pointers and interfaces
+const nilStructValue = "<nil>"
+
+// parseDataToTree converts a Go value to a tree rooted at a node named rootName, showing
+// nil pointers and interfaces as nilValue
+func parseDataToTree(v interface{}, rootName string, nilValue interface{}, opts []BuildOption) (*TreeNode, error) {
+	base, err := newYAMLTreeBuilder(newBuildOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	builder := &dataTreeBuilder{yamlTreeBuilder: base, visiting: make(map[dataRef]bool), nilValue: nilValue}
+
+	root := &TreeNode{
+		Name:     rootName,
+		Data:     YAMLNode{Name: rootName, IsDir: true, NodeType: "object"},
+		Children: nil,
+	}
+
+	value, cycle, err := builder.build(root, reflect.ValueOf(v), 0, false)
+	if err != nil {
+		return nil, err
+	}
+	if isJSONContainer(value) || cycle {
+		setYAMLContainerValue(root, value)
+	} else {
+		root.Data = newValueYAMLNode(rootName, value, false)
+	}
+	return root, nil
+}
+
+// dataRef identifies a map, slice or pointer being walked, to detect cycles
+type dataRef struct {
+	kind    reflect.Kind
+	pointer uintptr
+}
+
+// dataTreeBuilder builds TreeNodes from Go values using reflection
+type dataTreeBuilder struct {
+	*yamlTreeBuilder                  // Shares options, the depth limit and redaction with YAML trees
+	visiting         map[dataRef]bool // References on the path from the root to the current value
+	nilValue         interface{}      // Value of nil pointers and interfaces, nil for a null scalar
+}
+
+// build adds the members of a map or slice to node and returns the plain Go value,
+// converting maps to map[string]interface{} and slices to []interface{}. It reports a
+// cycle instead when v refers back to a value being walked.
+func (b *dataTreeBuilder) build(node *TreeNode, v reflect.Value, depth int, redacted bool) (interface{}, bool, error) {
+	if depth > b.maxDepth {
+		return nil, false, fmt.Errorf("data nesting exceeds maximum depth of %d", b.maxDepth)
+	}
+
+	// Follow pointers and interfaces, detecting pointer cycles
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return b.nilValue, false, nil
+		}
+		if v.Kind() == reflect.Pointer {
+			ref := dataRef{kind: reflect.Pointer, pointer: v.Pointer()}
+			if b.visiting[ref] {
+				return nil, true, nil
+			}
+			b.visiting[ref] = true
+			defer delete(b.visiting, ref)
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, false, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		ref := dataRef{kind: v.Kind(), pointer: v.Pointer()}
+		if b.visiting[ref] {
+			return nil, true, nil
+		}
+		b.visiting[ref] = true
+		defer delete(b.visiting, ref)
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		// Handle maps
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			keys = append(keys, key)
+			values[key] = iter.Value()
+		}
+		sort.Strings(keys)
+
+		value := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			childValue, err := b.addChild(node, key, values[key], depth, b.isRedacted(key, redacted))
+			if err != nil {
+				return nil, false, err
+			}
+			value[key] = childValue
+		}
+		return value, false, nil
+	case reflect.Struct:
+		// Handle structs, keeping types such as time.Time that describe themselves as scalars
+		if v.Type().Implements(stringerType) {
+			return v.Interface(), false, nil
+		}
+		value := make(map[string]interface{}, v.NumField())
+		if err := b.addStructFields(node, v, value, depth, redacted); err != nil {
+			return nil, false, err
+		}
+		return value, false, nil
+	case reflect.Slice, reflect.Array:
+		// Handle slices and arrays, keeping []byte as a scalar
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("%s", v.Interface()), false, nil
+		}
+
+		value := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			child := &TreeNode{Children: nil}
+			itemValue, cycle, err := b.build(child, v.Index(i), depth+1, redacted)
+			if err != nil {
+				return nil, false, err
+			}
+
+			itemName := yamlItemName(itemValue, i)
+			switch {
+			case cycle:
+				itemName = fmt.Sprintf("[%d]", i)
+			case redacted && !isJSONContainer(itemValue):
+				itemName = RedactionMask // Scalar items are named after their value
+			}
+			itemData := newValueYAMLNode(itemName, itemValue, redacted)
+			if cycle {
+				itemData = YAMLNode{Name: itemName, IsDir: false, NodeType: "cycle", Redacted: redacted}
+			}
+			itemData.Item, itemData.Index = true, i
+			child.Name = itemName
+			child.Data = itemData
+			node.Children = append(node.Children, child)
+			value = append(value, itemValue)
+		}
+		return value, false, nil
+	default:
+		// Handle scalar values
+		return v.Interface(), false, nil
+	}
+}
+
+// addChild adds a named child for v below node and returns its plain Go value
+func (b *dataTreeBuilder) addChild(node *TreeNode, name string, v reflect.Value, depth int, redacted bool) (interface{}, error) {
+	child := &TreeNode{Name: name, Children: nil}
+	childValue, cycle, err := b.build(child, v, depth+1, redacted)
+	if err != nil {
+		return nil, err
+	}
+
+	if cycle {
+		child.Data = YAMLNode{Name: name, IsDir: false, NodeType: "cycle", Redacted: redacted}
+	} else {
+		child.Data = newValueYAMLNode(name, childValue, redacted)
+	}
+	node.Children = append(node.Children, child)
+	return childValue, nil
+}
+
+// addStructFields adds the exported fields of a struct below node in declaration order.
+// Fields are named by their yaml or json tag and skipped when tagged "-". Embedded
+// structs are flattened into node unless WithNestEmbedded is given, and fields tagged
+// palantir:"redact" are masked.
+func (b *dataTreeBuilder) addStructFields(node *TreeNode, v reflect.Value, value map[string]interface{}, depth int, redacted bool) error {
+	structType := v.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, tagged := structFieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		// Unexported embedded structs are always flattened, as their fields are promoted
+		fieldValue := v.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		if field.Anonymous && ((!tagged && !b.options.NestEmbedded) || !field.IsExported()) {
+			embedded := reflect.Indirect(fieldValue)
+			if embedded.Kind() == reflect.Struct && !embedded.Type().Implements(stringerType) {
+				if err := b.addStructFields(node, embedded, value, depth, redacted); err != nil {
+					return err
+				}
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+		}
+
+		fieldRedacted := b.isRedacted(name, redacted) || field.Tag.Get("palantir") == "redact"
+		childValue, err := b.addChild(node, name, fieldValue, depth, fieldRedacted)
+		if err != nil {
+			return err
+		}
+		value[name] = childValue
+	}
+	return nil
+}
+
+// structFieldName returns the name of a struct field from its yaml or json tag, falling
+// back to the Go field name, and whether a tag provided it
+func structFieldName(field reflect.StructField) (string, bool) {
+	for _, key := range []string{"yaml", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name, true
+		}
+	}
+	return field.Name, false
+}
+
+// stringerType is used to render values that describe themselves, such as time.Time, as scalars
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// ShowStructHierarchy displays a struct, or a pointer to one, as a tree of its exported
+// fields. It accepts the same values and options as ShowDataHierarchy.
+func ShowStructHierarchy(v interface{}, opts ...BuildOption) error {
+	return ShowDataHierarchy(v, opts...)
+}
+
+// ShowDataHierarchy displays an in-memory Go value, such as a map[string]interface{},
+// as a tree structure
+func ShowDataHierarchy(v interface{}, opts ...BuildOption) error {
+	root, err := ParseDataToTree(v, opts...)
+	if err != nil {
+		return err
+	}
+
+	options := newBuildOptions(opts)
+	if options.KeyOrder == KeyOrderAlphabetical {
+		sortTree(root)
+	}
+	renderLimitedTree(root, options)
+	return nil
+}
@@ -0,0 +1,265 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDataToTree(t *testing.T) {
+	data := map[string]interface{}{
+		"server": map[string]interface{}{
+			"host":  "localhost",
+			"port":  8080,
+			"owner": nil,
+		},
+		"features": []string{"auth", "logging"},
+		"replicas": []interface{}{
+			map[string]interface{}{"name": "db2"},
+			3,
+		},
+	}
+
+	root, err := ParseDataToTree(data)
+	if err != nil {
+		t.Fatalf("ParseDataToTree() error = %v", err)
+	}
+
+	if names := childNames(root); strings.Join(names, ",") != "features,replicas,server" {
+		t.Errorf("Expected sorted map keys, got %v", names)
+	}
+
+	server := findChild(root, "server")
+	if names := childNames(server); strings.Join(names, ",") != "host,owner,port" {
+		t.Errorf("Expected nested map keys, got %v", names)
+	}
+	if data := findChild(server, "owner").Data.(YAMLNode); data.NodeType != "scalar" || data.Value != nil {
+		t.Errorf("Expected nil to be a null scalar, got %+v", data)
+	}
+
+	features := findChild(root, "features")
+	if !isSequenceNode(features.Data) || strings.Join(childNames(features), ",") != "auth,logging" {
+		t.Errorf("Expected a sequence of features, got %v", childNames(features))
+	}
+
+	replicas := findChild(root, "replicas")
+	if names := childNames(replicas); strings.Join(names, ",") != "[0],3" {
+		t.Errorf("Expected mixed slice items, got %v", names)
+	}
+	if findChild(replicas.Children[0], "name") == nil {
+		t.Error("Expected the map inside the slice to be walked")
+	}
+}
+
+func TestParseDataToTreeNonStringKeys(t *testing.T) {
+	root, err := ParseDataToTree(map[int]bool{10: true, 2: false})
+	if err != nil {
+		t.Fatalf("ParseDataToTree() error = %v", err)
+	}
+
+	if names := childNames(root); strings.Join(names, ",") != "10,2" {
+		t.Errorf("Expected keys formatted with fmt, got %v", names)
+	}
+	if value := findChild(root, "2").Data.(YAMLNode).Value; value != false {
+		t.Errorf("Expected the typed value to be kept, got %v", value)
+	}
+}
+
+func TestShowDataHierarchyCycle(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	cyclic := map[string]interface{}{"name": "node"}
+	cyclic["self"] = cyclic
+	cyclic["children"] = []interface{}{cyclic}
+
+	output := captureOutput(func() {
+		if err := ShowDataHierarchy(cyclic, WithShowValues()); err != nil {
+			t.Errorf("ShowDataHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "├── children\n│   └── [0] ↩ cycle\n├── name: node\n└── self ↩ cycle\n"
+	if output != expected {
+		t.Errorf("ShowDataHierarchy() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowDataHierarchyPointers(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	port := 8080
+	var missing *int
+	output := captureOutput(func() {
+		ShowDataHierarchy(&map[string]interface{}{"port": &port, "missing": missing}, WithShowValues())
+	})
+
+	if expected := "├── missing: null\n└── port: 8080\n"; output != expected {
+		t.Errorf("ShowDataHierarchy() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestParseDataToTreeNil(t *testing.T) {
+	root, err := ParseDataToTree(nil)
+	if err != nil {
+		t.Fatalf("ParseDataToTree() error = %v", err)
+	}
+	if data := root.Data.(YAMLNode); data.NodeType != "scalar" || data.Value != nil || len(root.Children) != 0 {
+		t.Errorf("Expected a null scalar root, got %+v", data)
+	}
+}
+
+type structTestMetadata struct {
+	Owner string `json:"owner,omitempty"`
+}
+
+type structTestDatabase struct {
+	Host     string `yaml:"host"`
+	Password string `yaml:"password" palantir:"redact"`
+}
+
+type structTestConfig struct {
+	structTestMetadata
+	Name     string              `yaml:"name"`
+	Database *structTestDatabase `yaml:"database"`
+	Replica  *structTestDatabase `yaml:"replica"`
+	Created  time.Time           `yaml:"created"`
+	Tags     []string            `yaml:"tags"`
+	Limits   map[string]int      `yaml:"limits"`
+	Internal string              `yaml:"-"`
+	Timeout  time.Duration
+	Parent   *structTestConfig `yaml:"parent"`
+	secret   string
+}
+
+func TestShowStructHierarchy(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	config := &structTestConfig{
+		structTestMetadata: structTestMetadata{Owner: "ops"},
+		Name:               "palantir",
+		Database:           &structTestDatabase{Host: "db.local", Password: "hunter2"},
+		Created:            time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Tags:               []string{"cli"},
+		Limits:             map[string]int{"cpu": 2},
+		Internal:           "hidden",
+		Timeout:            30 * time.Second,
+		secret:             "hidden",
+	}
+	config.Parent = config
+
+	output := captureOutput(func() {
+		if err := ShowStructHierarchy(config, WithShowValues()); err != nil {
+			t.Errorf("ShowStructHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "├── owner: ops\n" +
+		"├── name: palantir\n" +
+		"├── database\n" +
+		"│   ├── host: db.local\n" +
+		"│   └── password: " + RedactionMask + "\n" +
+		"├── replica: null\n" +
+		"├── created: 2024-05-01T12:00:00Z\n" +
+		"├── tags\n" +
+		"│   └── cli\n" +
+		"├── limits\n" +
+		"│   └── cpu: 2\n" +
+		"├── Timeout: 30s\n" +
+		"└── parent ↩ cycle\n"
+	if output != expected {
+		t.Errorf("ShowStructHierarchy() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestParseDataToTreeNestEmbedded(t *testing.T) {
+	type Metadata struct {
+		Owner string `yaml:"owner"`
+	}
+	type config struct {
+		Metadata
+		Name string `yaml:"name"`
+	}
+	value := config{Metadata: Metadata{Owner: "ops"}, Name: "palantir"}
+
+	root, err := ParseDataToTree(value)
+	if err != nil {
+		t.Fatalf("ParseDataToTree() error = %v", err)
+	}
+	if names := childNames(root); strings.Join(names, ",") != "owner,name" {
+		t.Errorf("Expected embedded fields to be flattened, got %v", names)
+	}
+
+	root, err = ParseDataToTree(value, WithNestEmbedded())
+	if err != nil {
+		t.Fatalf("ParseDataToTree() error = %v", err)
+	}
+	embedded := findChild(root, "Metadata")
+	if embedded == nil || findChild(embedded, "owner") == nil {
+		t.Fatalf("Expected the embedded struct to be nested, got %v", childNames(root))
+	}
+	if findChild(root, "owner") != nil {
+		t.Error("Expected embedded fields not to be flattened")
+	}
+}
+
+func TestBuildTreeFromStruct(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	config := &structTestConfig{
+		Name:     "palantir",
+		Database: &structTestDatabase{Host: "db.local", Password: "hunter2"},
+		Tags:     []string{"cli", "tree"},
+		Limits:   map[string]int{"cpu": 2},
+	}
+	config.Parent = config
+
+	root, err := BuildTreeFromStruct("config", config)
+	if err != nil {
+		t.Fatalf("BuildTreeFromStruct() error = %v", err)
+	}
+
+	for path, nodeType := range map[string]string{"": "object", "database": "object", "tags": "array", "limits": "object", "name": "scalar", "replica": "scalar", "parent": "cycle"} {
+		node := root.FindByPathString(path, ".")
+		if node == nil {
+			t.Errorf("Expected a node at %q", path)
+			continue
+		}
+		if got := node.Data.(YAMLNode).NodeType; got != nodeType {
+			t.Errorf("Node %q has type %q, want %q", path, got, nodeType)
+		}
+	}
+	if value, _ := root.GetValue("replica"); value != "<nil>" {
+		t.Errorf("Expected a nil pointer to be shown as <nil>, got %v", value)
+	}
+
+	expected := "config\n" +
+		"├── owner: \"\"\n" +
+		"├── name: palantir\n" +
+		"├── database\n" +
+		"│   ├── host: db.local\n" +
+		"│   └── password: " + RedactionMask + "\n" +
+		"├── replica: <nil>\n" +
+		"├── created: 0001-01-01T00:00:00Z\n" +
+		"├── tags\n" +
+		"│   ├── cli\n" +
+		"│   └── tree\n" +
+		"├── limits\n" +
+		"│   └── cpu: 2\n" +
+		"├── Timeout: 0s\n" +
+		"└── parent ↩ cycle"
+	if output := renderTreeString(root, []BuildOption{WithShowValues()}); output != expected {
+		t.Errorf("Rendered tree =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestBuildTreeFromStructRejectsOtherValues(t *testing.T) {
+	var missing *structTestConfig
+	for _, value := range []interface{}{nil, 42, map[string]int{}, missing} {
+		if _, err := BuildTreeFromStruct("value", value); err == nil {
+			t.Errorf("BuildTreeFromStruct(%#v) expected an error", value)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package palantir
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultEnvSeparator splits environment variable names into nested groups
+const DefaultEnvSeparator = "_"
+
+// envVariable is a single KEY=VALUE entry
+type envVariable struct {
+	key   string
+	value string
+}
+
+// ParseEnvToTree converts .env content to a TreeNode structure built from YAMLNodes,
+// grouping variables by the parts of their names split on sep ("_" when empty), so
+// DATABASE_HOST and DATABASE_PORT share a DATABASE node. Values of variables matching
+// DefaultSecretPatterns are masked unless WithRedactKeys or WithAllowKeys say otherwise.
+func ParseEnvToTree(envContent []byte, sep string, opts ...BuildOption) (*TreeNode, error) {
+	variables, err := parseEnvFile(envContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env file: %w", err)
+	}
+	return buildEnvTree(variables, sep, newBuildOptions(opts))
+}
+
+// parseEnvFile parses .env content, skipping blank lines and comments and accepting
+// "export " prefixes and single- or double-quoted values
+func parseEnvFile(content []byte) ([]envVariable, error) {
+	var variables []envVariable
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, rawValue, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+
+		value, err := parseEnvValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		variables = append(variables, envVariable{key: key, value: value})
+	}
+	return variables, scanner.Err()
+}
+
+// parseEnvValue unquotes a value. Double-quoted values support \n, \t, \" and \\
+// escapes, single-quoted values are literal and unquoted values end at a " #" comment.
+func parseEnvValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		var value strings.Builder
+		for i := 1; i < len(raw); i++ {
+			switch c := raw[i]; {
+			case c == '"':
+				return value.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					value.WriteByte('\n')
+				case 't':
+					value.WriteByte('\t')
+				default:
+					value.WriteByte(raw[i])
+				}
+			default:
+				value.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated double-quoted value")
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single-quoted value")
+		}
+		return raw[1 : end+1], nil
+	default:
+		if comment := strings.Index(raw, " #"); comment >= 0 {
+			raw = raw[:comment]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+}
+
+// buildEnvTree groups variables into a tree by the parts of their names. A variable
+// whose name is also a group, such as DATABASE next to DATABASE_HOST, stays a leaf
+// beside the group.
+func buildEnvTree(variables []envVariable, sep string, options BuildOptions) (*TreeNode, error) {
+	if sep == "" {
+		sep = DefaultEnvSeparator
+	}
+	if len(options.RedactKeys) == 0 {
+		options.RedactKeys = DefaultSecretPatterns
+	}
+	builder, err := newYAMLTreeBuilder(options)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &TreeNode{
+		Name:     "root",
+		Data:     YAMLNode{Name: "root", IsDir: true, NodeType: "object"},
+		Children: nil,
+	}
+
+	for _, variable := range variables {
+		var parts []string
+		for _, part := range strings.Split(variable.key, sep) {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) == 0 {
+			parts = []string{variable.key}
+		}
+
+		// Find or create the groups leading to the variable
+		current := root
+		for _, part := range parts[:len(parts)-1] {
+			group := findEnvGroup(current, part)
+			if group == nil {
+				group = &TreeNode{
+					Name:     part,
+					Data:     YAMLNode{Name: part, IsDir: true, NodeType: "object"},
+					Children: nil,
+				}
+				current.Children = append(current.Children, group)
+			}
+			current = group
+		}
+
+		name := parts[len(parts)-1]
+		current.Children = append(current.Children, &TreeNode{
+			Name:     name,
+			Data:     newValueYAMLNode(name, variable.value, builder.isRedacted(variable.key, false)),
+			Children: nil,
+		})
+	}
+
+	setTreeContainerValues(root)
+	return root, nil
+}
+
+// findEnvGroup returns the group child of node with the given name
+func findEnvGroup(node *TreeNode, name string) *TreeNode {
+	for _, child := range node.Children {
+		if child.Name == name && getIsDir(child.Data) {
+			return child
+		}
+	}
+	return nil
+}
+
+// ShowEnvHierarchy displays .env content as a tree grouped by the parts of variable
+// names split on sep ("_" when empty)
+func ShowEnvHierarchy(envContent []byte, sep string, opts ...BuildOption) error {
+	root, err := ParseEnvToTree(envContent, sep, opts...)
+	if err != nil {
+		return err
+	}
+	showEnvTree(root, newBuildOptions(opts))
+	return nil
+}
+
+// ShowEnvHierarchyFromFile reads and displays a .env file as a tree grouped on "_", up to the WithMaxReadSize limit.
+// A path of "-" reads standard input.
+func ShowEnvHierarchyFromFile(filePath string, opts ...BuildOption) error {
+	file, err := openInput(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read env file: %w", err)
+	}
+	defer file.Close()
+
+	content, err := readLimited(file, newBuildOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to read env: %w", err)
+	}
+	return ShowEnvHierarchy(content, DefaultEnvSeparator, opts...)
+}
+
+// ShowProcessEnvHierarchy displays the environment of the current process as a tree
+// grouped on "_", limited to variables starting with prefix when it is not empty.
+// Variables are listed alphabetically.
+func ShowProcessEnvHierarchy(prefix string, opts ...BuildOption) error {
+	var variables []envVariable
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		if key == "" || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		variables = append(variables, envVariable{key: key, value: value})
+	}
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].key < variables[j].key
+	})
+
+	options := newBuildOptions(opts)
+	root, err := buildEnvTree(variables, DefaultEnvSeparator, options)
+	if err != nil {
+		return err
+	}
+	showEnvTree(root, options)
+	return nil
+}
+
+// showEnvTree sorts an env tree when requested and renders it
+func showEnvTree(root *TreeNode, options BuildOptions) {
+	if options.KeyOrder == KeyOrderAlphabetical {
+		sortTree(root)
+	}
+	renderLimitedTree(root, options)
+}
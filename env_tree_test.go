@@ -0,0 +1,131 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+var dotEnv = []byte(`# Application settings
+APP_NAME=palantir
+export APP_ENV="staging" # deployed by CI
+DATABASE_HOST=db.internal
+DATABASE_PORT=5432
+DATABASE_PASSWORD='p@ss#word'
+DATABASE=postgres://db.internal
+GREETING="hello\nworld"
+API_TOKEN=abc123 # rotated monthly
+`)
+
+func TestParseEnvToTree(t *testing.T) {
+	root, err := ParseEnvToTree(dotEnv, "")
+	if err != nil {
+		t.Fatalf("ParseEnvToTree() error = %v", err)
+	}
+
+	if names := childNames(root); strings.Join(names, ",") != "APP,DATABASE,DATABASE,GREETING,API" {
+		t.Errorf("Expected variables grouped by prefix, got %v", names)
+	}
+
+	app := findChild(root, "APP")
+	if names := childNames(app); strings.Join(names, ",") != "NAME,ENV" {
+		t.Errorf("Expected APP group members, got %v", names)
+	}
+
+	tests := []struct {
+		path     []string
+		expected string
+		redacted bool
+	}{
+		{[]string{"APP", "ENV"}, "staging", false},
+		{[]string{"DATABASE", "PASSWORD"}, "p@ss#word", true},
+		{[]string{"GREETING"}, "hello\nworld", false},
+		{[]string{"API", "TOKEN"}, "abc123", true},
+	}
+	for _, tt := range tests {
+		node := root
+		for _, part := range tt.path {
+			node = findChild(node, part)
+		}
+		data := node.Data.(YAMLNode)
+		if data.Value != tt.expected || data.Redacted != tt.redacted {
+			t.Errorf("%s = %+v, want value %q redacted %v", strings.Join(tt.path, "_"), data, tt.expected, tt.redacted)
+		}
+	}
+
+	// A variable named like a group stays a leaf beside it
+	database := root.Children[2]
+	if data := database.Data.(YAMLNode); data.IsDir || data.Value != "postgres://db.internal" {
+		t.Errorf("Expected DATABASE to be a leaf, got %+v", data)
+	}
+}
+
+func TestParseEnvToTreeCustomSeparator(t *testing.T) {
+	root, err := ParseEnvToTree([]byte("APP__DB__HOST=localhost\nAPP__LOG_LEVEL=debug\n"), "__")
+	if err != nil {
+		t.Fatalf("ParseEnvToTree() error = %v", err)
+	}
+
+	app := findChild(root, "APP")
+	if names := childNames(app); strings.Join(names, ",") != "DB,LOG_LEVEL" {
+		t.Errorf("Expected grouping on the custom separator, got %v", names)
+	}
+	if host := findChild(findChild(app, "DB"), "HOST"); host == nil {
+		t.Error("Expected APP__DB__HOST to nest under APP and DB")
+	}
+}
+
+func TestParseEnvToTreeInvalid(t *testing.T) {
+	tests := []string{
+		"NOT_AN_ASSIGNMENT\n",
+		"=value\n",
+		`KEY="unterminated` + "\n",
+		"KEY='unterminated\n",
+	}
+
+	for _, content := range tests {
+		if _, err := ParseEnvToTree([]byte(content), ""); err == nil {
+			t.Errorf("Expected an error for %q", content)
+		}
+	}
+}
+
+func TestShowEnvHierarchyMasking(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	masked := captureOutput(func() {
+		if err := ShowEnvHierarchy(dotEnv, "", WithShowValues()); err != nil {
+			t.Errorf("ShowEnvHierarchy() error = %v", err)
+		}
+	})
+	if strings.Contains(masked, "abc123") || !strings.Contains(masked, "PASSWORD: "+RedactionMask) {
+		t.Errorf("Expected secrets to be masked by default, got:\n%s", masked)
+	}
+	if !strings.Contains(masked, "HOST: db.internal") {
+		t.Errorf("Expected other values to be shown, got:\n%s", masked)
+	}
+
+	unmasked := captureOutput(func() {
+		ShowEnvHierarchy(dotEnv, "", WithShowValues(), WithAllowKeys("API_TOKEN"))
+	})
+	if !strings.Contains(unmasked, "TOKEN: abc123") {
+		t.Errorf("Expected the allowlisted variable to be shown, got:\n%s", unmasked)
+	}
+}
+
+func TestShowProcessEnvHierarchy(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	t.Setenv("PALANTIR_TEST_B", "two")
+	t.Setenv("PALANTIR_TEST_A", "one")
+	t.Setenv("PALANTIR_TEST_SECRET", "hidden")
+
+	output := captureOutput(func() {
+		if err := ShowProcessEnvHierarchy("PALANTIR_TEST_", WithShowValues()); err != nil {
+			t.Errorf("ShowProcessEnvHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "└── PALANTIR\n    └── TEST\n        ├── A: one\n        ├── B: two\n        └── SECRET: " + RedactionMask + "\n"
+	if output != expected {
+		t.Errorf("ShowProcessEnvHierarchy() =\n%s\nwant:\n%s", output, expected)
+	}
+}
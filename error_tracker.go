@@ -0,0 +1,154 @@
+package palantir
+
+import "sync/atomic"
+
+// ErrorTracker is implemented by handlers that count the errors printed through them, so
+// a program can exit non-zero when code deep in a library reported a failure:
+//
+//	os.Exit(palantir.ExitCodeOf(palantir.GetGlobalOutputHandler()))
+//
+// The built-in handlers implement it, and wrappers such as PrefixedHandler forward to the
+// handler they wrap. Handlers returned by WithPrefix share their parent's count.
+type ErrorTracker interface {
+	HasErrors() bool // Whether any error was printed since the last Reset
+	ErrorCount() int // Errors printed since the last Reset
+	ExitCode() int   // Suggested exit code, 0 without errors and 1 or OutputConfig.ExitCodeFunc's result otherwise
+	Reset()          // Forget the errors printed so far, such as between dispatched commands
+}
+
+// ExitCodeOf returns handler's suggested exit code, or 0 when it does not track errors
+func ExitCodeOf(handler OutputHandler) int {
+	if tracker, ok := handler.(ErrorTracker); ok {
+		return tracker.ExitCode()
+	}
+	return 0
+}
+
+// errorCounter counts printed errors. It is shared by a handler and the handlers
+// returned by its WithPrefix, and is safe for concurrent use.
+type errorCounter struct {
+	count atomic.Int64
+}
+
+// add records n printed errors. A nil counter records nothing.
+func (c *errorCounter) add(n int) {
+	if c != nil {
+		c.count.Add(int64(n))
+	}
+}
+
+// value returns the number of errors recorded. A nil counter has none.
+func (c *errorCounter) value() int {
+	if c == nil {
+		return 0
+	}
+	return int(c.count.Load())
+}
+
+// reset forgets the recorded errors
+func (c *errorCounter) reset() {
+	if c != nil {
+		c.count.Store(0)
+	}
+}
+
+// exitCode maps an error count to an exit code: 0 without errors, otherwise the result of
+// the configured ExitCodeFunc or 1
+func exitCode(config *OutputConfig, errorCount int) int {
+	if errorCount == 0 {
+		return 0
+	}
+	if config != nil && config.ExitCodeFunc != nil {
+		return config.ExitCodeFunc(errorCount)
+	}
+	return 1
+}
+
+// innerTracker returns the ErrorTracker of a wrapped handler, or nil when it has none
+func innerTracker(inner OutputHandler) ErrorTracker {
+	tracker, _ := inner.(ErrorTracker)
+	return tracker
+}
+
+func (oh *outputHandler) HasErrors() bool {
+	return oh.errs.value() > 0
+}
+
+func (oh *outputHandler) ErrorCount() int {
+	return oh.errs.value()
+}
+
+func (oh *outputHandler) ExitCode() int {
+	return exitCode(oh.config, oh.errs.value())
+}
+
+func (oh *outputHandler) Reset() {
+	oh.errs.reset()
+}
+
+func (sh *slogOutputHandler) HasErrors() bool {
+	return sh.errs.value() > 0
+}
+
+func (sh *slogOutputHandler) ErrorCount() int {
+	return sh.errs.value()
+}
+
+func (sh *slogOutputHandler) ExitCode() int {
+	return exitCode(sh.config, sh.errs.value())
+}
+
+func (sh *slogOutputHandler) Reset() {
+	sh.errs.reset()
+}
+
+// HasErrors reports whether the wrapped handler printed errors
+func (p *PrefixedHandler) HasErrors() bool {
+	return p.ErrorCount() > 0
+}
+
+// ErrorCount returns the errors printed by the wrapped handler, 0 when it does not track them
+func (p *PrefixedHandler) ErrorCount() int {
+	if tracker := innerTracker(p.inner); tracker != nil {
+		return tracker.ErrorCount()
+	}
+	return 0
+}
+
+// ExitCode returns the wrapped handler's suggested exit code
+func (p *PrefixedHandler) ExitCode() int {
+	return ExitCodeOf(p.inner)
+}
+
+// Reset forgets the errors printed by the wrapped handler
+func (p *PrefixedHandler) Reset() {
+	if tracker := innerTracker(p.inner); tracker != nil {
+		tracker.Reset()
+	}
+}
+
+// HasErrors reports whether the wrapped handler printed errors. Filtered errors were
+// never printed, so they are not counted.
+func (f *LevelFilterHandler) HasErrors() bool {
+	return f.ErrorCount() > 0
+}
+
+// ErrorCount returns the errors printed by the wrapped handler, 0 when it does not track them
+func (f *LevelFilterHandler) ErrorCount() int {
+	if tracker := innerTracker(f.inner); tracker != nil {
+		return tracker.ErrorCount()
+	}
+	return 0
+}
+
+// ExitCode returns the wrapped handler's suggested exit code
+func (f *LevelFilterHandler) ExitCode() int {
+	return ExitCodeOf(f.inner)
+}
+
+// Reset forgets the errors printed by the wrapped handler
+func (f *LevelFilterHandler) Reset() {
+	if tracker := innerTracker(f.inner); tracker != nil {
+		tracker.Reset()
+	}
+}
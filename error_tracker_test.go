@@ -0,0 +1,126 @@
+package palantir
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+func TestErrorTrackerCountsErrors(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithWriter(&buf).Build())
+
+	if handler.HasErrors() || handler.ExitCode() != 0 {
+		t.Fatalf("Expected a new handler to have no errors")
+	}
+
+	handler.PrintInfo("starting")
+	handler.PrintWarning("slow")
+	handler.PrintError("failed: %s", "disk full")
+	handler.PrintErrors(errors.Join(errors.New("a"), errors.New("b")), nil)
+	handler.LogTree(LevelError, &TreeNode{Name: "broken"})
+
+	if got := handler.ErrorCount(); got != 4 {
+		t.Errorf("ErrorCount() = %d, want 4", got)
+	}
+	if !handler.HasErrors() || handler.ExitCode() != 1 {
+		t.Errorf("Expected errors and exit code 1, got %v and %d", handler.HasErrors(), handler.ExitCode())
+	}
+
+	handler.Reset()
+	if handler.HasErrors() || handler.ErrorCount() != 0 || handler.ExitCode() != 0 {
+		t.Errorf("Expected Reset to forget the errors, got %d", handler.ErrorCount())
+	}
+}
+
+func TestErrorTrackerCountsDisabledOutput(t *testing.T) {
+	handler := NewOutputHandler(&OutputConfig{DisableOutput: true})
+	handler.PrintError("hidden")
+	handler.PrintErrors(errors.New("also hidden"))
+
+	if got := handler.ErrorCount(); got != 2 {
+		t.Errorf("ErrorCount() = %d, want 2", got)
+	}
+}
+
+func TestErrorTrackerExitCodeFunc(t *testing.T) {
+	config := NewConfigBuilder().WithWriter(io.Discard).WithExitCodeFunc(func(errorCount int) int {
+		return min(errorCount, 3) + 10
+	}).Build()
+	handler := NewOutputHandler(config)
+
+	if got := handler.ExitCode(); got != 0 {
+		t.Errorf("ExitCode() without errors = %d, want 0", got)
+	}
+	handler.PrintError("one")
+	handler.PrintError("two")
+	if got := handler.ExitCode(); got != 12 {
+		t.Errorf("ExitCode() = %d, want 12", got)
+	}
+}
+
+func TestErrorTrackerThroughWrappers(t *testing.T) {
+	var buf bytes.Buffer
+	inner := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithWriter(&buf).Build())
+	prefixed := NewPrefixedHandler(inner, "api")
+	filtered := NewLevelFilterHandler(prefixed.WithPrefix("db"), LevelError)
+
+	prefixed.PrintError("from the prefixed handler")
+	filtered.PrintError("from the filtered handler")
+	inner.WithPrefix("worker").PrintErrors(errors.New("from a prefix"))
+
+	for name, tracker := range map[string]ErrorTracker{"inner": inner, "prefixed": prefixed, "filtered": filtered} {
+		if got := tracker.ErrorCount(); got != 3 {
+			t.Errorf("%s ErrorCount() = %d, want 3", name, got)
+		}
+		if got := ExitCodeOf(tracker.(OutputHandler)); got != 1 {
+			t.Errorf("%s ExitCodeOf() = %d, want 1", name, got)
+		}
+	}
+
+	filtered.Reset()
+	if inner.HasErrors() || prefixed.HasErrors() {
+		t.Errorf("Expected Reset through a wrapper to reset the wrapped handler")
+	}
+
+	// Wrapping a handler that does not track errors reports none
+	untracked := NewPrefixedHandler(&customOutputHandler{}, "x")
+	untracked.PrintError("ignored")
+	if untracked.HasErrors() || untracked.ExitCode() != 0 {
+		t.Errorf("Expected a wrapper around an untracked handler to report no errors")
+	}
+}
+
+func TestErrorTrackerSlogHandler(t *testing.T) {
+	handler := NewSlogHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	handler.WithPrefix("api").PrintError("failed")
+	handler.PrintErrors(errors.New("a"), errors.New("b"))
+
+	if got := ExitCodeOf(handler); got != 1 {
+		t.Errorf("ExitCodeOf() = %d, want 1", got)
+	}
+	if got := handler.(ErrorTracker).ErrorCount(); got != 3 {
+		t.Errorf("ErrorCount() = %d, want 3", got)
+	}
+}
+
+func TestErrorTrackerConcurrentErrors(t *testing.T) {
+	handler := NewOutputHandler(&OutputConfig{DisableOutput: true})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.PrintError("failed")
+		}()
+	}
+	wg.Wait()
+
+	if got := handler.ErrorCount(); got != 50 {
+		t.Errorf("ErrorCount() = %d, want 50", got)
+	}
+}
@@ -0,0 +1,122 @@
+package palantir
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// TreeBuilder builds a tree of nodes rooted at the given path
+type TreeBuilder interface {
+	Build(root string) (*TreeNode, error)
+}
+
+// OSTreeBuilder builds trees from the local filesystem
+type OSTreeBuilder struct{}
+
+// NewOSTreeBuilder creates a TreeBuilder for the local filesystem
+func NewOSTreeBuilder() *OSTreeBuilder {
+	return &OSTreeBuilder{}
+}
+
+// Build walks basePath on disk and returns its tree. FileNode paths are OS paths.
+func (b *OSTreeBuilder) Build(basePath string) (*TreeNode, error) {
+	// Get root directory info
+	rootInfo, err := os.Stat(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	root := newFileTreeRoot(rootInfo, basePath)
+
+	// Build tree structure by walking filesystem
+	if err := buildTree(root, basePath); err != nil {
+		return nil, fmt.Errorf("failed to build tree: %w", err)
+	}
+	return root, nil
+}
+
+// FSTreeBuilder builds trees from any fs.FS, such as embed.FS or fstest.MapFS
+type FSTreeBuilder struct {
+	FS fs.FS
+}
+
+// NewFSTreeBuilder creates a TreeBuilder reading from fsys
+func NewFSTreeBuilder(fsys fs.FS) *FSTreeBuilder {
+	return &FSTreeBuilder{FS: fsys}
+}
+
+// Build walks root within the filesystem and returns its tree. FileNode paths are
+// slash-separated fs.FS paths.
+func (b *FSTreeBuilder) Build(root string) (*TreeNode, error) {
+	rootInfo, err := fs.Stat(b.FS, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path: %w", err)
+	}
+
+	node := newFileTreeRoot(rootInfo, root)
+
+	err = walkFS(node, b.FS, root, func(relPath string) string {
+		return path.Join(root, relPath)
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tree: %w", err)
+	}
+	return node, nil
+}
+
+// ShowHierarchyFS displays a tree structure of the files/directories under root in fsys.
+// WithGitStatus has no effect since an fs.FS has no working tree.
+func ShowHierarchyFS(fsys fs.FS, root string, opts ...BuildOption) (error, bool) {
+	node, stats, err := NewFSTreeBuilder(fsys).BuildWithStats(root)
+	if err != nil {
+		return err, false
+	}
+	return showTimedFileTree(node, stats, newBuildOptions(opts))
+}
+
+// newFileTreeRoot creates the root node of a filesystem tree
+func newFileTreeRoot(info fs.FileInfo, rootPath string) *TreeNode {
+	return &TreeNode{
+		Name: info.Name(),
+		Data: FileNode{
+			Name:    info.Name(),
+			Path:    rootPath,
+			IsDir:   info.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime().Unix(),
+		},
+		Children: nil,
+	}
+}
+
+// readOSLink returns the target of the symbolic link at linkPath and whether the target
+// is missing. Unreadable links have no target.
+func readOSLink(linkPath string) (string, bool) {
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return "", false
+	}
+	_, err = os.Stat(linkPath)
+	return target, err != nil
+}
+
+// styleLinkTarget returns the " -> target" following a symbolic link, with the target
+// colored like a file of its type, dimmed otherwise, and broken links marked "(broken)"
+func styleLinkTarget(node *TreeNode) string {
+	fileNode, ok := node.Data.(FileNode)
+	if !ok || fileNode.LinkTarget == "" {
+		return ""
+	}
+
+	target := styleDim(fileNode.LinkTarget)
+	if category, ok := fileCategoryFor(fileNode.LinkTarget); ok && !fileNode.LinkBroken {
+		target = styleTreeText(fileNode.LinkTarget, category.color, false)
+	}
+	styled := " " + styleDim("->") + " " + target
+	if fileNode.LinkBroken {
+		styled += " " + styleDim("(broken)")
+	}
+	return styled
+}
@@ -0,0 +1,206 @@
+package palantir
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed testdata/fstree
+var embeddedFixture embed.FS
+
+func TestFSTreeBuilderMapFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"file1.txt":            {Data: []byte("hello")},
+		"dir1/file2.go":        {Data: []byte("package dir1")},
+		"dir1/subdir/file3.md": {Data: []byte("# title")},
+		"dir2/file4.json":      {Data: []byte("{}")},
+		".hidden/secret.txt":   {Data: []byte("secret")},
+		"dir1/.env":            {Data: []byte("TOKEN=1")},
+	}
+
+	root, err := NewFSTreeBuilder(fsys).Build(".")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if len(root.Children) != 3 { // file1.txt, dir1, dir2
+		t.Fatalf("Expected 3 children, got %d", len(root.Children))
+	}
+
+	var dir1, file1 *TreeNode
+	for _, child := range root.Children {
+		switch child.Name {
+		case "dir1":
+			dir1 = child
+		case "file1.txt":
+			file1 = child
+		}
+	}
+
+	if dir1 == nil || !getIsDir(dir1.Data) {
+		t.Fatal("dir1 directory not found in tree")
+	}
+	if len(dir1.Children) != 2 { // file2.go, subdir
+		t.Errorf("Expected dir1 to have 2 children, got %d", len(dir1.Children))
+	}
+
+	if file1 == nil {
+		t.Fatal("file1.txt not found in tree")
+	}
+	fileNode := file1.Data.(FileNode)
+	if fileNode.Size != 5 {
+		t.Errorf("Expected file1.txt size 5, got %d", fileNode.Size)
+	}
+	if fileNode.Path != "file1.txt" {
+		t.Errorf("Expected file1.txt path %q, got %q", "file1.txt", fileNode.Path)
+	}
+}
+
+func TestFSTreeBuilderSubdirectoryRoot(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/base.html":         {Data: []byte("<html>")},
+		"templates/partials/nav.html": {Data: []byte("<nav>")},
+		"static/app.js":               {Data: []byte("")},
+	}
+
+	root, err := NewFSTreeBuilder(fsys).Build("templates")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if root.Name != "templates" {
+		t.Errorf("Expected root name %q, got %q", "templates", root.Name)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("Expected 2 children, got %d", len(root.Children))
+	}
+
+	for _, child := range root.Children {
+		if child.Name == "partials" {
+			nav := child.Children[0].Data.(FileNode)
+			if nav.Path != "templates/partials/nav.html" {
+				t.Errorf("Expected nav.html path %q, got %q", "templates/partials/nav.html", nav.Path)
+			}
+		}
+	}
+}
+
+func TestFSTreeBuilderMissingRoot(t *testing.T) {
+	_, err := NewFSTreeBuilder(fstest.MapFS{}).Build("missing")
+	if err == nil {
+		t.Error("Expected error for missing root, got nil")
+	}
+
+	err, hasHierarchy := ShowHierarchyFS(fstest.MapFS{}, "missing")
+	if err == nil {
+		t.Error("Expected ShowHierarchyFS() error for missing root, got nil")
+	}
+	if hasHierarchy {
+		t.Error("Expected hasHierarchy=false for missing root")
+	}
+}
+
+func TestShowHierarchyFSMatchesOS(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	osOutput := captureOutput(func() {
+		if err, _ := ShowHierarchy("testdata/fstree", "", WithShowRoot()); err != nil {
+			t.Errorf("ShowHierarchy() error = %v", err)
+		}
+	})
+	embedOutput := captureOutput(func() {
+		if err, _ := ShowHierarchyFS(embeddedFixture, "testdata/fstree", WithShowRoot()); err != nil {
+			t.Errorf("ShowHierarchyFS() error = %v", err)
+		}
+	})
+
+	if embedOutput != osOutput {
+		t.Errorf("ShowHierarchyFS() output = %q, want %q", embedOutput, osOutput)
+	}
+	if !strings.Contains(embedOutput, "file3.md") {
+		t.Errorf("Expected nested file in output, got %q", embedOutput)
+	}
+}
+
+func TestTreeBuildersImplementInterface(t *testing.T) {
+	builders := []TreeBuilder{
+		NewOSTreeBuilder(),
+		NewFSTreeBuilder(embeddedFixture),
+	}
+
+	for _, builder := range builders {
+		root, err := builder.Build("testdata/fstree")
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if len(root.Children) != 3 {
+			t.Errorf("%T: expected 3 children, got %d", builder, len(root.Children))
+		}
+	}
+}
+
+func TestShowHierarchyLinkTargets(t *testing.T) {
+	tempDir := createFileFixture(t, []string{"config.yaml", "notes"})
+	for link, target := range map[string]string{"current.yaml": "config.yaml", "missing": "gone/file.txt", "plain": "notes"} {
+		if err := os.Symlink(target, filepath.Join(tempDir, link)); err != nil {
+			t.Skipf("Symlinks are not supported: %v", err)
+		}
+	}
+
+	root, err := NewOSTreeBuilder().Build(tempDir)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	current := root.ChildNamed("current.yaml").Data.(FileNode)
+	if current.LinkTarget != "config.yaml" || current.LinkBroken {
+		t.Errorf("Expected a link to config.yaml, got %+v", current)
+	}
+	if missing := root.ChildNamed("missing").Data.(FileNode); missing.LinkTarget != "gone/file.txt" || !missing.LinkBroken {
+		t.Errorf("Expected a broken link to gone/file.txt, got %+v", missing)
+	}
+	if notes := root.ChildNamed("notes").Data.(FileNode); notes.LinkTarget != "" {
+		t.Errorf("Expected no link target on a regular file, got %+v", notes)
+	}
+
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	output := captureOutput(func() {
+		ShowHierarchy(tempDir, "", WithLinkTargets())
+	})
+	expected := "├── config.yaml\n" +
+		"├── current.yaml -> config.yaml\n" +
+		"├── missing -> gone/file.txt (broken)\n" +
+		"├── notes\n" +
+		"└── plain -> notes\n"
+	if output != expected {
+		t.Errorf("ShowHierarchy() output = %q, want %q", output, expected)
+	}
+
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+	output = captureOutput(func() {
+		ShowHierarchy(tempDir, "", WithLinkTargets())
+	})
+	category, _ := fileCategoryFor("config.yaml")
+	for _, styled := range []string{
+		ColorDim + "->" + ColorReset + " " + category.color + "config.yaml" + ColorReset,
+		ColorDim + "gone/file.txt" + ColorReset + " " + ColorDim + "(broken)" + ColorReset,
+		ColorDim + "->" + ColorReset + " " + ColorDim + "notes" + ColorReset,
+	} {
+		if !strings.Contains(output, styled) {
+			t.Errorf("Expected %q in the colored output, got %q", styled, output)
+		}
+	}
+
+	output = captureOutput(func() {
+		ShowHierarchy(tempDir, "")
+	})
+	if strings.Contains(output, "->") {
+		t.Errorf("Expected no link targets by default, got %q", output)
+	}
+}
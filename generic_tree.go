@@ -0,0 +1,186 @@
+package palantir
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tree is a tree of arbitrary data, such as packages, services or menu entries, that is
+// rendered with the same connectors as the built-in trees
+type Tree[T any] struct {
+	root *Node[T]
+}
+
+// Node is a named node of a Tree holding data of type T
+type Node[T any] struct {
+	Name     string
+	Data     T
+	Children []*Node[T]
+
+	implicit bool // Created by Insert as an intermediate node, so its data may still be set
+}
+
+// NodeStyler returns the text displayed for a node when a Tree is rendered
+type NodeStyler[T any] interface {
+	StyleNode(node *Node[T]) string
+}
+
+// NodeStylerFunc adapts a function to the NodeStyler interface
+type NodeStylerFunc[T any] func(node *Node[T]) string
+
+// StyleNode calls f(node)
+func (f NodeStylerFunc[T]) StyleNode(node *Node[T]) string {
+	return f(node)
+}
+
+// NewTree creates a tree whose root is named name and holds rootData
+func NewTree[T any](rootData T, name string) *Tree[T] {
+	return &Tree[T]{root: &Node[T]{Name: name, Data: rootData}}
+}
+
+// Root returns the root node of the tree
+func (t *Tree[T]) Root() *Node[T] {
+	return t.root
+}
+
+// Insert adds a node holding data at the path below the root, such as
+// []string{"services", "api"}, creating missing intermediate nodes with the zero value
+// of T. Inserting at the path of an intermediate node sets its data, while inserting at
+// a path that was already inserted is an error.
+func (t *Tree[T]) Insert(pathParts []string, data T) error {
+	if len(pathParts) == 0 {
+		return fmt.Errorf("cannot insert at an empty path")
+	}
+
+	node := t.root
+	for i, part := range pathParts {
+		if part == "" {
+			return fmt.Errorf("cannot insert at %q: empty path element", strings.Join(pathParts, "/"))
+		}
+
+		child := node.Child(part)
+		isTarget := i == len(pathParts)-1
+		switch {
+		case child == nil:
+			child = &Node[T]{Name: part, implicit: !isTarget}
+			if isTarget {
+				child.Data = data
+			}
+			node.Children = append(node.Children, child)
+		case isTarget && !child.implicit:
+			return fmt.Errorf("cannot insert at %q: node already exists", strings.Join(pathParts, "/"))
+		case isTarget:
+			child.Data, child.implicit = data, false
+		}
+		node = child
+	}
+	return nil
+}
+
+// Child returns the direct child of n with the given name, or nil
+func (n *Node[T]) Child(name string) *Node[T] {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// Sort recursively orders the children of every node with less. Children that compare
+// equal keep their insertion order.
+func (t *Tree[T]) Sort(less func(a, b *Node[T]) bool) {
+	sortGenericNode(t.root, less)
+}
+
+// sortGenericNode sorts the children of node and, recursively, of its descendants
+func sortGenericNode[T any](node *Node[T], less func(a, b *Node[T]) bool) {
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		return less(node.Children[i], node.Children[j])
+	})
+	for _, child := range node.Children {
+		sortGenericNode(child, less)
+	}
+}
+
+// Render prints the tree with the connectors selected by the global handler's
+// OutputConfig, displaying each node as styled by styler. A nil styler displays node
+// names. WithShowRoot labels the tree with the root node, and WithPathFilter, which
+// matches node names, WithMaxDepth, WithMaxNodes and WithMaxLines apply as they do to the
+// built-in trees. In accessible mode, nodes are printed as indented "level 2: name" lines
+// instead.
+func (t *Tree[T]) Render(styler NodeStyler[T], opts ...BuildOption) {
+	if styler == nil {
+		styler = NodeStylerFunc[T](func(node *Node[T]) string { return node.Name })
+	}
+	style := func(node *TreeNode) string {
+		if original, ok := node.Data.(*Node[T]); ok {
+			return styler.StyleNode(original)
+		}
+		return styleFileNode(node) // A marker standing in for content hidden by MaxDepth
+	}
+	renderLimited(mirrorGenericTree(t.root), newBuildOptions(opts), func(root *TreeNode, options BuildOptions) {
+		renderGenericTree(root, style, options)
+	})
+}
+
+// mirrorGenericTree copies the shape of a generic tree into TreeNodes holding the
+// original nodes, so the limits of the built-in trees apply to it
+func mirrorGenericTree[T any](node *Node[T]) *TreeNode {
+	mirror := &TreeNode{Name: node.Name, Data: node}
+	for _, child := range node.Children {
+		mirror.Children = append(mirror.Children, mirrorGenericTree(child))
+	}
+	return mirror
+}
+
+// renderGenericTree prints a mirrored generic tree, displaying each node as styled by style
+func renderGenericTree(root *TreeNode, style func(node *TreeNode) string, options BuildOptions) {
+	defer recoverPanic(treeOutputConfig())
+	flushGlobalOutput()
+
+	if options.MaxLines > 0 {
+		options.lineBudget = &lineBudget{max: options.MaxLines}
+	}
+	if treeOutputConfig().accessible() {
+		printAccessibleGenericTree(root, 1, style, options)
+	} else if !options.ShowRoot || printTreeLine(style(root), options) {
+		printGenericTree(root, "", style, treeConnectorsFor(treeOutputConfig()), options)
+	}
+	printTruncationNotice(options)
+}
+
+// printAccessibleGenericTree prints node and its descendants as indented "level 2: name"
+// lines for screen readers, the root being level 1. It reports whether the line budget
+// let every line through.
+func printAccessibleGenericTree(node *TreeNode, level int, style func(node *TreeNode) string, options BuildOptions) bool {
+	if level > 1 || options.ShowRoot {
+		line := fmt.Sprintf("%slevel %d: %s", strings.Repeat("  ", level-1), level, stripANSI(style(node)))
+		if !printTreeLine(line, options) {
+			return false
+		}
+	}
+	for _, child := range node.Children {
+		if !printAccessibleGenericTree(child, level+1, style, options) {
+			return false
+		}
+	}
+	return true
+}
+
+// printGenericTree prints the children of node below prefix, reporting whether the line
+// budget let every line through
+func printGenericTree(node *TreeNode, prefix string, style func(node *TreeNode) string, connectors treeConnectors, options BuildOptions) bool {
+	for i, child := range node.Children {
+		treeChar, childPrefix := connectors.branch, prefix+connectors.vertical
+		if i == len(node.Children)-1 {
+			treeChar, childPrefix = connectors.last, prefix+connectors.space
+		}
+
+		if !printTreeLine(prefix+treeChar+style(child), options) || !printGenericTree(child, childPrefix, style, connectors, options) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,196 @@
+package palantir
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTreeInsertCreatesIntermediateNodes(t *testing.T) {
+	tree := NewTree(0, "root")
+	if err := tree.Insert([]string{"services", "api", "handlers"}, 3); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	services := tree.Root().Child("services")
+	if services == nil || services.Data != 0 {
+		t.Fatalf("Expected an intermediate services node with zero data, got %+v", services)
+	}
+	handlers := services.Child("api").Child("handlers")
+	if handlers == nil || handlers.Data != 3 {
+		t.Fatalf("Expected handlers to hold 3, got %+v", handlers)
+	}
+
+	// Inserting at an intermediate node fills in its data
+	if err := tree.Insert([]string{"services"}, 1); err != nil {
+		t.Fatalf("Insert() at an intermediate node error = %v", err)
+	}
+	if services.Data != 1 || len(services.Children) != 1 {
+		t.Errorf("Expected services to keep its children and hold 1, got %+v", services)
+	}
+}
+
+func TestTreeInsertDuplicatePath(t *testing.T) {
+	tree := NewTree("", "root")
+	if err := tree.Insert([]string{"docs", "guide"}, "first"); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	err := tree.Insert([]string{"docs", "guide"}, "second")
+	if err == nil || !strings.Contains(err.Error(), `"docs/guide"`) {
+		t.Errorf("Expected a duplicate path error, got %v", err)
+	}
+	if data := tree.Root().Child("docs").Child("guide").Data; data != "first" {
+		t.Errorf("Expected the original data to be kept, got %q", data)
+	}
+	if len(tree.Root().Child("docs").Children) != 1 {
+		t.Errorf("Expected no duplicate node to be added")
+	}
+
+	for _, path := range [][]string{nil, {"docs", ""}} {
+		if err := tree.Insert(path, "x"); err == nil {
+			t.Errorf("Insert(%q) expected an error", path)
+		}
+	}
+}
+
+func TestTreeSortRecursive(t *testing.T) {
+	tree := NewTree(0, "root")
+	for _, path := range [][]string{{"b", "z"}, {"b", "y"}, {"a", "d", "2"}, {"a", "d", "1"}, {"c"}} {
+		if err := tree.Insert(path, 0); err != nil {
+			t.Fatalf("Insert(%q) error = %v", path, err)
+		}
+	}
+
+	tree.Sort(func(a, b *Node[int]) bool { return a.Name < b.Name })
+
+	var names []string
+	var walk func(node *Node[int])
+	walk = func(node *Node[int]) {
+		for _, child := range node.Children {
+			names = append(names, child.Name)
+			walk(child)
+		}
+	}
+	walk(tree.Root())
+
+	if got := strings.Join(names, " "); got != "a d 1 2 b y z c" {
+		t.Errorf("Sorted order = %q, want %q", got, "a d 1 2 b y z c")
+	}
+}
+
+func TestTreeRender(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false, TreeStyle: TreeStyleRounded}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	tree := NewTree(0, "root")
+	tree.Insert([]string{"a", "b"}, 1)
+	tree.Insert([]string{"c"}, 2)
+
+	output := captureOutput(func() {
+		tree.Render(nil, WithShowRoot())
+	})
+
+	expected := "root\n├── a\n│   ╰── b\n╰── c\n"
+	if output != expected {
+		t.Errorf("Render() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestTreeRenderLimits(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	tree := NewTree(0, "root")
+	tree.Insert([]string{"services", "api", "handlers"}, 1)
+	tree.Insert([]string{"services", "worker"}, 2)
+	tree.Insert([]string{"docs"}, 3)
+
+	tests := []struct {
+		name     string
+		opts     []BuildOption
+		expected string
+	}{
+		{"MaxDepth", []BuildOption{WithMaxDepth(1)}, "├── services\n│   └── … (3 nested items)\n└── docs\n"},
+		{"MaxLines", []BuildOption{WithMaxLines(2)}, "├── services\n│   ├── api\n... output truncated (2 lines shown)\n"},
+		{"PathFilter", []BuildOption{WithPathFilter("services.worker")}, "└── services\n    └── worker\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				tree.Render(nil, tt.opts...)
+			})
+			if output != tt.expected {
+				t.Errorf("Render() =\n%s\nwant:\n%s", output, tt.expected)
+			}
+		})
+	}
+
+	// Limits apply to a copy, leaving the tree intact
+	if len(tree.Root().Child("services").Children) != 2 {
+		t.Errorf("Expected Render to leave the tree's nodes in place")
+	}
+}
+
+func TestTreeRenderMaxNodesWarns(t *testing.T) {
+	var warnings bytes.Buffer
+	SetGlobalOutputHandler(NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(&warnings).Build()))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	tree := NewTree(0, "root")
+	for _, name := range []string{"a", "b", "c", "d"} {
+		tree.Insert([]string{name}, 0)
+	}
+
+	output := captureOutput(func() {
+		tree.Render(nil, WithMaxNodes(2))
+	})
+	if expected := "├── a\n└── b\n"; output != expected {
+		t.Errorf("Render() =\n%s\nwant:\n%s", output, expected)
+	}
+	if !strings.Contains(warnings.String(), "Tree truncated at 2 nodes, 2 more not shown") {
+		t.Errorf("Expected a truncation warning, got %q", warnings.String())
+	}
+}
+
+// service is the data held by the example tree's nodes
+type service struct {
+	Port    int
+	Healthy bool
+}
+
+func ExampleTree() {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	tree := NewTree(service{}, "cluster")
+	tree.Insert([]string{"frontend", "web"}, service{Port: 443, Healthy: true})
+	tree.Insert([]string{"backend", "api"}, service{Port: 8080, Healthy: true})
+	tree.Insert([]string{"backend", "worker"}, service{Healthy: false})
+	tree.Sort(func(a, b *Node[service]) bool { return a.Name < b.Name })
+
+	tree.Render(NodeStylerFunc[service](func(node *Node[service]) string {
+		if len(node.Children) > 0 {
+			return node.Name + "/"
+		}
+		status := "up"
+		if !node.Data.Healthy {
+			status = "down"
+		}
+		return fmt.Sprintf("%s :%d (%s)", node.Name, node.Data.Port, status)
+	}), WithShowRoot())
+
+	// Output:
+	// cluster/
+	// ├── backend/
+	// │   ├── api :8080 (up)
+	// │   └── worker :0 (down)
+	// └── frontend/
+	//     └── web :443 (up)
+}
@@ -0,0 +1,209 @@
+package palantir
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitStatus represents the git working tree status of a filesystem entry
+type GitStatus rune
+
+// Git status markers, modelled after the porcelain status codes
+const (
+	GitClean      GitStatus = 0
+	GitModified   GitStatus = 'M'
+	GitUntracked  GitStatus = '?'
+	GitAdded      GitStatus = 'A'
+	GitRenamed    GitStatus = 'R'
+	GitDeleted    GitStatus = 'D'
+	GitConflicted GitStatus = 'U'
+)
+
+// gitStatusColors maps each git status to the color used for its marker
+var gitStatusColors = map[GitStatus]string{
+	GitModified:   ColorYellow,
+	GitUntracked:  ColorGreen,
+	GitAdded:      ColorBlue,
+	GitRenamed:    ColorCyan,
+	GitDeleted:    ColorRed,
+	GitConflicted: ColorRed,
+}
+
+// gitStatusCodes maps each git status to the code `git status --short` shows for it
+var gitStatusCodes = map[GitStatus]string{
+	GitModified:   "M",
+	GitUntracked:  "??",
+	GitAdded:      "A",
+	GitRenamed:    "R",
+	GitDeleted:    "D",
+	GitConflicted: "UU",
+}
+
+// runGit executes a git command in dir and returns its standard output.
+// It is a variable so tests can replace the git invocation.
+var runGit = func(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	return cmd.Output()
+}
+
+// ShowHierarchyWithGitStatus displays the tree of repoPath with entries colored by their
+// git status, such as modified files in yellow and untracked ones in green, each followed
+// by its status code. Outside of a git repository the tree is shown with normal colors.
+func ShowHierarchyWithGitStatus(repoPath string, opts ...BuildOption) error {
+	err, _ := ShowHierarchy(repoPath, "", append(opts, WithGitColors())...)
+	return err
+}
+
+// applyGitStatus annotates the FileNodes of the tree with their git status.
+// Outside of a git repository, or when git is unavailable, the tree is left untouched.
+func applyGitStatus(root *TreeNode, basePath string) {
+	dir := basePath
+	if !getIsDir(root.Data) {
+		dir = filepath.Dir(basePath)
+	}
+
+	statuses, err := loadGitStatus(dir)
+	if err != nil || len(statuses) == 0 {
+		return
+	}
+
+	var annotate func(node *TreeNode, inherited GitStatus)
+	annotate = func(node *TreeNode, inherited GitStatus) {
+		fileNode, ok := node.Data.(FileNode)
+		if !ok {
+			return
+		}
+
+		status := inherited
+		if relPath, err := filepath.Rel(dir, fileNode.Path); err == nil {
+			if s, found := statuses[filepath.ToSlash(relPath)]; found {
+				status = s
+			}
+		}
+		fileNode.GitStatus = status
+		node.Data = fileNode
+
+		// Git reports untracked directories as a single entry, so their contents inherit the status
+		childInherited := GitClean
+		if status == GitUntracked {
+			childInherited = GitUntracked
+		}
+		for _, child := range node.Children {
+			annotate(child, childInherited)
+		}
+	}
+	annotate(root, GitClean)
+}
+
+// loadGitStatus returns the status of every changed path under dir, keyed by
+// its slash-separated path relative to dir
+func loadGitStatus(dir string) (map[string]GitStatus, error) {
+	prefixOutput, err := runGit(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %w", err)
+	}
+	prefix := strings.TrimSpace(string(prefixOutput))
+
+	statusOutput, err := runGit(dir, "status", "--porcelain=v1", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git status: %w", err)
+	}
+
+	statuses := make(map[string]GitStatus)
+	for path, status := range parseGitStatus(statusOutput) {
+		if !strings.HasPrefix(path, prefix) {
+			continue // Outside of the displayed directory
+		}
+		statuses[strings.TrimPrefix(path, prefix)] = status
+	}
+	return statuses, nil
+}
+
+// parseGitStatus parses `git status --porcelain=v1 -z` output into a map of
+// repository-relative paths to their status
+func parseGitStatus(output []byte) map[string]GitStatus {
+	statuses := make(map[string]GitStatus)
+
+	fields := bytes.Split(output, []byte{0})
+	for i := 0; i < len(fields); i++ {
+		field := string(fields[i])
+		if len(field) < 4 {
+			continue
+		}
+
+		x, y := field[0], field[1]
+		path := strings.TrimSuffix(field[3:], "/")
+		statuses[path] = gitStatusFromCodes(x, y)
+
+		// Renames and copies are followed by the original path, which no longer exists in the tree
+		if x == 'R' || x == 'C' {
+			i++
+		}
+	}
+
+	return statuses
+}
+
+// gitStatusFromCodes collapses the porcelain index (x) and worktree (y) codes into a single status
+func gitStatusFromCodes(x, y byte) GitStatus {
+	switch {
+	case x == '?' && y == '?':
+		return GitUntracked
+	case x == 'U' || y == 'U' || (x == 'A' && y == 'A') || (x == 'D' && y == 'D'):
+		return GitConflicted
+	case x == 'R' || x == 'C':
+		return GitRenamed
+	case x == 'A':
+		return GitAdded
+	case x == 'D' || y == 'D':
+		return GitDeleted
+	default:
+		return GitModified
+	}
+}
+
+// styleGitStatus returns the colored status rune for a node, or an empty string for clean entries
+func styleGitStatus(node *TreeNode) string {
+	fileNode, ok := node.Data.(FileNode)
+	if !ok || fileNode.GitStatus == GitClean {
+		return ""
+	}
+
+	marker := string(fileNode.GitStatus)
+	outputConfig := treeOutputConfig()
+	if !outputConfig.UseColors {
+		return marker
+	}
+	return fmt.Sprintf("%s%s%s", gitStatusColors[fileNode.GitStatus], marker, ColorReset)
+}
+
+// styleGitColoredName returns a node's name in the color of its git status, or an empty
+// string for clean entries
+func styleGitColoredName(node *TreeNode) string {
+	fileNode, ok := node.Data.(FileNode)
+	if !ok || fileNode.GitStatus == GitClean {
+		return ""
+	}
+
+	return styleTreeText(fileNode.Name, gitStatusColors[fileNode.GitStatus], false)
+}
+
+// styleGitStatusCode returns the colored porcelain code for a node's status, such as "??",
+// or an empty string for clean entries
+func styleGitStatusCode(node *TreeNode) string {
+	fileNode, ok := node.Data.(FileNode)
+	if !ok || fileNode.GitStatus == GitClean {
+		return ""
+	}
+
+	code := gitStatusCodes[fileNode.GitStatus]
+	outputConfig := treeOutputConfig()
+	if !outputConfig.UseColors {
+		return code
+	}
+	return fmt.Sprintf("%s%s%s", gitStatusColors[fileNode.GitStatus], code, ColorReset)
+}
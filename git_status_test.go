@@ -0,0 +1,256 @@
+package palantir
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubGit replaces the git invocation for the duration of a test
+func stubGit(t *testing.T, prefix string, status string, err error) *[][]string {
+	var calls [][]string
+	oldRunGit := runGit
+	runGit = func(dir string, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		if err != nil {
+			return nil, err
+		}
+		if args[0] == "rev-parse" {
+			return []byte(prefix + "\n"), nil
+		}
+		return []byte(status), nil
+	}
+	t.Cleanup(func() {
+		runGit = oldRunGit
+	})
+	return &calls
+}
+
+func createGitFixture(t *testing.T) string {
+	tempDir, err := os.MkdirTemp("", "palantir_git_status_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	for _, file := range []string{"clean.txt", "main.go", "new.txt", "renamed.md", "staged.go", "tmp/scratch.txt"} {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", fullPath, err)
+		}
+	}
+	return tempDir
+}
+
+func TestParseGitStatus(t *testing.T) {
+	output := " M main.go\x00?? new.txt\x00R  renamed.md\x00old.md\x00A  staged.go\x00?? tmp/\x00UU conflict.go\x00"
+
+	statuses := parseGitStatus([]byte(output))
+
+	expected := map[string]GitStatus{
+		"main.go":     GitModified,
+		"new.txt":     GitUntracked,
+		"renamed.md":  GitRenamed,
+		"staged.go":   GitAdded,
+		"tmp":         GitUntracked,
+		"conflict.go": GitConflicted,
+	}
+	if len(statuses) != len(expected) {
+		t.Errorf("Expected %d entries, got %d: %v", len(expected), len(statuses), statuses)
+	}
+	for path, status := range expected {
+		if statuses[path] != status {
+			t.Errorf("Expected %q to have status %q, got %q", path, status, statuses[path])
+		}
+	}
+	if _, found := statuses["old.md"]; found {
+		t.Error("Expected the original path of a rename to be skipped")
+	}
+}
+
+func TestShowHierarchyWithGitStatus(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	tempDir := createGitFixture(t)
+	calls := stubGit(t, "", " M main.go\x00?? new.txt\x00R  renamed.md\x00old.md\x00A  staged.go\x00?? tmp/\x00", nil)
+
+	output := captureOutput(func() {
+		if err, _ := ShowHierarchy(tempDir, "", WithGitStatus()); err != nil {
+			t.Errorf("ShowHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "├── ? tmp\n" +
+		"│   └── ? scratch.txt\n" +
+		"├── clean.txt\n" +
+		"├── M main.go\n" +
+		"├── ? new.txt\n" +
+		"├── R renamed.md\n" +
+		"└── A staged.go\n"
+	if output != expected {
+		t.Errorf("ShowHierarchy() output = %q, want %q", output, expected)
+	}
+
+	statusCalls := 0
+	for _, call := range *calls {
+		if call[0] == "status" {
+			statusCalls++
+		}
+	}
+	if statusCalls != 1 {
+		t.Errorf("Expected git status to run exactly once, ran %d times", statusCalls)
+	}
+}
+
+func TestShowHierarchyWithGitStatusColors(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+	tempDir := createGitFixture(t)
+	stubGit(t, "", " M main.go\x00?? new.txt\x00", nil)
+
+	output := captureOutput(func() {
+		ShowHierarchy(tempDir, "", WithGitStatus())
+	})
+
+	if !strings.Contains(output, ColorYellow+"M"+ColorReset) {
+		t.Errorf("Expected a yellow modified marker, got %q", output)
+	}
+	if !strings.Contains(output, ColorGreen+"?"+ColorReset) {
+		t.Errorf("Expected a green untracked marker, got %q", output)
+	}
+}
+
+func TestShowHierarchyWithGitStatusSubdirectory(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	tempDir := createGitFixture(t)
+	// The displayed directory is "project/" inside the repository; entries elsewhere must be ignored
+	stubGit(t, "project/", " M project/main.go\x00 M other/clean.txt\x00", nil)
+
+	output := captureOutput(func() {
+		ShowHierarchy(tempDir, "", WithGitStatus())
+	})
+
+	if !strings.Contains(output, "M main.go") {
+		t.Errorf("Expected main.go to be marked modified, got %q", output)
+	}
+	if strings.Contains(output, "M clean.txt") {
+		t.Errorf("Expected entries outside the directory to be ignored, got %q", output)
+	}
+}
+
+func TestShowHierarchyWithGitStatusOutsideRepository(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	tempDir := createGitFixture(t)
+	stubGit(t, "", "", errors.New("fatal: not a git repository"))
+
+	withStatus := captureOutput(func() {
+		if err, _ := ShowHierarchy(tempDir, "", WithGitStatus()); err != nil {
+			t.Errorf("ShowHierarchy() error = %v", err)
+		}
+	})
+	withoutStatus := captureOutput(func() {
+		ShowHierarchy(tempDir, "")
+	})
+
+	if withStatus != withoutStatus {
+		t.Errorf("Expected unchanged output outside a repository, got %q, want %q", withStatus, withoutStatus)
+	}
+}
+
+// createGitRepository creates a repository with a committed file that was then modified,
+// an untracked file and a staged file
+func createGitRepository(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoDir := createFileFixture(t, []string{"tracked.txt", "untracked.txt", "staged.go"})
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	git("init", "-q")
+	git("add", "tracked.txt")
+	git("commit", "-q", "-m", "initial")
+	git("add", "staged.go")
+	if err := os.WriteFile(filepath.Join(repoDir, "tracked.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to modify tracked.txt: %v", err)
+	}
+	return repoDir
+}
+
+func TestShowHierarchyWithGitStatusRepository(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	repoDir := createGitRepository(t)
+
+	output := captureOutput(func() {
+		if err := ShowHierarchyWithGitStatus(repoDir); err != nil {
+			t.Errorf("ShowHierarchyWithGitStatus() error = %v", err)
+		}
+	})
+
+	expected := "├── staged.go A\n" +
+		"├── tracked.txt M\n" +
+		"└── untracked.txt ??\n"
+	if output != expected {
+		t.Errorf("ShowHierarchyWithGitStatus() output = %q, want %q", output, expected)
+	}
+}
+
+func TestShowHierarchyWithGitStatusRepositoryColors(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+	repoDir := createGitRepository(t)
+
+	output := captureOutput(func() {
+		ShowHierarchyWithGitStatus(repoDir)
+	})
+
+	for _, colored := range []string{
+		ColorYellow + "tracked.txt" + ColorReset + " " + ColorYellow + "M" + ColorReset,
+		ColorGreen + "untracked.txt" + ColorReset + " " + ColorGreen + "??" + ColorReset,
+		ColorBlue + "staged.go" + ColorReset + " " + ColorBlue + "A" + ColorReset,
+	} {
+		if !strings.Contains(output, colored) {
+			t.Errorf("Expected output to contain %q, got %q", colored, output)
+		}
+	}
+}
+
+func TestShowHierarchyWithGitStatusNotARepository(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+	tempDir := createGitFixture(t)
+	stubGit(t, "", "", errors.New("fatal: not a git repository"))
+
+	withStatus := captureOutput(func() {
+		if err := ShowHierarchyWithGitStatus(tempDir); err != nil {
+			t.Errorf("ShowHierarchyWithGitStatus() error = %v", err)
+		}
+	})
+	withoutStatus := captureOutput(func() {
+		ShowHierarchy(tempDir, "")
+	})
+
+	if withStatus != withoutStatus {
+		t.Errorf("Expected normal coloring outside a repository, got %q, want %q", withStatus, withoutStatus)
+	}
+}
@@ -0,0 +1,170 @@
+package palantir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// CIMode selects whether output is written as CI workflow commands
+type CIMode int
+
+const (
+	// CIModeNone always writes terminal output
+	CIModeNone CIMode = iota
+	// CIModeAuto writes GitHub Actions workflow commands when the GITHUB_ACTIONS
+	// environment variable is "true", as it is on Actions runners
+	CIModeAuto
+	// CIModeGitHubActions always writes GitHub Actions workflow commands: headers open
+	// collapsible "::group::" sections, and errors and warnings become "::error::" and
+	// "::warning::" annotations
+	CIModeGitHubActions
+)
+
+// SourceError is an error located in a file, reported by PrintErrors as an annotation
+// on that file and line when running under GitHub Actions
+type SourceError struct {
+	File   string
+	Line   int // Starting at 1, or 0 when unknown
+	Column int // Starting at 1, or 0 when unknown
+	Err    error
+}
+
+// Error returns the location and message, such as "config.yaml:3:5: unknown key"
+func (e *SourceError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location += fmt.Sprintf(":%d", e.Line)
+		if e.Column > 0 {
+			location += fmt.Sprintf(":%d", e.Column)
+		}
+	}
+	return location + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// groupState tracks whether a "::group::" is open, so the next header can close it
+type groupState struct {
+	mu   sync.Mutex
+	open bool
+}
+
+// githubActions reports whether output is written as GitHub Actions workflow commands
+func (c *OutputConfig) githubActions() bool {
+	switch c.CIMode {
+	case CIModeGitHubActions:
+		return true
+	case CIModeAuto:
+		return os.Getenv("GITHUB_ACTIONS") == "true"
+	default:
+		return false
+	}
+}
+
+// escapeCommandData escapes the message of a workflow command
+func escapeCommandData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeCommandProperty escapes a property value of a workflow command, such as a file name
+func escapeCommandProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// workflowCommand formats a workflow command such as "::error file=a.go,line=3::message"
+func workflowCommand(command string, properties []string, message string) string {
+	if len(properties) > 0 {
+		command += " " + strings.Join(properties, ",")
+	}
+	return fmt.Sprintf("::%s::%s\n", command, escapeCommandData(message))
+}
+
+// annotationProperties returns the file, line and column properties of an error located
+// by a SourceError
+func annotationProperties(err error) []string {
+	var sourceErr *SourceError
+	if !errors.As(err, &sourceErr) || sourceErr.File == "" {
+		return nil
+	}
+
+	properties := []string{"file=" + escapeCommandProperty(sourceErr.File)}
+	if sourceErr.Line > 0 {
+		properties = append(properties, fmt.Sprintf("line=%d", sourceErr.Line))
+	}
+	if sourceErr.Column > 0 {
+		properties = append(properties, fmt.Sprintf("col=%d", sourceErr.Column))
+	}
+	return properties
+}
+
+// annotationMessage returns the message of an annotation for err, leaving out the
+// location of a SourceError, which the annotation carries as properties
+func annotationMessage(err error) string {
+	var sourceErr *SourceError
+	if errors.As(err, &sourceErr) && sourceErr == err && sourceErr.File != "" && sourceErr.Err != nil {
+		return sourceErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// commandPrefix returns the handler's prefixes as text leading a command's message, since
+// workflow commands must start their line
+func (oh *outputHandler) commandPrefix() string {
+	var prefix strings.Builder
+	for _, p := range oh.prefixes {
+		prefix.WriteString("[" + p + "] ")
+	}
+	return prefix.String()
+}
+
+// writeCommand writes a workflow command to the level's writer without line prefixes
+func (oh *outputHandler) writeCommand(level OutputLevel, command string) {
+	defer recoverPanic(oh.config)
+	fmt.Fprint(oh.writerFor(level), command)
+}
+
+// endGroup writes "::endgroup::" when a header left a group open
+func (oh *outputHandler) endGroup() {
+	if oh.groups == nil {
+		return
+	}
+	oh.groups.mu.Lock()
+	defer oh.groups.mu.Unlock()
+	if oh.groups.open {
+		oh.writeCommand(LevelHeader, "::endgroup::\n")
+		oh.groups.open = false
+	}
+}
+
+// printWorkflowCommand writes a message of the given level as a workflow command when
+// the level has one, reporting whether it did. Headers close any open group and open a
+// new one named after them.
+func (oh *outputHandler) printWorkflowCommand(level OutputLevel, message string) bool {
+	message = oh.commandPrefix() + message
+	switch level {
+	case LevelError:
+		oh.writeCommand(level, workflowCommand("error", nil, message))
+	case LevelWarning:
+		oh.writeCommand(level, workflowCommand("warning", nil, message))
+	case LevelHeader:
+		command := workflowCommand("group", nil, strings.TrimSpace(message))
+		if oh.groups != nil {
+			oh.groups.mu.Lock()
+			defer oh.groups.mu.Unlock()
+			if oh.groups.open {
+				command = "::endgroup::\n" + command
+			}
+			oh.groups.open = true
+		}
+		oh.writeCommand(level, command)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package palantir
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGitHubActionsCommands(t *testing.T) {
+	handler := NewOutputHandler(&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true, CIMode: CIModeGitHubActions})
+
+	output := captureOutput(func() {
+		handler.PrintHeader("Build")
+		handler.PrintError("compile failed: %s", "100% broken\r\nsee log")
+		handler.PrintWarning("deprecated %s", "flag")
+		handler.PrintHeaderWithSubtitle("Test", "unit and integration")
+		handler.WithPrefix("api").PrintError("down")
+	})
+
+	expected := "::group::Build\n" +
+		"::error::compile failed: 100%25 broken%0D%0Asee log\n" +
+		"::warning::deprecated flag\n" +
+		"::endgroup::\n::group::Test\n" +
+		"unit and integration\n" +
+		"::error::[api] down\n"
+	if output != expected {
+		t.Errorf("Workflow commands = %q, want %q", output, expected)
+	}
+}
+
+func TestGitHubActionsCloseEndsGroup(t *testing.T) {
+	handler := NewOutputHandler(&OutputConfig{CIMode: CIModeGitHubActions})
+
+	output := captureOutput(func() {
+		handler.PrintHeader("Build")
+		handler.WithPrefix("api").PrintWarning("slow")
+		if err := handler.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+		handler.Close() // The group is only ended once
+	})
+	if expected := "::group::Build\n::warning::[api] slow\n::endgroup::\n"; output != expected {
+		t.Errorf("Workflow commands = %q, want %q", output, expected)
+	}
+}
+
+func TestGitHubActionsOtherLevels(t *testing.T) {
+	setupSupportedTerminal(t)
+	handler := NewOutputHandler(&OutputConfig{UseFormatting: true, CIMode: CIModeGitHubActions})
+
+	// Levels without a workflow command keep their usual formatting
+	output := captureOutput(func() {
+		handler.PrintSuccess("done")
+		handler.PrintStage("deploying")
+	})
+	if output != "[SUCCESS] done\n[STAGE] deploying\n" {
+		t.Errorf("Expected regular output for other levels, got %q", output)
+	}
+
+	quiet := NewOutputHandler(&OutputConfig{QuietMode: true, CIMode: CIModeGitHubActions})
+	output = captureOutput(func() {
+		quiet.PrintHeader("Build")
+		quiet.PrintWarning("careful")
+	})
+	if output != "::warning::careful\n" {
+		t.Errorf("Expected quiet mode to drop the group, got %q", output)
+	}
+}
+
+func TestGitHubActionsPrintErrors(t *testing.T) {
+	handler := NewOutputHandler(&OutputConfig{CIMode: CIModeGitHubActions})
+
+	output := captureOutput(func() {
+		handler.PrintErrors(
+			&SourceError{File: "config,v1.yaml", Line: 3, Column: 5, Err: errors.New("unknown key")},
+			fmt.Errorf("loading: %w", &SourceError{File: "main.go", Line: 10, Err: errors.New("bad")}),
+			errors.New("timed out"),
+		)
+	})
+
+	expected := "::error file=config%2Cv1.yaml,line=3,col=5::unknown key\n" +
+		"::error file=main.go,line=10::loading: main.go:10: bad\n" +
+		"::error::timed out\n"
+	if output != expected {
+		t.Errorf("PrintErrors() = %q, want %q", output, expected)
+	}
+}
+
+func TestCIModeAuto(t *testing.T) {
+	handler := NewOutputHandler(&OutputConfig{CIMode: CIModeAuto})
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	if output := captureOutput(func() { handler.PrintWarning("careful") }); output != "::warning::careful\n" {
+		t.Errorf("Expected a workflow command on Actions, got %q", output)
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "")
+	if output := captureOutput(func() { handler.PrintWarning("careful") }); output != "[WARNING] careful\n" {
+		t.Errorf("Expected terminal output elsewhere, got %q", output)
+	}
+
+	if NewDefaultOutputHandler().Config().CIMode != CIModeAuto {
+		t.Error("Expected the default configuration to detect Actions")
+	}
+}
+
+func TestSourceErrorMessage(t *testing.T) {
+	err := &SourceError{File: "a.yaml", Line: 2, Err: errors.New("bad indent")}
+	if err.Error() != "a.yaml:2: bad indent" || !errors.Is(err, err.Err) {
+		t.Errorf("Unexpected SourceError %q", err.Error())
+	}
+	if escaped := escapeCommandProperty("C:\\a,b%\n"); escaped != "C%3A\\a%2Cb%25%0A" {
+		t.Errorf("escapeCommandProperty() = %q", escaped)
+	}
+}
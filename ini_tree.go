@@ -0,0 +1,228 @@
+package palantir
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ParseINIToTree converts INI content, including git config files, to a TreeNode structure
+// built from YAMLNodes. Sections become object nodes, git-style subsections such as
+// [remote "origin"] nest below their section and keys become scalars. Keys repeated
+// within a section become an array node. Comments start with ";" or "#".
+func ParseINIToTree(iniContent []byte, opts ...BuildOption) (*TreeNode, error) {
+	builder, err := newYAMLTreeBuilder(newBuildOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	root := &TreeNode{
+		Name:     "root",
+		Data:     YAMLNode{Name: "root", IsDir: true, NodeType: "object"},
+		Children: nil,
+	}
+	parser := &iniParser{builder: builder, keys: make(map[*TreeNode]map[string]*TreeNode)}
+	section := root
+
+	scanner := bufio.NewScanner(bytes.NewReader(iniContent))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			path, err := parseINISectionHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse INI: line %d: %w", lineNumber, err)
+			}
+			section = parser.section(root, path)
+			continue
+		}
+
+		key, rawValue, hasValue := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("failed to parse INI: line %d: missing key", lineNumber)
+		}
+
+		// A key without a value is a boolean flag, as in git config
+		var value interface{} = true
+		if hasValue {
+			parsed, err := parseINIValue(strings.TrimSpace(rawValue))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse INI: line %d: %w", lineNumber, err)
+			}
+			value = parsed
+		}
+		parser.addKey(section, key, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse INI: %w", err)
+	}
+
+	setTreeContainerValues(root)
+	return root, nil
+}
+
+// iniParser adds sections and keys to an INI tree
+type iniParser struct {
+	builder *yamlTreeBuilder
+	keys    map[*TreeNode]map[string]*TreeNode // Key nodes of each section by name
+}
+
+// section finds or creates the section node for a header path such as ["remote", "origin"]
+func (p *iniParser) section(root *TreeNode, path []string) *TreeNode {
+	current := root
+	for _, name := range path {
+		var next *TreeNode
+		for _, child := range current.Children {
+			if child.Name == name && p.keys[current][name] != child {
+				next = child
+				break
+			}
+		}
+
+		if next == nil {
+			redacted := p.builder.isRedacted(name, current.Data.(YAMLNode).Redacted)
+			next = &TreeNode{
+				Name:     name,
+				Data:     YAMLNode{Name: name, IsDir: true, NodeType: "object", Redacted: redacted},
+				Children: nil,
+			}
+			current.Children = append(current.Children, next)
+		}
+		current = next
+	}
+	return current
+}
+
+// addKey adds a key to a section, turning repeated keys into an array node
+func (p *iniParser) addKey(section *TreeNode, key string, value interface{}) {
+	redacted := p.builder.isRedacted(key, section.Data.(YAMLNode).Redacted)
+
+	if p.keys[section] == nil {
+		p.keys[section] = make(map[string]*TreeNode)
+	}
+	existing, found := p.keys[section][key]
+	if !found {
+		node := &TreeNode{
+			Name:     key,
+			Data:     newValueYAMLNode(key, value, redacted),
+			Children: nil,
+		}
+		section.Children = append(section.Children, node)
+		p.keys[section][key] = node
+		return
+	}
+
+	// Convert the first occurrence into an array holding every value
+	if existingData := existing.Data.(YAMLNode); existingData.NodeType == "scalar" {
+		existing.Children = []*TreeNode{newINIArrayItem(existingData.Value, 0, redacted)}
+		existing.Data = YAMLNode{Name: key, IsDir: true, NodeType: "array", Redacted: redacted}
+	}
+	existing.Children = append(existing.Children, newINIArrayItem(value, len(existing.Children), redacted))
+}
+
+// newINIArrayItem creates an item of a repeated key's array node
+func newINIArrayItem(value interface{}, index int, redacted bool) *TreeNode {
+	name := yamlItemName(value, index)
+	if redacted {
+		name = RedactionMask // Items are named after their value
+	}
+	itemData := newValueYAMLNode(name, value, redacted)
+	itemData.Item, itemData.Index = true, index
+	return &TreeNode{
+		Name:     name,
+		Data:     itemData,
+		Children: nil,
+	}
+}
+
+// parseINISectionHeader splits a header such as [core], [branch.main] or
+// [remote "origin"] into its section path
+func parseINISectionHeader(line string) ([]string, error) {
+	if !strings.HasSuffix(line, "]") {
+		return nil, fmt.Errorf("unterminated section header %s", line)
+	}
+	header := strings.TrimSpace(line[1 : len(line)-1])
+
+	name, subsection, hasSubsection := strings.Cut(header, " ")
+	if name == "" {
+		return nil, fmt.Errorf("empty section name")
+	}
+	path := strings.Split(name, ".")
+
+	if hasSubsection {
+		subsection = strings.TrimSpace(subsection)
+		if len(subsection) < 2 || !strings.HasPrefix(subsection, `"`) || !strings.HasSuffix(subsection, `"`) {
+			return nil, fmt.Errorf("subsection must be quoted in %s", line)
+		}
+		path = append(path, strings.ReplaceAll(subsection[1:len(subsection)-1], `\"`, `"`))
+	}
+	return path, nil
+}
+
+// parseINIValue strips trailing comments and unquotes a value. Comment characters
+// inside double quotes are kept, and \", \\, \n and \t are unescaped.
+func parseINIValue(raw string) (string, error) {
+	var value strings.Builder
+	inQuotes := false
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == '\\' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			default:
+				value.WriteByte(raw[i])
+			}
+		case (c == ';' || c == '#') && !inQuotes:
+			return strings.TrimSpace(value.String()), nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+	if inQuotes {
+		return "", fmt.Errorf("unterminated quoted value")
+	}
+	return strings.TrimSpace(value.String()), nil
+}
+
+// ShowINIHierarchy displays INI content as a tree structure. Sections and keys keep their
+// document order unless WithKeyOrder(KeyOrderAlphabetical) is given.
+func ShowINIHierarchy(iniContent []byte, opts ...BuildOption) error {
+	root, err := ParseINIToTree(iniContent, opts...)
+	if err != nil {
+		return err
+	}
+
+	options := newBuildOptions(opts)
+	if options.KeyOrder == KeyOrderAlphabetical {
+		sortTree(root)
+	}
+	renderLimitedTree(root, options)
+	return nil
+}
+
+// ShowINIHierarchyFromFile reads and displays an INI file as a tree structure, up to the WithMaxReadSize limit.
+// A path of "-" reads standard input.
+func ShowINIHierarchyFromFile(filePath string, opts ...BuildOption) error {
+	file, err := openInput(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read INI file: %w", err)
+	}
+	defer file.Close()
+
+	content, err := readLimited(file, newBuildOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to read INI: %w", err)
+	}
+	return ShowINIHierarchy(content, opts...)
+}
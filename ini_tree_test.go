@@ -0,0 +1,95 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+var gitConfig = []byte(`# Global git configuration
+[user]
+	name = Jane Doe
+	email = jane@example.com ; work address
+[core]
+	editor = "vim -c 'set ft=gitcommit'"
+	bare
+[remote "origin"]
+	url = git@github.com:example/repo.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+	fetch = +refs/tags/*:refs/tags/*
+[credential]
+	helper = store
+	token = "ghp_#notacomment"
+[remote "upstream"]
+	url = git@github.com:upstream/repo.git
+`)
+
+func TestParseINIToTree(t *testing.T) {
+	root, err := ParseINIToTree(gitConfig)
+	if err != nil {
+		t.Fatalf("ParseINIToTree() error = %v", err)
+	}
+
+	if names := childNames(root); strings.Join(names, ",") != "user,core,remote,credential" {
+		t.Errorf("Expected sections in document order, got %v", names)
+	}
+
+	remote := findChild(root, "remote")
+	if names := childNames(remote); strings.Join(names, ",") != "origin,upstream" {
+		t.Errorf("Expected subsections below their section, got %v", names)
+	}
+
+	fetch := findChild(findChild(remote, "origin"), "fetch")
+	if data := fetch.Data.(YAMLNode); !data.IsDir || !isSequenceNode(fetch.Data) {
+		t.Errorf("Expected duplicate keys to become an array node, got %+v", data)
+	}
+	if len(fetch.Children) != 2 || fetch.Children[1].Name != "+refs/tags/*:refs/tags/*" {
+		t.Errorf("Expected both fetch values in order, got %v", childNames(fetch))
+	}
+
+	tests := []struct {
+		path     []string
+		expected interface{}
+	}{
+		{[]string{"user", "email"}, "jane@example.com"},
+		{[]string{"core", "editor"}, "vim -c 'set ft=gitcommit'"},
+		{[]string{"core", "bare"}, true},
+		{[]string{"credential", "token"}, "ghp_#notacomment"},
+	}
+	for _, tt := range tests {
+		node := root
+		for _, part := range tt.path {
+			node = findChild(node, part)
+		}
+		if value := node.Data.(YAMLNode).Value; value != tt.expected {
+			t.Errorf("%s = %v, want %v", strings.Join(tt.path, "."), value, tt.expected)
+		}
+	}
+}
+
+func TestShowINIHierarchyValues(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		err := ShowINIHierarchy(gitConfig, WithShowValues(), WithRedactKeys(DefaultSecretPatterns...))
+		if err != nil {
+			t.Errorf("ShowINIHierarchy() error = %v", err)
+		}
+	})
+
+	assertGolden(t, "ini_values", output)
+}
+
+func TestParseINIToTreeInvalid(t *testing.T) {
+	tests := []string{
+		"[unterminated\n",
+		"[remote origin]\n",
+		"= value\n",
+		"key = \"unterminated\n",
+	}
+
+	for _, content := range tests {
+		if _, err := ParseINIToTree([]byte(content)); err == nil || !strings.Contains(err.Error(), "failed to parse INI") {
+			t.Errorf("Expected a parse error for %q, got %v", content, err)
+		}
+	}
+}
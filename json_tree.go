@@ -0,0 +1,155 @@
+package palantir
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ParseJSONToTree converts JSON content to a TreeNode structure built from YAMLNodes, so
+// JSON trees share the YAML styling. Object keys keep their document order and numbers
+// are kept as json.Number to preserve integer fidelity.
+func ParseJSONToTree(jsonContent []byte, opts ...BuildOption) (*TreeNode, error) {
+	base, err := newYAMLTreeBuilder(newBuildOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonContent))
+	decoder.UseNumber()
+	builder := &jsonTreeBuilder{yamlTreeBuilder: base, decoder: decoder}
+
+	root := &TreeNode{
+		Name:     "root",
+		Data:     YAMLNode{Name: "root", IsDir: true, NodeType: "object"},
+		Children: nil,
+	}
+
+	value, err := builder.build(root, 0, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("failed to parse JSON: unexpected data after top-level value")
+	}
+
+	if isJSONContainer(value) {
+		setYAMLContainerValue(root, value)
+	} else {
+		root.Data = newValueYAMLNode("root", value, false)
+	}
+	return root, nil
+}
+
+// jsonTreeBuilder builds TreeNodes from a stream of JSON tokens
+type jsonTreeBuilder struct {
+	*yamlTreeBuilder // Shares options, the depth limit and redaction with YAML trees
+	decoder          *json.Decoder
+}
+
+// build decodes the next JSON value, adding its members to node when it is an object or
+// an array, and returns its plain Go value
+func (b *jsonTreeBuilder) build(node *TreeNode, depth int, redacted bool) (interface{}, error) {
+	if depth > b.maxDepth {
+		return nil, fmt.Errorf("JSON nesting exceeds maximum depth of %d", b.maxDepth)
+	}
+
+	token, err := b.decoder.Token()
+	if err == io.EOF {
+		return nil, errors.New("unexpected end of JSON input")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		// Handle objects
+		value := make(map[string]interface{})
+		for b.decoder.More() {
+			keyToken, err := b.decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyToken.(string)
+
+			childRedacted := b.isRedacted(key, redacted)
+			child := &TreeNode{Name: key, Children: nil}
+			childValue, err := b.build(child, depth+1, childRedacted)
+			if err != nil {
+				return nil, err
+			}
+
+			child.Data = newValueYAMLNode(key, childValue, childRedacted)
+			node.Children = append(node.Children, child)
+			value[key] = childValue
+		}
+		_, err = b.decoder.Token() // Closing brace
+		return value, err
+	case json.Delim('['):
+		// Handle arrays
+		value := make([]interface{}, 0)
+		for i := 0; b.decoder.More(); i++ {
+			child := &TreeNode{Children: nil}
+			itemValue, err := b.build(child, depth+1, redacted)
+			if err != nil {
+				return nil, err
+			}
+
+			itemName := yamlItemName(itemValue, i)
+			if redacted && !isJSONContainer(itemValue) {
+				itemName = RedactionMask // Scalar items are named after their value
+			}
+			itemData := newValueYAMLNode(itemName, itemValue, redacted)
+			itemData.Item, itemData.Index = true, i
+			child.Name = itemName
+			child.Data = itemData
+			node.Children = append(node.Children, child)
+			value = append(value, itemValue)
+		}
+		_, err = b.decoder.Token() // Closing bracket
+		return value, err
+	default:
+		// Handle scalar values: string, json.Number, bool or nil
+		return token, nil
+	}
+}
+
+// isJSONContainer reports whether a decoded JSON value is an object or an array
+func isJSONContainer(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// ShowJSONHierarchy displays JSON content as a tree structure. Keys keep their
+// document order unless WithKeyOrder(KeyOrderAlphabetical) is given.
+func ShowJSONHierarchy(jsonContent []byte, opts ...BuildOption) error {
+	root, err := ParseJSONToTree(jsonContent, opts...)
+	if err != nil {
+		return err
+	}
+
+	options := newBuildOptions(opts)
+	if options.KeyOrder == KeyOrderAlphabetical {
+		sortTree(root)
+	}
+	renderLimitedTree(root, options)
+	return nil
+}
+
+// ShowJSONHierarchyFromFile reads and displays a JSON file as a tree structure, up to the WithMaxReadSize limit.
+// A path of "-" reads standard input.
+func ShowJSONHierarchyFromFile(filePath string, opts ...BuildOption) error {
+	file, err := openInput(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read JSON file: %w", err)
+	}
+	defer file.Close()
+	return ShowJSONHierarchyFromReader(file, opts...)
+}
@@ -0,0 +1,132 @@
+package palantir
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var valuesJSON = []byte(`{
+  "server": {
+    "port": 8080,
+    "host": "localhost",
+    "ratio": 0.75,
+    "debug": true,
+    "owner": null,
+    "features": ["authentication", "logging"]
+  },
+  "id": 9007199254740993,
+  "database": {"name": "app"}
+}`)
+
+func TestParseJSONToTree(t *testing.T) {
+	root, err := ParseJSONToTree(valuesJSON)
+	if err != nil {
+		t.Fatalf("ParseJSONToTree() error = %v", err)
+	}
+
+	if names := childNames(root); strings.Join(names, ",") != "server,id,database" {
+		t.Errorf("Expected document key order, got %v", names)
+	}
+
+	server := findChild(root, "server")
+	if names := childNames(server); strings.Join(names, ",") != "port,host,ratio,debug,owner,features" {
+		t.Errorf("Expected nested document key order, got %v", names)
+	}
+
+	features := findChild(server, "features")
+	if data := features.Data.(YAMLNode); !data.IsDir || data.NodeType != "array" {
+		t.Errorf("Expected features to be an array container, got %+v", data)
+	}
+	if names := childNames(features); strings.Join(names, ",") != "authentication,logging" {
+		t.Errorf("Expected array items in order, got %v", names)
+	}
+
+	id := findChild(root, "id").Data.(YAMLNode)
+	if id.Value != json.Number("9007199254740993") {
+		t.Errorf("Expected integer fidelity to be preserved, got %v", id.Value)
+	}
+}
+
+func TestParseJSONToTreeTopLevelValues(t *testing.T) {
+	root, err := ParseJSONToTree([]byte(`[{"name": "a"}, 2, "three"]`))
+	if err != nil {
+		t.Fatalf("ParseJSONToTree() error = %v", err)
+	}
+	if names := childNames(root); strings.Join(names, ",") != "[0],2,three" {
+		t.Errorf("Expected top-level array items, got %v", names)
+	}
+	if !isSequenceNode(root.Data) {
+		t.Errorf("Expected the root to hold the array, got %+v", root.Data)
+	}
+
+	root, err = ParseJSONToTree([]byte(`"just a string"`))
+	if err != nil {
+		t.Fatalf("ParseJSONToTree() error = %v", err)
+	}
+	if data := root.Data.(YAMLNode); data.NodeType != "scalar" || data.Value != "just a string" {
+		t.Errorf("Expected a scalar root, got %+v", data)
+	}
+}
+
+func TestParseJSONToTreeInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"Syntax error", `{"a": }`},
+		{"Unterminated object", `{"a": 1`},
+		{"Trailing data", `{"a": 1} {"b": 2}`},
+		{"Empty", ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSONToTree([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), "failed to parse JSON") {
+				t.Errorf("Expected a parse error, got %v", err)
+			}
+		})
+	}
+
+	_, err := ParseJSONToTree([]byte(strings.Repeat("[", 20)+strings.Repeat("]", 20)), WithMaxParseDepth(5))
+	if err == nil || !strings.Contains(err.Error(), "maximum depth of 5") {
+		t.Errorf("Expected a maximum depth error, got %v", err)
+	}
+}
+
+func TestShowJSONHierarchyValues(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowJSONHierarchy(valuesJSON, WithShowValues()); err != nil {
+			t.Errorf("ShowJSONHierarchy() error = %v", err)
+		}
+	})
+
+	assertGolden(t, "json_values", output)
+}
+
+func TestShowJSONHierarchyFromFile(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, valuesJSON, 0644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	output := captureOutput(func() {
+		if err := ShowJSONHierarchyFromFile(path, WithKeyOrder(KeyOrderAlphabetical)); err != nil {
+			t.Errorf("ShowJSONHierarchyFromFile() error = %v", err)
+		}
+	})
+	if !strings.HasPrefix(output, "├── database") {
+		t.Errorf("Expected alphabetical key order, got:\n%s", output)
+	}
+
+	if err := ShowJSONHierarchyFromFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
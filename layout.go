@@ -0,0 +1,102 @@
+package palantir
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ansiPattern matches ANSI escape sequences such as colors and cursor movements
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// stripANSI removes ANSI escape sequences from s
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// displayWidth returns the number of terminal columns s occupies, ignoring ANSI
+// escape sequences and counting wide characters such as emoji as two columns
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range stripANSI(s) {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth returns the number of terminal columns a rune occupies
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || r == '\r' || r == '\n':
+		return 0
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == '\u200d' || (r >= '\ufe00' && r <= '\ufe0f'):
+		return 0 // Combining marks, zero-width joiners and variation selectors
+	case isWideRune(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// isWideRune reports whether r is rendered across two terminal columns
+func isWideRune(r rune) bool {
+	switch {
+	case r >= 0x1100 && r <= 0x115F, // Hangul Jamo
+		r >= 0x2E80 && r <= 0xA4CF,   // CJK radicals through Yi
+		r >= 0xAC00 && r <= 0xD7A3,   // Hangul syllables
+		r >= 0xF900 && r <= 0xFAFF,   // CJK compatibility ideographs
+		r >= 0xFE30 && r <= 0xFE4F,   // CJK compatibility forms
+		r >= 0xFF00 && r <= 0xFF60,   // Fullwidth forms
+		r >= 0xFFE0 && r <= 0xFFE6,   // Fullwidth signs
+		r >= 0x1F300 && r <= 0x1F64F, // Pictographs and emoticons
+		r >= 0x1F680 && r <= 0x1F6FF, // Transport and map symbols
+		r >= 0x1F900 && r <= 0x1F9FF, // Supplemental symbols and pictographs
+		r >= 0x20000 && r <= 0x3FFFD, // CJK extensions
+		r == 0x2705, r == 0x274C,     // ✅ ❌
+		r == 0x2728, r == 0x23F3: // ✨ ⏳
+		return true
+	}
+	return false
+}
+
+// terminalWidth returns the width used to wrap output: OutputConfig.WrapWidth when set,
+// otherwise the COLUMNS environment variable. Zero means lines never wrap.
+func (oh *outputHandler) terminalWidth() int {
+	if oh.config.WrapWidth > 0 {
+		return oh.config.WrapWidth
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return 0
+}
+
+// CountLines returns how many terminal rows the formatted message would occupy,
+// accounting for line wrapping at the terminal width. This is needed to move the
+// cursor back up when redrawing output in place.
+func (oh *outputHandler) CountLines(level OutputLevel, message string) int {
+	formatted := oh.FormatMessage(level, message)
+	if formatted == "" {
+		return 0
+	}
+	return countRows(formatted, oh.terminalWidth())
+}
+
+// countRows counts the terminal rows needed to display text at the given width.
+// A trailing newline terminates the last row rather than starting a new one.
+func countRows(text string, width int) int {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	rows := 0
+	for _, line := range lines {
+		lineWidth := displayWidth(line)
+		if width <= 0 || lineWidth <= width {
+			rows++
+			continue
+		}
+		rows += (lineWidth + width - 1) / width
+	}
+	return rows
+}
@@ -0,0 +1,88 @@
+package palantir
+
+import (
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"Plain text", "hello", 5},
+		{"ANSI colors are ignored", ColorBold + ColorGreen + "hello" + ColorReset, 5},
+		{"Emoji are two columns wide", "✅ done", 7},
+		{"Variation selectors are zero width", "⚠️ careful", 9},
+		{"CJK characters are two columns wide", "日本", 4},
+		{"Box drawing characters are one column", "├── ", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if width := displayWidth(tt.input); width != tt.expected {
+				t.Errorf("displayWidth(%q) = %d, want %d", tt.input, width, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	tests := []struct {
+		name     string
+		config   *OutputConfig
+		level    OutputLevel
+		message  string
+		expected int
+	}{
+		{"Short message", &OutputConfig{WrapWidth: 20}, LevelInfo, "hello", 1},
+		{"Exactly the width", &OutputConfig{WrapWidth: 5}, LevelInfo, "hello", 1},
+		{"Wrapped message", &OutputConfig{WrapWidth: 10}, LevelInfo, "abcdefghijklmnopqrstuvwxy", 3},
+		{"Multi-line message", &OutputConfig{WrapWidth: 20}, LevelInfo, "one\ntwo\nthree", 3},
+		{"Multi-line and wrapped", &OutputConfig{WrapWidth: 10}, LevelInfo, "short\nabcdefghijklmno", 3},
+		{"Prefix counts towards the width", &OutputConfig{WrapWidth: 10}, LevelError, "abcdef", 2},
+		{"ANSI codes do not count", &OutputConfig{WrapWidth: 10, UseColors: true, UseFormatting: true}, LevelInfo, "abcdefghij", 1},
+		{"Emoji prefixes are two columns", &OutputConfig{WrapWidth: 10, UseColors: true, UseEmojis: true, UseFormatting: true}, LevelSuccess, "abcdefg", 1},
+		{"Header includes its blank line", &OutputConfig{WrapWidth: 40}, LevelHeader, "Title", 2},
+		{"No width means no wrapping", &OutputConfig{}, LevelInfo, "abcdefghijklmnopqrstuvwxyz", 1},
+		{"Disabled output takes no rows", &OutputConfig{WrapWidth: 10, DisableOutput: true}, LevelInfo, "hello", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COLUMNS", "")
+			handler := NewOutputHandler(tt.config)
+			if lines := handler.CountLines(tt.level, tt.message); lines != tt.expected {
+				t.Errorf("CountLines() = %d, want %d", lines, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCountLinesUsesColumns(t *testing.T) {
+	setupSupportedTerminal(t)
+	t.Setenv("COLUMNS", "10")
+
+	handler := NewOutputHandler(&OutputConfig{})
+	if lines := handler.CountLines(LevelInfo, "abcdefghijklmnopqrst"); lines != 2 {
+		t.Errorf("CountLines() = %d, want 2", lines)
+	}
+}
+
+func TestCountLinesThroughWrappers(t *testing.T) {
+	setupSupportedTerminal(t)
+	inner := NewOutputHandler(&OutputConfig{WrapWidth: 10})
+
+	if lines := NewPrefixedHandler(inner, "api").WithWidth(0).CountLines(LevelInfo, "abcde"); lines != 2 {
+		t.Errorf("PrefixedHandler.CountLines() = %d, want 2 as the tag counts towards the width", lines)
+	}
+	filtered := NewLevelFilterHandler(inner, LevelError)
+	if lines := filtered.CountLines(LevelInfo, "abcde"); lines != 0 {
+		t.Errorf("LevelFilterHandler.CountLines() = %d, want 0 for a filtered level", lines)
+	}
+	if lines := filtered.CountLines(LevelError, "abcde"); lines != 2 {
+		t.Errorf("LevelFilterHandler.CountLines() = %d, want 2", lines)
+	}
+}
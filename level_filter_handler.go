@@ -0,0 +1,250 @@
+package palantir
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LevelFilterHandler wraps an OutputHandler, dropping the messages of levels that are not
+// allowed, such as everything but warnings and errors printed by a library. Each method
+// is filtered by the level it prints at: PrintColored counts as LevelInfo,
+// PrintCommandOutput as LevelStage and PrintProgress as LevelProgress. Confirmations
+// always pass through.
+type LevelFilterHandler struct {
+	inner  OutputHandler
+	filter *levelFilter  // Shared with the handlers returned by WithPrefix
+	stages *stageCounter // Numbers the scopes begun on the handler, shared with WithPrefix
+}
+
+// levelFilter holds the levels a LevelFilterHandler lets through
+type levelFilter struct {
+	mu      sync.RWMutex
+	allowed map[OutputLevel]bool
+}
+
+// NewLevelFilterHandler creates a handler passing only the allowed levels on to inner
+func NewLevelFilterHandler(inner OutputHandler, allowed ...OutputLevel) *LevelFilterHandler {
+	handler := &LevelFilterHandler{inner: inner, filter: &levelFilter{}, stages: &stageCounter{}}
+	handler.SetAllowed(allowed...)
+	return handler
+}
+
+// SetAllowed replaces the levels that are let through. It is safe to call while other
+// goroutines print.
+func (f *LevelFilterHandler) SetAllowed(allowed ...OutputLevel) {
+	levels := make(map[OutputLevel]bool, len(allowed))
+	for _, level := range allowed {
+		levels[level] = true
+	}
+
+	f.filter.mu.Lock()
+	defer f.filter.mu.Unlock()
+	f.filter.allowed = levels
+}
+
+// allows reports whether messages of level are let through
+func (f *LevelFilterHandler) allows(level OutputLevel) bool {
+	f.filter.mu.RLock()
+	defer f.filter.mu.RUnlock()
+	return f.filter.allowed[level]
+}
+
+func (f *LevelFilterHandler) PrintHeader(message string) {
+	if f.allows(LevelHeader) {
+		f.inner.PrintHeader(message)
+	}
+}
+
+func (f *LevelFilterHandler) PrintHeaderWithSubtitle(title, subtitle string) {
+	if f.allows(LevelHeader) {
+		f.inner.PrintHeaderWithSubtitle(title, subtitle)
+	}
+}
+
+func (f *LevelFilterHandler) PrintStage(message string) {
+	if f.allows(LevelStage) {
+		f.inner.PrintStage(message)
+	}
+}
+
+func (f *LevelFilterHandler) PrintSuccess(message string) {
+	if f.allows(LevelSuccess) {
+		f.inner.PrintSuccess(message)
+	}
+}
+
+func (f *LevelFilterHandler) PrintSuccessWithDuration(message string, d time.Duration) {
+	if f.allows(LevelSuccess) {
+		f.inner.PrintSuccessWithDuration(message, d)
+	}
+}
+
+func (f *LevelFilterHandler) PrintError(format string, args ...interface{}) {
+	if f.allows(LevelError) {
+		f.inner.PrintError(format, args...)
+	}
+}
+
+func (f *LevelFilterHandler) PrintErrors(errs ...error) {
+	if f.allows(LevelError) {
+		f.inner.PrintErrors(errs...)
+	}
+}
+
+func (f *LevelFilterHandler) PrintCommandOutput(label, output string) {
+	if f.allows(LevelStage) {
+		f.inner.PrintCommandOutput(label, output)
+	}
+}
+
+func (f *LevelFilterHandler) LogTree(level OutputLevel, tree *TreeNode, opts ...BuildOption) {
+	if f.allows(level) {
+		f.inner.LogTree(level, tree, opts...)
+	}
+}
+
+func (f *LevelFilterHandler) PrintWarning(format string, args ...interface{}) {
+	if f.allows(LevelWarning) {
+		f.inner.PrintWarning(format, args...)
+	}
+}
+
+func (f *LevelFilterHandler) PrintInfo(format string, args ...interface{}) {
+	if f.allows(LevelInfo) {
+		f.inner.PrintInfo(format, args...)
+	}
+}
+
+func (f *LevelFilterHandler) PrintAlreadyAvailable(format string, args ...interface{}) {
+	if f.allows(LevelAvailable) {
+		f.inner.PrintAlreadyAvailable(format, args...)
+	}
+}
+
+func (f *LevelFilterHandler) PrintColored(color string, format string, args ...interface{}) {
+	if f.allows(LevelInfo) {
+		f.inner.PrintColored(color, format, args...)
+	}
+}
+
+func (f *LevelFilterHandler) PrintProgress(current, total int, message string) {
+	if f.allows(LevelProgress) {
+		f.inner.PrintProgress(current, total, message)
+	}
+}
+
+func (f *LevelFilterHandler) Confirm(message string) bool {
+	return f.inner.Confirm(message)
+}
+
+func (f *LevelFilterHandler) ConfirmE(message string) (bool, error) {
+	return f.inner.ConfirmE(message)
+}
+
+func (f *LevelFilterHandler) IsSupported() bool {
+	return f.inner.IsSupported()
+}
+
+func (f *LevelFilterHandler) Disable() {
+	f.inner.Disable()
+}
+
+// WithPrefix returns a filtered inner.WithPrefix(prefix), sharing this handler's allowed
+// levels so SetAllowed applies to both
+func (f *LevelFilterHandler) WithPrefix(prefix string) OutputHandler {
+	return &LevelFilterHandler{inner: f.inner.WithPrefix(prefix), filter: f.filter, stages: f.stages}
+}
+
+func (f *LevelFilterHandler) Config() *OutputConfig {
+	return f.inner.Config()
+}
+
+// Close closes the wrapped handler
+func (f *LevelFilterHandler) Close() error {
+	return f.inner.Close()
+}
+
+// Flush flushes the wrapped handler
+func (f *LevelFilterHandler) Flush() error {
+	return f.inner.Flush()
+}
+
+// NewTaskList creates a task list printing each change as a line through the filter, so
+// started tasks count as LevelStage, details as LevelInfo and results as their level
+func (f *LevelFilterHandler) NewTaskList() *TaskList {
+	return newTaskList(f)
+}
+
+// NewMultiProgress creates progress bars printing each update through the filter as
+// LevelProgress
+func (f *LevelFilterHandler) NewMultiProgress() *MultiProgress {
+	return newMultiProgress(f)
+}
+
+// RunStep runs fn like the built-in handler's RunStep, printing the stage and its result
+// through the filter without a spinner. fn runs even when its lines are filtered.
+func (f *LevelFilterHandler) RunStep(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return runStep(f, ctx, name, fn)
+}
+
+// RunSteps runs steps with RunStep and prints their summary through the filter
+func (f *LevelFilterHandler) RunSteps(ctx context.Context, steps []Step) error {
+	return runSteps(ctx, steps, f.RunStep, printStepsSummaryTo(f))
+}
+
+func (f *LevelFilterHandler) PrintErrorWithStack(err error) {
+	if f.allows(LevelError) {
+		f.inner.PrintErrorWithStack(err)
+	}
+}
+
+// RecoverAndPrint recovers a panic and has the wrapped handler print it and apply its
+// OnPanic. Like confirmations, panics always pass through. It must be deferred directly,
+// as in `defer handler.RecoverAndPrint()`.
+func (f *LevelFilterHandler) RecoverAndPrint() {
+	if r := recover(); r != nil {
+		rethrowTo(f.inner, r)
+	}
+}
+
+// SafeGo runs fn in a new goroutine, printing a panic raised by it with RecoverAndPrint
+func (f *LevelFilterHandler) SafeGo(fn func()) {
+	go func() {
+		defer f.RecoverAndPrint()
+		fn()
+	}()
+}
+
+// Bell rings the bell of the wrapped handler when LevelInfo, which it is written at, is
+// allowed
+func (f *LevelFilterHandler) Bell() {
+	if f.allows(LevelInfo) {
+		f.inner.Bell()
+	}
+}
+
+// TimedStage runs fn like the built-in handler's TimedStage, printing the stage and its result through the filter
+func (f *LevelFilterHandler) TimedStage(name string, fn func() error) error {
+	return timedStage(name, fn, f.PrintStage, stageResultPrinter(f))
+}
+
+// TimedStageContext runs fn like the built-in handler's TimedStageContext, printing the stage and its result through the filter
+func (f *LevelFilterHandler) TimedStageContext(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return timedStageContext(ctx, name, fn, f.PrintStage, stageResultPrinter(f))
+}
+
+// BeginStage prints a numbered stage through the filter and returns its scope. Its
+// numbering is the handler's own, as the wrapped handler's is not reachable.
+func (f *LevelFilterHandler) BeginStage(name string) *StageScope {
+	return beginStage(f, f.stages, name)
+}
+
+// CountLines returns how many terminal rows the wrapped handler would print the message on,
+// or 0 when its level is filtered out
+func (f *LevelFilterHandler) CountLines(level OutputLevel, message string) int {
+	if !f.allows(level) {
+		return 0
+	}
+	return f.inner.CountLines(level, message)
+}
@@ -0,0 +1,92 @@
+package palantir
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// allLevels lists every level a LevelFilterHandler filters
+var allLevels = []OutputLevel{LevelInfo, LevelWarning, LevelError, LevelSuccess, LevelStage, LevelHeader, LevelAvailable, LevelProgress}
+
+func TestLevelFilterHandlerMethods(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	methods := []struct {
+		name  string
+		level OutputLevel
+		call  func(h OutputHandler)
+	}{
+		{"PrintHeader", LevelHeader, func(h OutputHandler) { h.PrintHeader("header") }},
+		{"PrintHeaderWithSubtitle", LevelHeader, func(h OutputHandler) { h.PrintHeaderWithSubtitle("title", "subtitle") }},
+		{"PrintStage", LevelStage, func(h OutputHandler) { h.PrintStage("stage") }},
+		{"PrintCommandOutput", LevelStage, func(h OutputHandler) { h.PrintCommandOutput("go test", "ok") }},
+		{"PrintSuccess", LevelSuccess, func(h OutputHandler) { h.PrintSuccess("success") }},
+		{"PrintSuccessWithDuration", LevelSuccess, func(h OutputHandler) { h.PrintSuccessWithDuration("success", time.Second) }},
+		{"PrintError", LevelError, func(h OutputHandler) { h.PrintError("error") }},
+		{"PrintErrors", LevelError, func(h OutputHandler) { h.PrintErrors(errors.New("a"), errors.New("b")) }},
+		{"PrintWarning", LevelWarning, func(h OutputHandler) { h.PrintWarning("warning") }},
+		{"PrintInfo", LevelInfo, func(h OutputHandler) { h.PrintInfo("info") }},
+		{"PrintColored", LevelInfo, func(h OutputHandler) { h.PrintColored(ColorCyan, "colored") }},
+		{"PrintAlreadyAvailable", LevelAvailable, func(h OutputHandler) { h.PrintAlreadyAvailable("available") }},
+		{"PrintProgress", LevelProgress, func(h OutputHandler) { h.PrintProgress(1, 2, "progress") }},
+		{"LogTree", LevelSuccess, func(h OutputHandler) { h.LogTree(LevelSuccess, &TreeNode{Name: "root"}) }},
+	}
+
+	for _, m := range methods {
+		t.Run(m.name, func(t *testing.T) {
+			var others []OutputLevel
+			for _, level := range allLevels {
+				if level != m.level {
+					others = append(others, level)
+				}
+			}
+
+			filtered := NewLevelFilterHandler(NewOutputHandler(&OutputConfig{UseFormatting: true}), m.level)
+			if output := captureOutput(func() { m.call(filtered) }); output == "" {
+				t.Errorf("Expected %s to pass with its level allowed", m.name)
+			}
+
+			filtered.SetAllowed(others...)
+			if output := captureOutput(func() { m.call(filtered) }); output != "" {
+				t.Errorf("Expected %s to be dropped, got %q", m.name, output)
+			}
+		})
+	}
+}
+
+func TestLevelFilterHandlerPassesConfirm(t *testing.T) {
+	inner := NewOutputHandler(&OutputConfig{Reader: strings.NewReader("y\n")})
+	filtered := NewLevelFilterHandler(inner)
+
+	var confirmed bool
+	output := captureOutput(func() {
+		confirmed = filtered.Confirm("Continue?")
+	})
+	if !confirmed || !strings.Contains(output, "Continue?") {
+		t.Errorf("Expected the confirmation to pass through, got %v and %q", confirmed, output)
+	}
+	if filtered.Config() != inner.Config() {
+		t.Error("Expected Config to be forwarded")
+	}
+}
+
+func TestLevelFilterHandlerWithPrefix(t *testing.T) {
+	filtered := NewLevelFilterHandler(NewOutputHandler(&OutputConfig{UseFormatting: true}), LevelWarning, LevelError)
+	prefixed := filtered.WithPrefix("lib")
+
+	output := captureOutput(func() {
+		prefixed.PrintInfo("chatty")
+		prefixed.PrintWarning("deprecated")
+	})
+	if output != "[lib] [WARNING] deprecated\n" {
+		t.Errorf("Expected only the prefixed warning, got %q", output)
+	}
+
+	// Runtime changes apply to derived handlers
+	filtered.SetAllowed(LevelError)
+	if output := captureOutput(func() { prefixed.PrintWarning("deprecated") }); output != "" {
+		t.Errorf("Expected the warning to be dropped after SetAllowed, got %q", output)
+	}
+}
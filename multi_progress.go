@@ -0,0 +1,144 @@
+package palantir
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// progressBarWidth is the number of cells of the bars drawn by MultiProgress
+const progressBarWidth = 20
+
+// MultiProgress shows several progress bars at once, such as for parallel downloads. On a
+// terminal the bars are kept on their own lines and all redrawn in place when any of them
+// changes. Elsewhere each update is printed as a line like PrintProgress, such as
+// "[3/10] 30% - api", and wrapping handlers such as PrefixedHandler print it with their
+// PrintProgress. Bars may be updated from several goroutines.
+type MultiProgress struct {
+	handler *outputHandler // The handler drawing the bars, nil when they are printed through out
+	out     OutputHandler  // The handler the bars were created on
+	live    bool           // Whether the bars are redrawn in place
+
+	mu    sync.Mutex
+	bars  []*Bar
+	block liveBlock
+}
+
+// Bar is a progress bar of a MultiProgress, created by AddBar
+type Bar struct {
+	progress *MultiProgress
+	label    string
+	current  int
+	total    int
+	state    progressState // Throttling and accessible announcements of this bar
+}
+
+// NewMultiProgress creates a manager without bars, live when output is a terminal
+func (oh *outputHandler) NewMultiProgress() *MultiProgress {
+	return &MultiProgress{handler: oh, out: oh, live: oh.redrawable()}
+}
+
+// newMultiProgress creates a manager without bars printing each update with out's
+// PrintProgress, for handlers other than the built-in one
+func newMultiProgress(out OutputHandler) *MultiProgress {
+	return &MultiProgress{out: out}
+}
+
+// AddBar adds a bar counting to total below the existing ones
+func (m *MultiProgress) AddBar(total int, label string) *Bar {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bar := &Bar{progress: m, label: label, total: total}
+	m.bars = append(m.bars, bar)
+	if m.live {
+		m.paint()
+	}
+	return bar
+}
+
+// Set moves the bar to current, clamped between 0 and its total
+func (b *Bar) Set(current int) {
+	b.progress.update(b, func() { b.current = current })
+}
+
+// Increment moves the bar forward by n
+func (b *Bar) Increment(n int) {
+	b.progress.update(b, func() { b.current += n })
+}
+
+// Done moves the bar to its total
+func (b *Bar) Done() {
+	b.progress.update(b, func() { b.current = b.total })
+}
+
+// update applies change to a bar and shows it, unless MinUpdateInterval throttles it: by
+// redrawing the bars when live, and otherwise by printing the bar as a line
+func (m *MultiProgress) update(b *Bar, change func()) {
+	defer recoverPanic(m.out.Config())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	change()
+	b.current = min(max(b.current, 0), max(b.total, 0))
+
+	oh := m.handler
+	if oh == nil {
+		m.out.PrintProgress(b.current, b.total, b.label)
+		return
+	}
+	if oh.config.DisableOutput || oh.config.QuietMode {
+		return
+	}
+	if oh.config.MinUpdateInterval > 0 && b.state.throttled(b.current >= b.total, oh.config.MinUpdateInterval) {
+		return
+	}
+	switch {
+	case m.live:
+		m.paint()
+	case oh.config.accessible():
+		if b.state.announce(b.current, b.total) {
+			oh.write(LevelInfo, formatAccessibleProgress(b.current, b.total, b.label))
+		}
+	default:
+		oh.write(LevelInfo, fmt.Sprintf("[%d/%d] %s - %s\n", b.current, b.total, b.percentage(), b.label))
+	}
+}
+
+// paint redraws every bar with m.mu held
+func (m *MultiProgress) paint() {
+	lines := make([]string, len(m.bars))
+	for i, bar := range m.bars {
+		lines[i] = bar.line()
+	}
+	m.block.paint(m.handler.writerFor(LevelInfo), lines)
+}
+
+// line formats a bar, such as "[██████░░░░░░░░░░░░░░] 3/10 30% api"
+func (b *Bar) line() string {
+	config := b.progress.handler.config
+	filled := progressBarWidth
+	if b.total > 0 {
+		filled = b.current * progressBarWidth / b.total
+	}
+
+	full, empty := "█", "░"
+	if config.ASCIIOnly {
+		full, empty = "#", "-"
+	}
+	bar := strings.Repeat(full, filled) + strings.Repeat(empty, progressBarWidth-filled)
+	if config.UseColors && config.UseFormatting {
+		bar = ColorCyan + bar + ColorReset
+	}
+	return fmt.Sprintf("[%s] %d/%d %s %s", bar, b.current, b.total, b.percentage(), b.label)
+}
+
+// percentage formats the bar's progress with ProgressPrecision decimals. A bar without a
+// total is complete.
+func (b *Bar) percentage() string {
+	if b.total <= 0 {
+		return b.progress.handler.formatPercentage(1, 1)
+	}
+	return b.progress.handler.formatPercentage(b.current, b.total)
+}
@@ -0,0 +1,89 @@
+package palantir
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// barLine formats a bar line of a MultiProgress without colors, for filled of 20 cells
+func barLine(filled int, progress string) string {
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled) + "] " + progress
+}
+
+func TestMultiProgressCursorMovement(t *testing.T) {
+	var tty bytes.Buffer
+	stubTerminal(t, &tty)
+	handler := newTimedStageHandler(&tty)
+
+	multi := handler.NewMultiProgress()
+	api := multi.AddBar(10, "api")
+	db := multi.AddBar(4, "db")
+	api.Set(5)
+	db.Increment(1)
+
+	expected := clearLine + barLine(0, "0/10 0% api") + "\n" +
+		"\033[1A" + clearLine + barLine(0, "0/10 0% api") + "\n" + clearLine + barLine(0, "0/4 0% db") + "\n" +
+		"\033[2A" + clearLine + barLine(10, "5/10 50% api") + "\n" + clearLine + barLine(0, "0/4 0% db") + "\n" +
+		"\033[2A" + clearLine + barLine(10, "5/10 50% api") + "\n" + clearLine + barLine(5, "1/4 25% db") + "\n"
+	if tty.String() != expected {
+		t.Errorf("Output = %q, want %q", tty.String(), expected)
+	}
+}
+
+func TestMultiProgressFinalScreen(t *testing.T) {
+	screen := &fakeScreen{}
+	stubTerminal(t, screen)
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithASCIIOnly().WithWriter(screen).Build())
+
+	multi := handler.NewMultiProgress()
+	api, db := multi.AddBar(10, "api"), multi.AddBar(4, "db")
+	db.Done()
+	api.Increment(3)
+	api.Increment(20) // Clamped to the total
+	db.Set(-1)        // Clamped to zero
+
+	expected := []string{
+		"[####################] 10/10 100% api",
+		"[--------------------] 0/4 0% db",
+	}
+	if got := screen.screen(); strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Screen = %q, want %q", got, expected)
+	}
+}
+
+func TestMultiProgressNonTTY(t *testing.T) {
+	var buf bytes.Buffer
+	handler := newTimedStageHandler(&buf)
+
+	multi := handler.NewMultiProgress()
+	api, db := multi.AddBar(10, "api"), multi.AddBar(4, "db")
+	api.Set(3)
+	db.Done()
+
+	expected := "[3/10] 30% - api\n[4/4] 100% - db\n"
+	if buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestMultiProgressThroughWrappers(t *testing.T) {
+	var buf bytes.Buffer
+	inner := newTimedStageHandler(&buf)
+
+	multi := NewPrefixedHandler(inner, "dl").WithWidth(0).NewMultiProgress()
+	api, db := multi.AddBar(10, "api"), multi.AddBar(4, "db")
+	api.Set(3)
+	db.Done()
+
+	expected := "\r[3/10] 30% - [dl] api\n\r[4/4] 100% - [dl] db\n"
+	if buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+
+	buf.Reset()
+	NewLevelFilterHandler(inner, LevelError).NewMultiProgress().AddBar(2, "hidden").Done()
+	if buf.String() != "" {
+		t.Errorf("Expected the filter to drop progress, got %q", buf.String())
+	}
+}
@@ -1,8 +1,13 @@
 package palantir
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
 // OutputLevel represents different levels of output
@@ -15,55 +20,171 @@ const (
 	LevelSuccess
 	LevelStage
 	LevelHeader
+	LevelAvailable
+	// LevelProgress identifies PrintProgress updates, such as for a LevelFilterHandler.
+	// Progress is written to the LevelInfo writer.
+	LevelProgress
 )
 
 // OutputHandler defines the interface for terminal output operations
 type OutputHandler interface {
 	PrintHeader(message string)
+	PrintHeaderWithSubtitle(title, subtitle string)
 	PrintStage(message string)
 	PrintSuccess(message string)
+	PrintSuccessWithDuration(message string, d time.Duration)
 	PrintError(format string, args ...interface{})
+	PrintErrors(errs ...error)
+	PrintCommandOutput(label, output string)
+	LogTree(level OutputLevel, tree *TreeNode, opts ...BuildOption)
 	PrintWarning(format string, args ...interface{})
 	PrintInfo(format string, args ...interface{})
 	PrintAlreadyAvailable(format string, args ...interface{})
+	PrintColored(color string, format string, args ...interface{})
 	PrintProgress(current, total int, message string)
 	Confirm(message string) bool
+	ConfirmE(message string) (bool, error)
 	IsSupported() bool
 	Disable()
+	WithPrefix(prefix string) OutputHandler
+	Config() *OutputConfig
+	Close() error // Flush buffered output and stop background work; callers should defer it
+	Flush() error // Write output batched under OutputConfig.FlushInterval
+	NewTaskList() *TaskList
+	NewMultiProgress() *MultiProgress
+	RunStep(ctx context.Context, name string, fn func(ctx context.Context) error) error
+	RunSteps(ctx context.Context, steps []Step) error
+	PrintErrorWithStack(err error)
+	RecoverAndPrint() // Must be deferred directly, as in `defer handler.RecoverAndPrint()`
+	SafeGo(fn func())
+	Bell()
+	TimedStage(name string, fn func() error) error
+	TimedStageContext(ctx context.Context, name string, fn func(ctx context.Context) error) error
+	BeginStage(name string) *StageScope
+	CountLines(level OutputLevel, message string) int
 }
 
 // OutputConfig holds configuration for output formatting
 type OutputConfig struct {
-	UseColors         bool
-	UseEmojis         bool
-	UseFormatting     bool
-	DisableOutput     bool
-	VerboseMode       bool
-	ColorizeLevelOnly bool
+	UseColors          bool
+	UseEmojis          bool
+	UseFormatting      bool
+	DisableOutput      bool
+	VerboseMode        bool
+	QuietMode          bool // Only print warnings and errors
+	Verbosity          int  // Verbosity level set by ApplyVerbosity
+	ColorizeLevelOnly  bool
+	WrapWidth          int  // Terminal width used to measure output, 0 reads the COLUMNS environment variable
+	TrimTrailingSpace  bool // Remove trailing whitespace from every line of a message
+	CollapseBlankLines bool // Collapse runs of blank lines in a message into one
+	ProgressPrecision  int  // Decimal places of the PrintProgress percentage, such as 1 for "33.3%"
+	BellOnDone         bool // Ring the terminal bell after success messages, see Bell
+	NumberStages       bool // Number stages as "[2/?] build", or "[2/5] build" after SetTotalStages
+	SuppressRepeats    bool // Replace repeats of the previous line with a "(last message repeated N times)" summary
+	RecoverPanics      bool // Report panics raised while printing, such as by a custom writer, on standard error instead of crashing
+	AutoDetectEmoji    bool // With UseEmojis, fall back to "[LEVEL]" prefixes when the locale or terminal is unlikely to render emoji
+	AccessibleMode     bool // Screen-reader friendly output: no colors or emoji, level words, coarse progress and trees as indented lines
+
+	// MinUpdateInterval coalesces PrintProgress calls arriving sooner than this after the
+	// last printed update. The first and final (current >= total) updates are always printed.
+	MinUpdateInterval time.Duration
+
+	// LevelStyles overrides the prefixes and color of levels, such as to label info
+	// messages "[INFO] ". Levels without an entry keep their default style.
+	LevelStyles map[OutputLevel]LevelStyle
+
+	// FlushInterval batches output, writing it at most this often, before Confirm prompts
+	// and on Flush and Close, so rapid short messages do not each cost a write. Output is
+	// never reordered. Callers should Close the handler before exiting.
+	FlushInterval time.Duration
+
+	// OnPanic selects whether RecoverAndPrint swallows a panic after printing it, which is
+	// the default, panics again or exits the program.
+	OnPanic PanicAction
+
+	// ExitCodeFunc maps the number of errors printed to the exit code suggested by
+	// ExitCode, such as to tell one failure from many. When nil, any error suggests 1.
+	ExitCodeFunc func(errorCount int) int
+
+	// CIMode writes headers, errors and warnings as CI workflow commands, such as
+	// "::error::" annotations. The default configuration uses CIModeAuto.
+	CIMode CIMode
+
+	TreeStyle TreeConnectorStyle // Connector characters used to draw trees
+	ASCIIOnly bool               // Draw trees with plain ASCII connectors, overriding TreeStyle
+
+	// Reader supplies the answers to Confirm, such as a strings.Reader in tests.
+	// When nil, answers are read from standard input. A file that is not a terminal,
+	// such as redirected standard input in CI, is not read: Confirm assumes "no".
+	Reader io.Reader
+
+	// LevelWriters routes each level's output to its own writer, such as errors to
+	// os.Stderr. Levels without a writer go to standard output.
+	LevelWriters map[OutputLevel]io.Writer
 }
 
 // outputHandler implements the OutputHandler interface
 type outputHandler struct {
-	config *OutputConfig
+	config   *OutputConfig
+	prefixes []string
+	repeats  *repeatState   // Shared with prefixed handlers, which write to the same streams
+	progress *progressState // Shared with prefixed handlers, which report the same progress
+	groups   *groupState    // Shared with prefixed handlers, which write to the same log
+	errs     *errorCounter  // Shared with prefixed handlers, which report to the same program
+	output   *coalescer     // Shared with prefixed handlers, which write to the same streams
+	stages   *stageCounter  // Shared with prefixed handlers, which number the same stages
+	spinners *spinnerSet    // Shared with prefixed handlers, which draw on the same streams
 }
 
+// repeatState tracks the last line written for OutputConfig.SuppressRepeats
+type repeatState struct {
+	mu     sync.Mutex
+	last   string
+	writer io.Writer
+	count  int // Repeats of last that were suppressed
+}
+
+// progressState remembers when PrintProgress last printed for OutputConfig.MinUpdateInterval
+// and OutputConfig.AccessibleMode
+type progressState struct {
+	mu        sync.Mutex
+	last      time.Time // Zero when no progress is being reported
+	announced int       // Step last announced in accessible mode, plus one; zero when none was
+}
+
+// nowFunc returns the current time. It is a variable so tests can control the clock.
+var nowFunc = time.Now
+
 // NewDefaultOutputHandler creates a new outputHandler with default configurations
 func NewDefaultOutputHandler() OutputHandler {
 	return &outputHandler{
-		config: &OutputConfig{
-			UseColors:         true,
-			UseEmojis:         true,
-			UseFormatting:     true,
-			DisableOutput:     false,
-			VerboseMode:       false,
-			ColorizeLevelOnly: false,
-		},
+		repeats:  &repeatState{},
+		progress: &progressState{},
+		groups:   &groupState{},
+		errs:     &errorCounter{},
+		output:   &coalescer{},
+		stages:   &stageCounter{},
+		spinners: &spinnerSet{},
+		config:   defaultOutputConfig(),
+	}
+}
+
+// defaultOutputConfig returns the configuration of NewDefaultOutputHandler
+func defaultOutputConfig() *OutputConfig {
+	return &OutputConfig{
+		UseColors:         true,
+		UseEmojis:         true,
+		UseFormatting:     true,
+		DisableOutput:     false,
+		VerboseMode:       false,
+		ColorizeLevelOnly: false,
+		CIMode:            CIModeAuto,
 	}
 }
 
 // NewOutputHandler creates a new outputHandler with a custom configurations
 func NewOutputHandler(config *OutputConfig) *outputHandler {
-	return &outputHandler{config: config}
+	return &outputHandler{config: config, repeats: &repeatState{}, progress: &progressState{}, groups: &groupState{}, errs: &errorCounter{}, output: &coalescer{}, stages: &stageCounter{}, spinners: &spinnerSet{}}
 }
 
 // FormatMessage formats a message according to the output level
@@ -72,6 +193,14 @@ func (oh *outputHandler) FormatMessage(level OutputLevel, message string) string
 		return ""
 	}
 
+	// Headers intentionally add blank lines, so only the other levels are tidied
+	if level != LevelHeader {
+		message = oh.tidyMessage(message)
+	}
+
+	if oh.config.accessible() {
+		return formatAccessibleMessage(level, message)
+	}
 	if !oh.IsSupported() {
 		return message
 	}
@@ -79,27 +208,24 @@ func (oh *outputHandler) FormatMessage(level OutputLevel, message string) string
 	// Headers are treated specially because the level representation is the banner itself.
 	if level == LevelHeader {
 		if oh.config.UseColors {
-			color := outputColors[level]
+			color := oh.config.levelStyle(level).Color
 			return fmt.Sprintf(coloredHeaderFormat, ColorBold, color, message, ColorReset)
 		}
 		return fmt.Sprintf(headerFormat, message)
 	}
 
-	var prefix string
-	var color string
-
-	if oh.config.UseColors && oh.config.UseEmojis && oh.config.UseFormatting {
-		prefix = outputEmojis[level]
-		color = outputColors[level]
-	} else {
-		prefix = outputPrefixes[level]
-		if oh.config.UseColors {
-			color = outputColors[level]
-		}
+	style := oh.config.levelStyle(level)
+	prefix, color := style.Prefix, ""
+	if oh.config.UseColors && oh.config.emojisEnabled() && oh.config.UseFormatting {
+		prefix = style.Emoji
+	}
+	if oh.config.UseColors {
+		color = style.Color
 	}
 
-	if oh.config.UseColors && oh.config.UseFormatting {
-		if oh.config.ColorizeLevelOnly && color != "" && prefix != "" {
+	// Levels without a color, such as info by default, are printed plain rather than bold
+	if oh.config.UseColors && oh.config.UseFormatting && color != "" {
+		if oh.config.ColorizeLevelOnly && prefix != "" {
 			coloredPrefix := fmt.Sprintf("%s%s%s%s", ColorBold, color, prefix, ColorReset)
 			return fmt.Sprintf("%s%s\n", coloredPrefix, message)
 		}
@@ -109,15 +235,188 @@ func (oh *outputHandler) FormatMessage(level OutputLevel, message string) string
 	return fmt.Sprintf("%s%s\n", prefix, message)
 }
 
-// PrintWithLevel prints a message with the specified level
+// emojisEnabled reports whether emojis should be printed: UseEmojis is set outside
+// accessible mode and, under AutoDetectEmoji, the environment looks able to render them
+func (c *OutputConfig) emojisEnabled() bool {
+	return c.UseEmojis && !c.accessible() && (!c.AutoDetectEmoji || emojiSupported())
+}
+
+// emojiSupported guesses whether the terminal renders emoji: the locale must use UTF-8,
+// and the Linux console and dumb terminals are assumed to lack emoji glyphs
+func emojiSupported() bool {
+	switch os.Getenv("TERM") {
+	case "linux", "dumb":
+		return false
+	}
+
+	// The first locale variable set wins, as in setlocale
+	var locale string
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale = os.Getenv(name); locale != "" {
+			break
+		}
+	}
+	locale = strings.ToLower(locale)
+	return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+}
+
+// tidyMessage applies TrimTrailingSpace and CollapseBlankLines to a message
+func (oh *outputHandler) tidyMessage(message string) string {
+	if !oh.config.TrimTrailingSpace && !oh.config.CollapseBlankLines {
+		return message
+	}
+
+	lines := strings.Split(message, "\n")
+	tidied := make([]string, 0, len(lines))
+	previousBlank := false
+	for _, line := range lines {
+		if oh.config.TrimTrailingSpace {
+			line = strings.TrimRight(line, " \t")
+		}
+
+		blank := strings.TrimSpace(line) == ""
+		if oh.config.CollapseBlankLines && blank && previousBlank {
+			continue
+		}
+		previousBlank = blank
+		tidied = append(tidied, line)
+	}
+	return strings.Join(tidied, "\n")
+}
+
+// PrintWithLevel prints a message with the specified level. Errors are counted even
+// when output is disabled, see ErrorTracker.
 func (oh *outputHandler) PrintWithLevel(level OutputLevel, format string, args ...interface{}) {
-	if oh.config.DisableOutput {
+	if level == LevelError {
+		oh.errs.add(1)
+	}
+	oh.printMessage(level, fmt.Sprintf(format, args...))
+}
+
+// printMessage prints a formatted message with the specified level without counting it
+func (oh *outputHandler) printMessage(level OutputLevel, message string) {
+	if oh.config.DisableOutput || oh.isQuieted(level) {
 		return
 	}
 
-	message := fmt.Sprintf(format, args...)
+	if oh.config.githubActions() && oh.printWorkflowCommand(level, oh.tidyMessage(message)) {
+		return
+	}
 	formatted := oh.FormatMessage(level, message)
-	fmt.Print(formatted)
+	oh.write(level, formatted)
+}
+
+// isQuieted reports whether quiet mode suppresses messages of the given level
+func (oh *outputHandler) isQuieted(level OutputLevel) bool {
+	return oh.config.QuietMode && level != LevelError && level != LevelWarning
+}
+
+// write emits formatted output to the level's writer, prepending the handler's prefixes to every line.
+// Colors are removed in accessible mode.
+func (oh *outputHandler) write(level OutputLevel, output string) {
+	defer recoverPanic(oh.config)
+
+	if oh.config.accessible() {
+		output = stripANSI(output)
+	}
+	output = oh.applyPrefix(output)
+	writer := oh.writerFor(level)
+	if oh.config.SuppressRepeats && oh.repeats != nil && oh.repeats.suppress(writer, output) {
+		return
+	}
+	fmt.Fprint(writer, output)
+}
+
+// recoverPanic stops a panic raised while printing and reports it on standard error when
+// RecoverPanics is set, so a failing writer cannot crash a long-running process. It must
+// be deferred directly.
+func recoverPanic(config *OutputConfig) {
+	if config == nil || !config.RecoverPanics {
+		return
+	}
+	if r := recover(); r != nil {
+		fmt.Fprintf(os.Stderr, "palantir: recovered from panic while printing: %v\n", r)
+	}
+}
+
+// suppress reports whether output repeats the previous line and should be skipped.
+// Otherwise it writes the summary of any suppressed repeats and remembers output.
+// Progress lines, which redraw themselves with a carriage return, are never suppressed.
+func (r *repeatState) suppress(writer io.Writer, output string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if output == r.last && !strings.HasPrefix(output, "\r") {
+		r.count++
+		return true
+	}
+
+	if r.count > 0 {
+		summary := fmt.Sprintf("(last message repeated %d times)\n", r.count)
+		if r.count == 1 {
+			summary = "(last message repeated 1 time)\n"
+		}
+		fmt.Fprint(r.writer, summary)
+	}
+	r.last, r.writer, r.count = output, writer, 0
+	return false
+}
+
+// writerFor returns the writer configured for a level, falling back to standard output.
+// Under FlushInterval, output written to it is batched.
+func (oh *outputHandler) writerFor(level OutputLevel) io.Writer {
+	writer := oh.destinationFor(level)
+	if oh.config.FlushInterval > 0 && oh.output != nil {
+		return coalescedWriter{c: oh.output, dest: writer, config: oh.config}
+	}
+	return writer
+}
+
+// destinationFor returns the writer configured for a level, falling back to standard output
+func (oh *outputHandler) destinationFor(level OutputLevel) io.Writer {
+	if writer, ok := oh.config.LevelWriters[level]; ok && writer != nil {
+		return writer
+	}
+	return os.Stdout
+}
+
+// applyPrefix inserts the handler's prefixes at the start of every non-empty line,
+// after any carriage return used to redraw progress lines
+func (oh *outputHandler) applyPrefix(output string) string {
+	if len(oh.prefixes) == 0 {
+		return output
+	}
+
+	var prefix strings.Builder
+	for _, p := range oh.prefixes {
+		if oh.config.UseColors && oh.config.UseFormatting {
+			prefix.WriteString(fmt.Sprintf("%s[%s]%s ", ColorPurple, p, ColorReset))
+		} else {
+			prefix.WriteString(fmt.Sprintf("[%s] ", p))
+		}
+	}
+
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "\r") {
+			lines[i] = "\r" + prefix.String() + line[1:]
+		} else {
+			lines[i] = prefix.String() + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WithPrefix returns a handler sharing this handler's configuration that labels every
+// line with "[prefix]". Prefixes compose when WithPrefix is called on a prefixed handler.
+func (oh *outputHandler) WithPrefix(prefix string) OutputHandler {
+	prefixes := make([]string, 0, len(oh.prefixes)+1)
+	prefixes = append(prefixes, oh.prefixes...)
+	prefixes = append(prefixes, prefix)
+	return &outputHandler{config: oh.config, prefixes: prefixes, repeats: oh.repeats, progress: oh.progress, groups: oh.groups, errs: oh.errs, output: oh.output, stages: oh.stages, spinners: oh.spinners}
 }
 
 // Implementation of OutputHandler interface methods
@@ -126,18 +425,186 @@ func (oh *outputHandler) PrintHeader(message string) {
 	oh.PrintWithLevel(LevelHeader, message)
 }
 
+// PrintHeaderWithSubtitle prints a header followed by its subtitle on the next line,
+// indented and dimmed when formatting and colors are enabled
+func (oh *outputHandler) PrintHeaderWithSubtitle(title, subtitle string) {
+	if oh.config.DisableOutput || oh.isQuieted(LevelHeader) {
+		return
+	}
+
+	if oh.config.githubActions() {
+		oh.printWorkflowCommand(LevelHeader, title)
+		oh.write(LevelHeader, subtitle+"\n")
+		return
+	}
+	if oh.config.accessible() {
+		oh.write(LevelHeader, oh.FormatMessage(LevelHeader, title)+subtitle+"\n")
+		return
+	}
+	if !oh.IsSupported() {
+		oh.write(LevelHeader, fmt.Sprintf("%s\n%s\n", title, subtitle))
+		return
+	}
+
+	header := oh.FormatMessage(LevelHeader, title)
+	switch {
+	case oh.config.UseFormatting && oh.config.UseColors:
+		subtitle = fmt.Sprintf("  %s%s%s", ColorDim, subtitle, ColorReset)
+	case oh.config.UseFormatting:
+		subtitle = "  " + subtitle
+	}
+	oh.write(LevelHeader, fmt.Sprintf("%s%s\n", header, subtitle))
+}
+
+// PrintStage prints the start of a stage, numbered such as "[2/5] build" under NumberStages
 func (oh *outputHandler) PrintStage(message string) {
-	oh.PrintWithLevel(LevelStage, message)
+	oh.PrintWithLevel(LevelStage, oh.numberStage(message))
 }
 
+// PrintSuccess prints a success message, ringing the bell afterwards under BellOnDone
 func (oh *outputHandler) PrintSuccess(message string) {
 	oh.PrintWithLevel(LevelSuccess, message)
+	oh.bellOnDone()
+}
+
+// PrintSuccessWithDuration prints a success message followed by a dimmed duration, such as "(1.2s)"
+func (oh *outputHandler) PrintSuccessWithDuration(message string, d time.Duration) {
+	if oh.config.DisableOutput || oh.isQuieted(LevelSuccess) {
+		return
+	}
+
+	formatted := strings.TrimSuffix(oh.FormatMessage(LevelSuccess, message), "\n")
+	oh.write(LevelSuccess, fmt.Sprintf("%s %s\n", formatted, oh.durationSuffix(d)))
+	oh.bellOnDone()
+}
+
+// durationSuffix formats a duration as "(1.2s)", dimmed when colors and formatting are enabled
+func (oh *outputHandler) durationSuffix(d time.Duration) string {
+	duration := fmt.Sprintf("(%s)", formatDuration(d))
+	if oh.config.UseColors && oh.config.UseFormatting {
+		duration = fmt.Sprintf("%s%s%s", ColorDim, duration, ColorReset)
+	}
+	return duration
+}
+
+// formatDuration formats a duration for display: milliseconds below a second,
+// tenths of a second below a minute, and whole seconds beyond that
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return d.Round(time.Second).String()
+	}
 }
 
 func (oh *outputHandler) PrintError(format string, args ...interface{}) {
 	oh.PrintWithLevel(LevelError, format, args...)
 }
 
+// PrintErrors prints several errors as a grouped list under a header such as
+// "3 errors occurred:". Errors joined with errors.Join are split into their parts and nil
+// errors are skipped, so a single error prints like PrintError and none prints nothing.
+// Each error counts towards ErrorCount.
+func (oh *outputHandler) PrintErrors(errs ...error) {
+	errs = splitErrors(errs)
+	oh.errs.add(len(errs))
+	if oh.config.githubActions() {
+		// Each error becomes its own annotation, located when it is a SourceError
+		if oh.config.DisableOutput {
+			return
+		}
+		for _, err := range errs {
+			message := oh.commandPrefix() + oh.tidyMessage(annotationMessage(err))
+			oh.writeCommand(LevelError, workflowCommand("error", annotationProperties(err), message))
+		}
+		return
+	}
+
+	switch len(errs) {
+	case 0:
+		return
+	case 1:
+		oh.printMessage(LevelError, errs[0].Error())
+		return
+	}
+
+	oh.printMessage(LevelError, fmt.Sprintf("%d errors occurred:", len(errs)))
+	if oh.config.DisableOutput {
+		return
+	}
+	for _, err := range errs {
+		item := "  - " + strings.ReplaceAll(oh.tidyMessage(err.Error()), "\n", "\n    ")
+		if oh.config.UseColors && oh.IsSupported() {
+			item = fmt.Sprintf("%s%s%s", oh.config.levelStyle(LevelError).Color, item, ColorReset)
+		}
+		oh.write(LevelError, item+"\n")
+	}
+}
+
+// PrintCommandOutput prints the output of a command under a stage-styled label, each
+// non-empty line indented by two spaces and dimmed, followed by a blank line. Empty output is
+// shown as "(no output)".
+func (oh *outputHandler) PrintCommandOutput(label, output string) {
+	oh.PrintStage(label)
+	if oh.config.DisableOutput || oh.isQuieted(LevelStage) {
+		return
+	}
+
+	lines := []string{"(no output)"}
+	if trimmed := strings.TrimRight(output, "\r\n"); trimmed != "" {
+		lines = strings.Split(trimmed, "\n")
+	}
+
+	var block strings.Builder
+	for _, line := range lines {
+		if line = strings.TrimSuffix(line, "\r"); line == "" {
+			block.WriteString("\n")
+			continue
+		}
+		line = "  " + line
+		if oh.config.UseColors && oh.IsSupported() {
+			line = fmt.Sprintf("%s%s%s", ColorDim, line, ColorReset)
+		}
+		block.WriteString(line + "\n")
+	}
+	block.WriteString("\n")
+
+	// Written at once so repeated lines are not taken for repeated messages
+	oh.write(LevelStage, block.String())
+}
+
+// LogTree prints a tree, labelled with its root, as a single multi-line message at the
+// given level, so it is filtered and routed like the level's other messages. The tree
+// is rendered with the BuildOptions in opts and left uncolored when colors are disabled.
+func (oh *outputHandler) LogTree(level OutputLevel, tree *TreeNode, opts ...BuildOption) {
+	if oh.config.DisableOutput || oh.isQuieted(level) || tree == nil {
+		return
+	}
+
+	rendered := renderTreeString(tree, opts)
+	if !oh.config.UseColors {
+		rendered = stripANSI(rendered)
+	}
+	oh.PrintWithLevel(level, "%s", rendered)
+}
+
+// splitErrors flattens errors joined with errors.Join into a list of their parts,
+// dropping nil errors
+func splitErrors(errs []error) []error {
+	var flattened []error
+	for _, err := range errs {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			flattened = append(flattened, splitErrors(joined.Unwrap())...)
+		} else if err != nil {
+			flattened = append(flattened, err)
+		}
+	}
+	return flattened
+}
+
 func (oh *outputHandler) PrintWarning(format string, args ...interface{}) {
 	oh.PrintWithLevel(LevelWarning, format, args...)
 }
@@ -147,74 +614,161 @@ func (oh *outputHandler) PrintInfo(format string, args ...interface{}) {
 }
 
 func (oh *outputHandler) PrintAlreadyAvailable(format string, args ...interface{}) {
+	oh.PrintWithLevel(LevelAvailable, format, args...)
+}
+
+// PrintColored prints a one-off line wrapped in color, one of the Color* constants,
+// without a level prefix. The line is plain when colors are disabled.
+func (oh *outputHandler) PrintColored(color string, format string, args ...interface{}) {
 	if oh.config.DisableOutput {
 		return
 	}
 
-	message := fmt.Sprintf(format, args...)
-
-	if oh.config.UseColors {
-		prefix := "[AVAILABLE] "
-		if oh.config.UseEmojis && oh.config.UseFormatting {
-			prefix = "💙 "
-		}
-
-		if oh.config.ColorizeLevelOnly {
-			coloredPrefix := fmt.Sprintf("%s%s%s%s", ColorBold, ColorBlue, prefix, ColorReset)
-			fmt.Printf("%s%s\n", coloredPrefix, message)
-		} else {
-			fmt.Printf("%s%s%s%s%s\n", ColorBold, ColorBlue, prefix, message, ColorReset)
-		}
-		return
+	message := oh.tidyMessage(fmt.Sprintf(format, args...))
+	if oh.config.UseColors && oh.IsSupported() {
+		message = fmt.Sprintf("%s%s%s", color, message, ColorReset)
 	}
-
-	fmt.Printf("[AVAILABLE] %s\n", message)
+	oh.write(LevelInfo, message+"\n")
 }
 
 func (oh *outputHandler) PrintProgress(current, total int, message string) {
-	if oh.config.DisableOutput {
+	if oh.config.DisableOutput || oh.config.QuietMode {
+		return
+	}
+	if oh.config.MinUpdateInterval > 0 && oh.progress != nil && oh.progress.throttled(current >= total, oh.config.MinUpdateInterval) {
+		return
+	}
+	if oh.config.accessible() {
+		if oh.progress == nil || oh.progress.announce(current, total) {
+			oh.write(LevelInfo, formatAccessibleProgress(current, total, message))
+		}
 		return
 	}
 
-	percentage := float64(current) / float64(total) * 100
+	percentage := oh.formatPercentage(current, total)
 
 	if oh.config.UseColors && oh.config.UseFormatting {
-		progressPrefix := fmt.Sprintf("[%d/%d] %.0f%% - ", current, total, percentage)
+		progressPrefix := fmt.Sprintf("[%d/%d] %s - ", current, total, percentage)
 		if oh.config.ColorizeLevelOnly {
 			coloredPrefix := fmt.Sprintf("%s%s%s%s", ColorBold, ColorCyan, progressPrefix, ColorReset)
-			fmt.Printf("\r%s%s\n", coloredPrefix, message)
+			oh.write(LevelInfo, fmt.Sprintf("\r%s%s\n", coloredPrefix, message))
 		} else {
-			fmt.Printf("\r%s%s%s%s%s\n", ColorBold, ColorCyan, progressPrefix, message, ColorReset)
+			oh.write(LevelInfo, fmt.Sprintf("\r%s%s%s%s%s\n", ColorBold, ColorCyan, progressPrefix, message, ColorReset))
 		}
 	} else {
-		fmt.Printf("\r[%d/%d] %.0f%% - %s\n", current, total, percentage, message)
+		oh.write(LevelInfo, fmt.Sprintf("\r[%d/%d] %s - %s\n", current, total, percentage, message))
+	}
+}
+
+// throttled reports whether a progress update arrives within interval of the last printed
+// one and should be skipped. Final updates are never skipped and end the sequence, so the
+// next update starts a new one.
+func (p *progressState) throttled(final bool, interval time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := nowFunc()
+	if final {
+		p.last = time.Time{}
+		return false
+	}
+	if !p.last.IsZero() && now.Sub(p.last) < interval {
+		return true
 	}
+	p.last = now
+	return false
 }
 
+// formatPercentage formats progress as a percentage with ProgressPrecision decimals
+func (oh *outputHandler) formatPercentage(current, total int) string {
+	percentage := float64(current) / float64(total) * 100
+	return fmt.Sprintf("%.*f%%", max(oh.config.ProgressPrecision, 0), percentage)
+}
+
+// Confirm prompts for a yes/no answer, treating read failures as "no". When the input is
+// not interactive, such as stdin redirected in CI, it returns the default "no" without
+// reading and prints an info line noting the assumption.
 func (oh *outputHandler) Confirm(message string) bool {
-	if oh.config.DisableOutput {
+	if !oh.config.DisableOutput && !interactive(oh.reader()) {
+		oh.printMessage(LevelInfo, fmt.Sprintf("%s: assuming no, as input is not a terminal", strings.TrimRight(message, "? ")))
 		return false
 	}
+	confirmed, _ := oh.ConfirmE(message)
+	return confirmed
+}
 
-	if oh.config.UseColors && oh.config.UseFormatting {
+// ConfirmE prompts for a yes/no answer like Confirm, but returns an error when the answer
+// cannot be read, such as when stdin is closed, so callers can tell it apart from "no".
+// Unlike Confirm, it reads input that is not a terminal.
+func (oh *outputHandler) ConfirmE(message string) (bool, error) {
+	if oh.config.DisableOutput {
+		return false, nil
+	}
+
+	if oh.config.accessible() {
+		oh.write(LevelInfo, accessibleQuestion(message))
+	} else if oh.config.UseColors && oh.config.UseFormatting {
 		if oh.config.ColorizeLevelOnly {
 			coloredPrefix := fmt.Sprintf("%s%s?%s", ColorBold, ColorYellow, ColorReset)
-			fmt.Printf("%s %s (y/N): ", coloredPrefix, message)
+			oh.write(LevelInfo, fmt.Sprintf("%s %s (y/N): ", coloredPrefix, message))
 		} else {
-			fmt.Printf("%s%s? %s (y/N): %s", ColorBold, ColorYellow, message, ColorReset)
+			oh.write(LevelInfo, fmt.Sprintf("%s%s? %s (y/N): %s", ColorBold, ColorYellow, message, ColorReset))
 		}
 	} else {
-		fmt.Printf("? %s (y/N): ", message)
+		oh.write(LevelInfo, fmt.Sprintf("? %s (y/N): ", message))
 	}
+	oh.Flush()
 
-	var response string
-	fmt.Scanln(&response)
+	response, err := readLine(oh.reader())
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
 
-	switch response {
+	switch strings.TrimSpace(response) {
 	case "y", "Y", "yes", "Yes":
-		return true
+		return true, nil
 	default:
-		return false
+		return false, nil
+	}
+}
+
+// reader returns the configured input reader, falling back to standard input
+func (oh *outputHandler) reader() io.Reader {
+	if oh.config.Reader != nil {
+		return oh.config.Reader
+	}
+	return os.Stdin
+}
+
+// interactive reports whether Confirm can ask r for an answer: r is a terminal, or a
+// reader other than a file, such as scripted answers in OutputConfig.Reader
+func interactive(r io.Reader) bool {
+	if file, ok := r.(*os.File); ok {
+		return isTerminal(file)
+	}
+	return true
+}
+
+// readLine reads a single line from r one byte at a time, so no input beyond the line
+// is consumed. A final line without a newline is returned without error; io.EOF is
+// returned only when nothing was read.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, buf[0])
+		}
+		if err == io.EOF && len(line) > 0 {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
+		}
 	}
 }
 
@@ -227,6 +781,11 @@ func (oh *outputHandler) Disable() {
 	oh.config.DisableOutput = true
 }
 
+// Config returns the handler's output configuration
+func (oh *outputHandler) Config() *OutputConfig {
+	return oh.config
+}
+
 // Global output handler instance
 var globalOutputHandler OutputHandler = NewDefaultOutputHandler()
 
@@ -2,12 +2,24 @@ package palantir
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
+// TestMain runs the suite as outside of CI, so handlers left on CIModeAuto write terminal
+// output even when the suite itself runs on GitHub Actions. Tests of Actions output set
+// GITHUB_ACTIONS or CIModeGitHubActions themselves.
+func TestMain(m *testing.M) {
+	os.Unsetenv("GITHUB_ACTIONS")
+	os.Exit(m.Run())
+}
+
 func captureOutput(fn func()) string {
 	old := os.Stdout
 	r, w, _ := os.Pipe()
@@ -31,6 +43,14 @@ func setupSupportedTerminal(t *testing.T) {
 	})
 }
 
+// setupInteractiveStdin makes isTerminal report the current standard input as a terminal,
+// so answers piped to it reach Confirm
+func setupInteractiveStdin(t *testing.T) {
+	original := isTerminal
+	isTerminal = func(w io.Writer) bool { return w == io.Writer(os.Stdin) || original(w) }
+	t.Cleanup(func() { isTerminal = original })
+}
+
 func setupUnsupportedTerminal(t *testing.T) {
 	oldTerm := os.Getenv("TERM")
 	os.Setenv("TERM", "dumb")
@@ -51,48 +71,52 @@ func TestFormatMessage_AllConfigurations(t *testing.T) {
 			"WithAllFeatures",
 			&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true, DisableOutput: false},
 			map[OutputLevel]string{
-				LevelHeader:  fmt.Sprintf("\n%s%s=== Test Header ===%s\n", ColorBold, ColorCyan, ColorReset),
-				LevelStage:   fmt.Sprintf("%s%s🔧 Test Stage%s\n", ColorBold, ColorBlue, ColorReset),
-				LevelSuccess: fmt.Sprintf("%s%s✅ Test Success%s\n", ColorBold, ColorGreen, ColorReset),
-				LevelError:   fmt.Sprintf("%s%s❌ Test Error%s\n", ColorBold, ColorRed, ColorReset),
-				LevelWarning: fmt.Sprintf("%s%s⚠️  Test Warning%s\n", ColorBold, ColorYellow, ColorReset),
-				LevelInfo:    fmt.Sprintf("%s%sTest Info%s\n", ColorBold, "", ColorReset),
+				LevelHeader:    fmt.Sprintf("\n%s%s=== Test Header ===%s\n", ColorBold, ColorCyan, ColorReset),
+				LevelStage:     fmt.Sprintf("%s%s🔧 Test Stage%s\n", ColorBold, ColorBlue, ColorReset),
+				LevelSuccess:   fmt.Sprintf("%s%s✅ Test Success%s\n", ColorBold, ColorGreen, ColorReset),
+				LevelError:     fmt.Sprintf("%s%s❌ Test Error%s\n", ColorBold, ColorRed, ColorReset),
+				LevelWarning:   fmt.Sprintf("%s%s⚠️  Test Warning%s\n", ColorBold, ColorYellow, ColorReset),
+				LevelInfo:      "Test Info\n",
+				LevelAvailable: fmt.Sprintf("%s%s💙 Test Available%s\n", ColorBold, ColorBlue, ColorReset),
 			},
 		},
 		{
 			"WithLevelOnlyColours",
 			&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true, DisableOutput: false, ColorizeLevelOnly: true},
 			map[OutputLevel]string{
-				LevelHeader:  fmt.Sprintf("\n%s%s=== Test Header ===%s\n", ColorBold, ColorCyan, ColorReset),
-				LevelStage:   fmt.Sprintf("%s%s🔧 %sTest Stage\n", ColorBold, ColorBlue, ColorReset),
-				LevelSuccess: fmt.Sprintf("%s%s✅ %sTest Success\n", ColorBold, ColorGreen, ColorReset),
-				LevelError:   fmt.Sprintf("%s%s❌ %sTest Error\n", ColorBold, ColorRed, ColorReset),
-				LevelWarning: fmt.Sprintf("%s%s⚠️  %sTest Warning\n", ColorBold, ColorYellow, ColorReset),
-				LevelInfo:    fmt.Sprintf("%sTest Info%s\n", ColorBold, ColorReset),
+				LevelHeader:    fmt.Sprintf("\n%s%s=== Test Header ===%s\n", ColorBold, ColorCyan, ColorReset),
+				LevelStage:     fmt.Sprintf("%s%s🔧 %sTest Stage\n", ColorBold, ColorBlue, ColorReset),
+				LevelSuccess:   fmt.Sprintf("%s%s✅ %sTest Success\n", ColorBold, ColorGreen, ColorReset),
+				LevelError:     fmt.Sprintf("%s%s❌ %sTest Error\n", ColorBold, ColorRed, ColorReset),
+				LevelWarning:   fmt.Sprintf("%s%s⚠️  %sTest Warning\n", ColorBold, ColorYellow, ColorReset),
+				LevelInfo:      "Test Info\n",
+				LevelAvailable: fmt.Sprintf("%s%s💙 %sTest Available\n", ColorBold, ColorBlue, ColorReset),
 			},
 		},
 		{
 			"WithColorsOnly",
 			&OutputConfig{UseColors: true, UseEmojis: false, UseFormatting: true, DisableOutput: false},
 			map[OutputLevel]string{
-				LevelHeader:  fmt.Sprintf("\n%s%s=== Test Header ===%s\n", ColorBold, ColorCyan, ColorReset),
-				LevelStage:   fmt.Sprintf("%s%s[STAGE] Test Stage%s\n", ColorBold, ColorBlue, ColorReset),
-				LevelSuccess: fmt.Sprintf("%s%s[SUCCESS] Test Success%s\n", ColorBold, ColorGreen, ColorReset),
-				LevelError:   fmt.Sprintf("%s%s[ERROR] Test Error%s\n", ColorBold, ColorRed, ColorReset),
-				LevelWarning: fmt.Sprintf("%s%s[WARNING] Test Warning%s\n", ColorBold, ColorYellow, ColorReset),
-				LevelInfo:    fmt.Sprintf("%s%sTest Info%s\n", ColorBold, "", ColorReset),
+				LevelHeader:    fmt.Sprintf("\n%s%s=== Test Header ===%s\n", ColorBold, ColorCyan, ColorReset),
+				LevelStage:     fmt.Sprintf("%s%s[STAGE] Test Stage%s\n", ColorBold, ColorBlue, ColorReset),
+				LevelSuccess:   fmt.Sprintf("%s%s[SUCCESS] Test Success%s\n", ColorBold, ColorGreen, ColorReset),
+				LevelError:     fmt.Sprintf("%s%s[ERROR] Test Error%s\n", ColorBold, ColorRed, ColorReset),
+				LevelWarning:   fmt.Sprintf("%s%s[WARNING] Test Warning%s\n", ColorBold, ColorYellow, ColorReset),
+				LevelInfo:      "Test Info\n",
+				LevelAvailable: fmt.Sprintf("%s%s[AVAILABLE] Test Available%s\n", ColorBold, ColorBlue, ColorReset),
 			},
 		},
 		{
 			"WithoutColors",
 			&OutputConfig{UseColors: false, UseEmojis: false, UseFormatting: false, DisableOutput: false},
 			map[OutputLevel]string{
-				LevelHeader:  "\n=== Test Header ===\n",
-				LevelStage:   "[STAGE] Test Stage\n",
-				LevelSuccess: "[SUCCESS] Test Success\n",
-				LevelError:   "[ERROR] Test Error\n",
-				LevelWarning: "[WARNING] Test Warning\n",
-				LevelInfo:    "Test Info\n",
+				LevelHeader:    "\n=== Test Header ===\n",
+				LevelStage:     "[STAGE] Test Stage\n",
+				LevelSuccess:   "[SUCCESS] Test Success\n",
+				LevelError:     "[ERROR] Test Error\n",
+				LevelWarning:   "[WARNING] Test Warning\n",
+				LevelInfo:      "Test Info\n",
+				LevelAvailable: "[AVAILABLE] Test Available\n",
 			},
 		},
 	}
@@ -118,12 +142,13 @@ func TestFormatMessage_AllConfigurations(t *testing.T) {
 
 // Helper map for level names
 var levelNames = map[OutputLevel]string{
-	LevelHeader:  "Header",
-	LevelStage:   "Stage",
-	LevelSuccess: "Success",
-	LevelError:   "Error",
-	LevelWarning: "Warning",
-	LevelInfo:    "Info",
+	LevelHeader:    "Header",
+	LevelStage:     "Stage",
+	LevelSuccess:   "Success",
+	LevelError:     "Error",
+	LevelWarning:   "Warning",
+	LevelInfo:      "Info",
+	LevelAvailable: "Available",
 }
 
 // generateExpectedOutput is a helper function to generate expected output for FormatMessage
@@ -158,7 +183,7 @@ func generateExpectedOutput(level OutputLevel, message string, config *OutputCon
 		}
 	}
 
-	if config.UseColors && config.UseFormatting {
+	if config.UseColors && config.UseFormatting && color != "" {
 		if config.ColorizeLevelOnly && color != "" && prefix != "" {
 			coloredPrefix := fmt.Sprintf("%s%s%s%s", ColorBold, color, prefix, ColorReset)
 			return fmt.Sprintf("%s%s\n", coloredPrefix, message)
@@ -272,7 +297,7 @@ func TestPrintMethods_AllVariations(t *testing.T) {
 			handler.PrintInfo,
 			"Info: %s",
 			[]interface{}{"test info"},
-			fmt.Sprintf("%s%sInfo: test info%s\n", ColorBold, "", ColorReset),
+			"Info: test info\n",
 		},
 	}
 
@@ -493,6 +518,7 @@ func TestGlobalHandler(t *testing.T) {
 
 func TestConfirm_AllScenarios(t *testing.T) {
 	setupSupportedTerminal(t)
+	setupInteractiveStdin(t)
 
 	handler := NewOutputHandler(&OutputConfig{
 		UseColors:     true,
@@ -555,6 +581,7 @@ func TestConfirm_AllScenarios(t *testing.T) {
 
 func TestConfirm_LevelOnlyColours(t *testing.T) {
 	setupSupportedTerminal(t)
+	setupInteractiveStdin(t)
 
 	handler := NewOutputHandler(&OutputConfig{
 		UseColors:         true,
@@ -705,3 +732,895 @@ func TestOutputFormatConsistency(t *testing.T) {
 		t.Error("Output should end with a newline character")
 	}
 }
+
+func TestWithPrefix(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	handler := NewOutputHandler(&OutputConfig{UseColors: false})
+	auth := handler.WithPrefix("auth")
+
+	output := captureOutput(func() {
+		auth.PrintInfo("Token refreshed")
+		auth.PrintError("Login failed for %s", "bob")
+		auth.PrintStage("Validating")
+		auth.PrintAlreadyAvailable("Session cache")
+		auth.PrintProgress(1, 2, "Syncing")
+		auth.PrintHeader("Auth")
+	})
+
+	expected := "[auth] Token refreshed\n" +
+		"[auth] [ERROR] Login failed for bob\n" +
+		"[auth] [STAGE] Validating\n" +
+		"[auth] [AVAILABLE] Session cache\n" +
+		"\r[auth] [1/2] 50% - Syncing\n" +
+		"\n[auth] === Auth ===\n"
+	if output != expected {
+		t.Errorf("Prefixed output = %q, want %q", output, expected)
+	}
+
+	// The parent handler is unaffected
+	output = captureOutput(func() {
+		handler.PrintInfo("Unprefixed")
+	})
+	if output != "Unprefixed\n" {
+		t.Errorf("Parent handler output = %q, want %q", output, "Unprefixed\n")
+	}
+}
+
+func TestWithPrefix_Nested(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	handler := NewOutputHandler(&OutputConfig{UseColors: false})
+	nested := handler.WithPrefix("db").WithPrefix("migrations")
+
+	output := captureOutput(func() {
+		nested.PrintWarning("Slow migration")
+		nested.PrintSuccess("Applied")
+	})
+
+	expected := "[db] [migrations] [WARNING] Slow migration\n" +
+		"[db] [migrations] [SUCCESS] Applied\n"
+	if output != expected {
+		t.Errorf("Nested prefixed output = %q, want %q", output, expected)
+	}
+}
+
+func TestWithPrefix_Colored(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	handler := NewOutputHandler(&OutputConfig{UseColors: true, UseFormatting: true})
+	output := captureOutput(func() {
+		handler.WithPrefix("api").PrintInfo("Started")
+	})
+
+	coloredPrefix := fmt.Sprintf("%s[api]%s ", ColorPurple, ColorReset)
+	if !strings.HasPrefix(output, coloredPrefix) {
+		t.Errorf("Expected output to start with colored prefix %q, got %q", coloredPrefix, output)
+	}
+}
+
+func TestWithPrefix_Confirm(t *testing.T) {
+	setupSupportedTerminal(t)
+	setupInteractiveStdin(t)
+
+	handler := NewOutputHandler(&OutputConfig{UseColors: false}).WithPrefix("deploy")
+
+	oldStdin := os.Stdin
+	defer func() {
+		os.Stdin = oldStdin
+	}()
+	r, w, _ := os.Pipe()
+	os.Stdin = r
+	go func() {
+		w.WriteString("y\n")
+		w.Close()
+	}()
+
+	var result bool
+	output := captureOutput(func() {
+		result = handler.Confirm("Continue?")
+	})
+
+	if !result {
+		t.Error("Confirm() = false, want true")
+	}
+	if output != "[deploy] ? Continue? (y/N): " {
+		t.Errorf("Confirm() prompt = %q, want %q", output, "[deploy] ? Continue? (y/N): ")
+	}
+}
+
+func TestPrintHeaderWithSubtitle(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	tests := []struct {
+		name     string
+		config   *OutputConfig
+		expected string
+	}{
+		{
+			"Colors and formatting",
+			&OutputConfig{UseColors: true, UseFormatting: true},
+			fmt.Sprintf("\n%s%s=== Deploy ===%s\n  %sstaging environment%s\n", ColorBold, ColorCyan, ColorReset, ColorDim, ColorReset),
+		},
+		{
+			"Formatting without colors",
+			&OutputConfig{UseColors: false, UseFormatting: true},
+			"\n=== Deploy ===\n  staging environment\n",
+		},
+		{
+			"Formatting off",
+			&OutputConfig{UseColors: false, UseFormatting: false},
+			"\n=== Deploy ===\nstaging environment\n",
+		},
+		{
+			"Output disabled",
+			&OutputConfig{UseColors: true, UseFormatting: true, DisableOutput: true},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewOutputHandler(tt.config)
+			output := captureOutput(func() {
+				handler.PrintHeaderWithSubtitle("Deploy", "staging environment")
+			})
+			if output != tt.expected {
+				t.Errorf("PrintHeaderWithSubtitle() = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrintHeaderWithSubtitleUnsupportedTerminal(t *testing.T) {
+	setupUnsupportedTerminal(t)
+
+	handler := NewOutputHandler(&OutputConfig{UseColors: true, UseFormatting: true})
+	output := captureOutput(func() {
+		handler.PrintHeaderWithSubtitle("Deploy", "staging environment")
+	})
+
+	if expected := "Deploy\nstaging environment\n"; output != expected {
+		t.Errorf("PrintHeaderWithSubtitle() = %q, want %q", output, expected)
+	}
+}
+
+func TestLevelWriters(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	var errors, warnings, infos bytes.Buffer
+	handler := NewOutputHandler(&OutputConfig{
+		LevelWriters: map[OutputLevel]io.Writer{
+			LevelError:   &errors,
+			LevelWarning: &warnings,
+			LevelInfo:    &infos,
+		},
+	})
+
+	stdout := captureOutput(func() {
+		handler.PrintError("disk full")
+		handler.PrintWarning("low memory")
+		handler.PrintInfo("starting")
+		handler.PrintSuccess("done")
+	})
+
+	if got := errors.String(); got != "[ERROR] disk full\n" {
+		t.Errorf("Error writer got %q", got)
+	}
+	if got := warnings.String(); got != "[WARNING] low memory\n" {
+		t.Errorf("Warning writer got %q", got)
+	}
+	if got := infos.String(); got != "starting\n" {
+		t.Errorf("Info writer got %q", got)
+	}
+	if stdout != "[SUCCESS] done\n" {
+		t.Errorf("Expected levels without a writer to go to stdout, got %q", stdout)
+	}
+}
+
+func TestPlainInfo(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	handler := NewOutputHandler(&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true})
+	output := captureOutput(func() { handler.PrintInfo("starting") })
+	if output != "starting\n" {
+		t.Errorf("Expected info without a color to be printed plain, got %q", output)
+	}
+
+	prefixed := NewPrefixedHandler(handler, "api")
+	output = captureOutput(func() { prefixed.PrintInfo("starting") })
+	if strings.Contains(output, ColorBold) {
+		t.Errorf("Expected a prefixed info message not to restore bold, got %q", output)
+	}
+}
+
+func TestLabeledInfo(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	style := LevelStyle{Prefix: "[INFO] ", Emoji: "ℹ️  ", Color: ColorCyan}
+	tests := []struct {
+		name     string
+		config   *OutputConfig
+		expected string
+	}{
+		{
+			"WithAllFeatures",
+			NewConfigBuilder().WithLevelStyle(LevelInfo, style).Build(),
+			fmt.Sprintf("%s%sℹ️  starting%s\n", ColorBold, ColorCyan, ColorReset),
+		},
+		{
+			"WithLevelOnlyColours",
+			NewConfigBuilder().WithLevelStyle(LevelInfo, style).WithColorizeLevelOnly().Build(),
+			fmt.Sprintf("%s%sℹ️  %sstarting\n", ColorBold, ColorCyan, ColorReset),
+		},
+		{
+			"WithoutColors",
+			&OutputConfig{UseFormatting: true, LevelStyles: map[OutputLevel]LevelStyle{LevelInfo: style}},
+			"[INFO] starting\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewOutputHandler(tt.config)
+			output := captureOutput(func() { handler.PrintInfo("starting") })
+			if output != tt.expected {
+				t.Errorf("PrintInfo() = %q, want %q", output, tt.expected)
+			}
+
+			output = captureOutput(func() { handler.PrintSuccess("done") })
+			if !strings.Contains(output, "done") || strings.Contains(output, "[INFO]") || strings.Contains(output, "ℹ️") {
+				t.Errorf("Expected other levels to keep their default style, got %q", output)
+			}
+		})
+	}
+}
+
+func TestTrimTrailingSpaceAndCollapseBlankLines(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	message := "first line   \n\n\n\nsecond line\t\n  \n\nthird"
+
+	tests := []struct {
+		name     string
+		config   *OutputConfig
+		expected string
+	}{
+		{"Disabled", &OutputConfig{}, "[WARNING] " + message + "\n"},
+		{"Trim trailing space", &OutputConfig{TrimTrailingSpace: true}, "[WARNING] first line\n\n\n\nsecond line\n\n\nthird\n"},
+		{"Collapse blank lines", &OutputConfig{CollapseBlankLines: true}, "[WARNING] first line   \n\nsecond line\t\n  \nthird\n"},
+		{"Both", &OutputConfig{TrimTrailingSpace: true, CollapseBlankLines: true}, "[WARNING] first line\n\nsecond line\n\nthird\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewOutputHandler(tt.config)
+			output := captureOutput(func() {
+				handler.PrintWarning(message)
+			})
+			if output != tt.expected {
+				t.Errorf("PrintWarning() = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTidyMessageExemptsHeaders(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	handler := NewOutputHandler(&OutputConfig{TrimTrailingSpace: true, CollapseBlankLines: true})
+	output := captureOutput(func() {
+		handler.PrintHeader("Title  ")
+	})
+
+	if expected := "\n=== Title   ===\n"; output != expected {
+		t.Errorf("PrintHeader() = %q, want %q", output, expected)
+	}
+}
+
+func TestPrintSuccessWithDuration(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	tests := []struct {
+		name     string
+		config   *OutputConfig
+		duration time.Duration
+		expected string
+	}{
+		{
+			"Sub-second without colors",
+			&OutputConfig{UseColors: false, UseFormatting: true},
+			250 * time.Millisecond,
+			"[SUCCESS] Built project (250ms)\n",
+		},
+		{
+			"Multi-second without colors",
+			&OutputConfig{UseColors: false, UseFormatting: true},
+			1234 * time.Millisecond,
+			"[SUCCESS] Built project (1.2s)\n",
+		},
+		{
+			"Minutes without colors",
+			&OutputConfig{UseColors: false, UseFormatting: true},
+			90*time.Second + 400*time.Millisecond,
+			"[SUCCESS] Built project (1m30s)\n",
+		},
+		{
+			"Colors",
+			&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true},
+			1500 * time.Millisecond,
+			fmt.Sprintf("%s%s✅ Built project%s %s(1.5s)%s\n", ColorBold, ColorGreen, ColorReset, ColorDim, ColorReset),
+		},
+		{
+			"Colorize level only",
+			&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true, ColorizeLevelOnly: true},
+			20 * time.Millisecond,
+			fmt.Sprintf("%s%s✅ %sBuilt project %s(20ms)%s\n", ColorBold, ColorGreen, ColorReset, ColorDim, ColorReset),
+		},
+		{
+			"Output disabled",
+			&OutputConfig{DisableOutput: true},
+			time.Second,
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewOutputHandler(tt.config)
+			output := captureOutput(func() {
+				handler.PrintSuccessWithDuration("Built project", tt.duration)
+			})
+			if output != tt.expected {
+				t.Errorf("PrintSuccessWithDuration() = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfirmE(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	handler := NewOutputHandler(&OutputConfig{UseColors: false})
+
+	tests := []struct {
+		name        string
+		input       string
+		closeStdin  bool
+		expected    bool
+		expectError bool
+	}{
+		{"Yes", "yes\n", false, true, false},
+		{"No", "n\n", false, false, false},
+		{"Empty answer is no", "\n", false, false, false},
+		{"Answer without newline", "y", false, true, false},
+		{"Closed stdin", "", true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldStdin := os.Stdin
+			defer func() {
+				os.Stdin = oldStdin
+			}()
+
+			r, w, _ := os.Pipe()
+			os.Stdin = r
+			w.WriteString(tt.input)
+			w.Close()
+
+			var result bool
+			var err error
+			captureOutput(func() {
+				result, err = handler.ConfirmE("Delete everything?")
+			})
+
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ConfirmE() error = %v, expectError %v", err, tt.expectError)
+			}
+			if result != tt.expected {
+				t.Errorf("ConfirmE() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfirmClosedStdin(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	oldStdin := os.Stdin
+	defer func() {
+		os.Stdin = oldStdin
+	}()
+
+	r, w, _ := os.Pipe()
+	os.Stdin = r
+	w.Close()
+
+	handler := NewOutputHandler(&OutputConfig{UseColors: false})
+	var result bool
+	var err error
+	captureOutput(func() {
+		result, err = handler.ConfirmE("Delete everything?")
+	})
+	if result || err == nil {
+		t.Errorf("ConfirmE() = %v, %v; want false and a read error when stdin is closed", result, err)
+	}
+	captureOutput(func() {
+		result = handler.Confirm("Delete everything?")
+	})
+	if result {
+		t.Error("Confirm() should return false when stdin is closed")
+	}
+}
+
+func TestConfirmNonInteractiveInput(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	w.WriteString("y\n")
+	w.Close()
+
+	var out bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithColors(false).WithReader(r).WithWriter(&out).Build())
+	if handler.Confirm("Delete everything?") {
+		t.Error("Confirm() = true, want the default no")
+	}
+	if expected := "Delete everything: assuming no, as input is not a terminal\n"; out.String() != expected {
+		t.Errorf("Output = %q, want %q", out.String(), expected)
+	}
+
+	// ConfirmE still reads the answer
+	if confirmed, err := handler.ConfirmE("Delete everything?"); !confirmed || err != nil {
+		t.Errorf("ConfirmE() = %v, %v, want the piped yes", confirmed, err)
+	}
+}
+
+func TestLevelAvailableRouting(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	var available bytes.Buffer
+	handler := NewOutputHandler(&OutputConfig{
+		LevelWriters: map[OutputLevel]io.Writer{LevelAvailable: &available},
+	})
+
+	stdout := captureOutput(func() {
+		handler.PrintAlreadyAvailable("Go %s", "1.23")
+	})
+
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+	if got := available.String(); got != "[AVAILABLE] Go 1.23\n" {
+		t.Errorf("Available writer got %q", got)
+	}
+}
+
+func TestSuppressRepeats(t *testing.T) {
+	setupSupportedTerminal(t)
+	handler := NewOutputHandler(&OutputConfig{SuppressRepeats: true})
+
+	output := captureOutput(func() {
+		for i := 0; i < 5; i++ {
+			handler.PrintInfo("waiting for lock")
+		}
+		handler.PrintInfo("lock acquired")
+	})
+
+	expected := "waiting for lock\n(last message repeated 4 times)\nlock acquired\n"
+	if output != expected {
+		t.Errorf("Output = %q, want %q", output, expected)
+	}
+}
+
+func TestSuppressRepeatsDisabledAndProgress(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	output := captureOutput(func() {
+		handler := NewOutputHandler(&OutputConfig{})
+		handler.PrintInfo("tick")
+		handler.PrintInfo("tick")
+	})
+	if strings.Count(output, "tick") != 2 || strings.Contains(output, "repeated") {
+		t.Errorf("Expected repeats to be printed without SuppressRepeats, got %q", output)
+	}
+
+	output = captureOutput(func() {
+		handler := NewOutputHandler(&OutputConfig{SuppressRepeats: true})
+		handler.PrintProgress(1, 2, "copying")
+		handler.PrintProgress(1, 2, "copying")
+	})
+	if strings.Count(output, "copying") != 2 || strings.Contains(output, "repeated") {
+		t.Errorf("Expected progress lines to be exempt, got %q", output)
+	}
+}
+
+func TestPrintColored(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	tests := []struct {
+		name     string
+		config   *OutputConfig
+		expected string
+	}{
+		{"Colored", &OutputConfig{UseColors: true}, ColorPurple + "cache warmed in 3s" + ColorReset + "\n"},
+		{"No colors", &OutputConfig{UseColors: false}, "cache warmed in 3s\n"},
+		{"Disabled", &OutputConfig{UseColors: true, DisableOutput: true}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewOutputHandler(tt.config)
+			output := captureOutput(func() {
+				handler.PrintColored(ColorPurple, "cache warmed in %ds", 3)
+			})
+			if output != tt.expected {
+				t.Errorf("PrintColored() = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrintColoredUnsupportedTerminal(t *testing.T) {
+	setupUnsupportedTerminal(t)
+	handler := NewOutputHandler(&OutputConfig{UseColors: true})
+
+	output := captureOutput(func() {
+		handler.PrintColored(ColorRed, "plain")
+	})
+	if output != "plain\n" {
+		t.Errorf("PrintColored() = %q, want plain text on unsupported terminals", output)
+	}
+}
+
+func TestPrintProgressPrecision(t *testing.T) {
+	tests := []struct {
+		precision int
+		current   int
+		expected  string
+	}{
+		{0, 1, "\r[1/3] 33% - copying\n"},
+		{0, 2, "\r[2/3] 67% - copying\n"},
+		{1, 1, "\r[1/3] 33.3% - copying\n"},
+		{1, 2, "\r[2/3] 66.7% - copying\n"},
+		{2, 1, "\r[1/3] 33.33% - copying\n"},
+		{2, 2, "\r[2/3] 66.67% - copying\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("precision_%d_%d", tt.precision, tt.current), func(t *testing.T) {
+			handler := NewOutputHandler(&OutputConfig{ProgressPrecision: tt.precision})
+			output := captureOutput(func() {
+				handler.PrintProgress(tt.current, 3, "copying")
+			})
+			if output != tt.expected {
+				t.Errorf("PrintProgress() = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+
+	// Edge cases keep their formatting at any precision
+	handler := NewOutputHandler(&OutputConfig{ProgressPrecision: 1})
+	output := captureOutput(func() {
+		handler.PrintProgress(0, 0, "empty")
+	})
+	if expected := "\r[0/0] NaN% - empty\n"; output != expected {
+		t.Errorf("PrintProgress() = %q, want %q", output, expected)
+	}
+}
+
+// panicWriter is a writer that panics, standing in for a faulty user-supplied writer
+type panicWriter struct{}
+
+func (panicWriter) Write(p []byte) (int, error) {
+	panic("writer exploded")
+}
+
+func TestRecoverPanics(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	handler := NewOutputHandler(&OutputConfig{
+		RecoverPanics: true,
+		LevelWriters:  map[OutputLevel]io.Writer{LevelError: panicWriter{}},
+	})
+	output := captureOutput(func() {
+		handler.PrintError("disk full")
+		handler.PrintInfo("still running")
+	})
+
+	w.Close()
+	os.Stderr = oldStderr
+	var stderr bytes.Buffer
+	stderr.ReadFrom(r)
+
+	if output != "still running\n" {
+		t.Errorf("Expected printing to continue after the panic, got %q", output)
+	}
+	if !strings.Contains(stderr.String(), "recovered from panic while printing: writer exploded") {
+		t.Errorf("Expected the panic to be reported on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRecoverPanicsDisabled(t *testing.T) {
+	handler := NewOutputHandler(&OutputConfig{
+		LevelWriters: map[OutputLevel]io.Writer{LevelError: panicWriter{}},
+	})
+
+	defer func() {
+		if r := recover(); r != "writer exploded" {
+			t.Errorf("Expected the writer's panic to propagate, got %v", r)
+		}
+	}()
+	handler.PrintError("disk full")
+}
+
+func TestConfirmReader(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	handler := NewOutputHandler(&OutputConfig{Reader: strings.NewReader("y\nno\nyes")})
+
+	var answers []bool
+	output := captureOutput(func() {
+		for i := 0; i < 3; i++ {
+			answers = append(answers, handler.Confirm("Continue?"))
+		}
+	})
+
+	if !reflect.DeepEqual(answers, []bool{true, false, true}) {
+		t.Errorf("Confirm() answers = %v, want [true false true]", answers)
+	}
+	if strings.Count(output, "? Continue? (y/N): ") != 3 {
+		t.Errorf("Expected three prompts, got %q", output)
+	}
+
+	if _, err := handler.ConfirmE("Again?"); err == nil {
+		t.Error("Expected an error once the reader is exhausted")
+	}
+}
+
+// stubNow replaces the clock for the duration of a test, returning a function that advances it
+func stubNow(t *testing.T) func(d time.Duration) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldNowFunc := nowFunc
+	nowFunc = func() time.Time { return now }
+	t.Cleanup(func() {
+		nowFunc = oldNowFunc
+	})
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestPrintProgressMinUpdateInterval(t *testing.T) {
+	setupSupportedTerminal(t)
+	advance := stubNow(t)
+	handler := NewOutputHandler(&OutputConfig{MinUpdateInterval: 100 * time.Millisecond})
+
+	output := captureOutput(func() {
+		for i := 1; i <= 10; i++ {
+			handler.PrintProgress(i, 10, "copying")
+			advance(30 * time.Millisecond)
+		}
+		handler.PrintProgress(1, 5, "next")
+	})
+
+	// Updates come every 30ms, so only one in four is printed besides the final one
+	expected := "\r[1/10] 10% - copying\n" +
+		"\r[5/10] 50% - copying\n" +
+		"\r[9/10] 90% - copying\n" +
+		"\r[10/10] 100% - copying\n" +
+		"\r[1/5] 20% - next\n"
+	if output != expected {
+		t.Errorf("PrintProgress() output = %q, want %q", output, expected)
+	}
+}
+
+func TestPrintProgressWithoutMinUpdateInterval(t *testing.T) {
+	setupSupportedTerminal(t)
+	stubNow(t)
+	handler := NewOutputHandler(&OutputConfig{})
+
+	output := captureOutput(func() {
+		for i := 1; i <= 5; i++ {
+			handler.PrintProgress(i, 5, "copying")
+		}
+	})
+	if strings.Count(output, "copying") != 5 {
+		t.Errorf("Expected every update without MinUpdateInterval, got %q", output)
+	}
+}
+
+func TestPrintErrors(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	disk := errors.New("disk full")
+	network := errors.New("connection reset\nafter 3 retries")
+	timeout := errors.New("timed out")
+
+	tests := []struct {
+		name     string
+		errs     []error
+		expected string
+	}{
+		{"None", nil, ""},
+		{"Only nil", []error{nil, nil}, ""},
+		{"One", []error{nil, disk}, "[ERROR] disk full\n"},
+		{"Three", []error{disk, network, timeout}, "[ERROR] 3 errors occurred:\n" +
+			"  - disk full\n" +
+			"  - connection reset\n    after 3 retries\n" +
+			"  - timed out\n"},
+		{"Joined", []error{errors.Join(disk, errors.Join(timeout, nil))}, "[ERROR] 2 errors occurred:\n" +
+			"  - disk full\n" +
+			"  - timed out\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewOutputHandler(&OutputConfig{UseFormatting: true})
+			output := captureOutput(func() {
+				handler.PrintErrors(tt.errs...)
+			})
+			if output != tt.expected {
+				t.Errorf("PrintErrors() = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrintErrorsColors(t *testing.T) {
+	setupSupportedTerminal(t)
+	handler := NewOutputHandler(&OutputConfig{UseColors: true, UseFormatting: true})
+
+	output := captureOutput(func() {
+		handler.PrintErrors(errors.New("first"), errors.New("second"))
+	})
+
+	if !strings.Contains(output, outputColors[LevelError]+"  - second"+ColorReset+"\n") {
+		t.Errorf("Expected indented errors in the error color, got %q", output)
+	}
+}
+
+func TestPrintCommandOutput(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"Multi-line", "building...\r\nok\n\nok\n", "[STAGE] go build\n  building...\n  ok\n\n  ok\n\n"},
+		{"No trailing newline", "PASS", "[STAGE] go build\n  PASS\n\n"},
+		{"Empty", "", "[STAGE] go build\n  (no output)\n\n"},
+		{"Only newlines", "\n\n", "[STAGE] go build\n  (no output)\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewOutputHandler(&OutputConfig{UseFormatting: true, SuppressRepeats: true})
+			output := captureOutput(func() {
+				handler.PrintCommandOutput("go build", tt.output)
+			})
+			if output != tt.expected {
+				t.Errorf("PrintCommandOutput() = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrintCommandOutputColorsAndQuiet(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	handler := NewOutputHandler(&OutputConfig{UseColors: true, UseFormatting: true})
+	output := captureOutput(func() {
+		handler.PrintCommandOutput("git status", "clean\n")
+	})
+	if !strings.HasSuffix(output, ColorDim+"  clean"+ColorReset+"\n\n") {
+		t.Errorf("Expected dimmed output lines, got %q", output)
+	}
+
+	for _, config := range []*OutputConfig{{QuietMode: true}, {DisableOutput: true}} {
+		handler := NewOutputHandler(config)
+		output := captureOutput(func() {
+			handler.PrintCommandOutput("git status", "clean\n")
+		})
+		if output != "" {
+			t.Errorf("Expected no output with %+v, got %q", config, output)
+		}
+	}
+}
+
+func TestAutoDetectEmoji(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected bool
+	}{
+		{"UTF-8 LANG", map[string]string{"TERM": "xterm-256color", "LANG": "en_US.UTF-8"}, true},
+		{"utf8 spelling", map[string]string{"TERM": "xterm", "LANG": "de_DE.utf8"}, true},
+		{"LC_ALL wins", map[string]string{"TERM": "xterm", "LC_ALL": "C", "LANG": "en_US.UTF-8"}, false},
+		{"LC_CTYPE before LANG", map[string]string{"TERM": "xterm", "LC_CTYPE": "en_US.UTF-8", "LANG": "C"}, true},
+		{"POSIX locale", map[string]string{"TERM": "xterm", "LANG": "C"}, false},
+		{"No locale", map[string]string{"TERM": "xterm"}, false},
+		{"Linux console", map[string]string{"TERM": "linux", "LANG": "en_US.UTF-8"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"TERM", "LC_ALL", "LC_CTYPE", "LANG"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			if supported := emojiSupported(); supported != tt.expected {
+				t.Errorf("emojiSupported() = %v, want %v", supported, tt.expected)
+			}
+
+			handler := NewOutputHandler(&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true, AutoDetectEmoji: true})
+			expectedPrefix := outputPrefixes[LevelSuccess]
+			if tt.expected {
+				expectedPrefix = outputEmojis[LevelSuccess]
+			}
+			if message := handler.FormatMessage(LevelSuccess, "done"); !strings.Contains(message, expectedPrefix+"done") {
+				t.Errorf("FormatMessage() = %q, want the %q prefix", message, expectedPrefix)
+			}
+		})
+	}
+}
+
+func TestAutoDetectEmojiDisabled(t *testing.T) {
+	t.Setenv("TERM", "linux")
+	t.Setenv("LANG", "C")
+
+	// Without AutoDetectEmoji, UseEmojis is taken at its word
+	handler := NewOutputHandler(&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true})
+	if message := handler.FormatMessage(LevelSuccess, "done"); !strings.Contains(message, outputEmojis[LevelSuccess]) {
+		t.Errorf("FormatMessage() = %q, want an emoji", message)
+	}
+}
+
+// countingWriter records each write separately
+type countingWriter struct {
+	writes []string
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestLogTree(t *testing.T) {
+	setupSupportedTerminal(t)
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+	tree, err := ParseYAMLToTree([]byte("server:\n  port: 8080\n"))
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	warnings := &countingWriter{}
+	handler := NewOutputHandler(&OutputConfig{UseFormatting: true, LevelWriters: map[OutputLevel]io.Writer{LevelWarning: warnings}})
+	handler.LogTree(LevelWarning, tree, WithShowValues())
+
+	expected := []string{"[WARNING] root\n└── server\n    └── port: 8080\n"}
+	if !reflect.DeepEqual(warnings.writes, expected) {
+		t.Errorf("LogTree() writes = %q, want %q", warnings.writes, expected)
+	}
+
+	// Quiet mode filters trees like other messages of their level
+	quiet := NewOutputHandler(&OutputConfig{QuietMode: true})
+	output := captureOutput(func() {
+		quiet.LogTree(LevelInfo, tree)
+		quiet.LogTree(LevelError, tree)
+		quiet.LogTree(LevelError, nil)
+	})
+	if output != "[ERROR] root\n└── server\n    └── port\n" {
+		t.Errorf("LogTree() in quiet mode = %q", output)
+	}
+}
@@ -0,0 +1,142 @@
+package palantir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+)
+
+// PanicAction selects what RecoverAndPrint does after printing a recovered panic
+type PanicAction int
+
+const (
+	// PanicContinue swallows the panic, so the deferring function returns normally
+	PanicContinue PanicAction = iota
+	// PanicRepanic panics again with the recovered value, such as to crash after reporting
+	PanicRepanic
+	// PanicExit exits the program with the handler's ExitCode
+	PanicExit
+)
+
+// exitFunc exits the program. It is a variable so tests can observe PanicExit.
+var exitFunc = os.Exit
+
+// RecoverAndPrint recovers a panic and prints it as an error, such as "panic: runtime
+// error: index out of range", followed by the stack of the code that panicked like
+// PrintErrorWithStack, even outside VerboseMode. It must be deferred directly, as in
+// `defer handler.RecoverAndPrint()`. The panic counts towards ErrorCount, and
+// OutputConfig.OnPanic selects what happens afterwards.
+func (oh *outputHandler) RecoverAndPrint() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	oh.errs.add(1)
+	oh.printMessage(LevelError, fmt.Sprintf("panic: %v", r))
+	oh.printStack(trimStack(debug.Stack()))
+
+	switch oh.config.OnPanic {
+	case PanicRepanic:
+		panic(r)
+	case PanicExit:
+		oh.Close()
+		exitFunc(max(oh.ExitCode(), 1))
+	}
+}
+
+// SafeGo runs fn in a new goroutine, printing a panic raised by it with RecoverAndPrint
+// instead of crashing the program
+func (oh *outputHandler) SafeGo(fn func()) {
+	go func() {
+		defer oh.RecoverAndPrint()
+		fn()
+	}()
+}
+
+// StackTracer is implemented by errors that carry the stack they were created at, in the
+// format of runtime/debug.Stack, for PrintErrorWithStack to print instead of its own
+type StackTracer interface {
+	Stack() []byte
+}
+
+// PrintErrorWithStack prints err like PrintError. In VerboseMode it is followed by a stack
+// trace, indented and dimmed: the stack carried by the first error in err's chain that is
+// a StackTracer, or else the stack of the caller.
+func (oh *outputHandler) PrintErrorWithStack(err error) {
+	if err == nil {
+		return
+	}
+
+	oh.errs.add(1)
+	oh.printMessage(LevelError, err.Error())
+	if !oh.config.VerboseMode {
+		return
+	}
+
+	stack := debug.Stack()
+	var tracer StackTracer
+	if errors.As(err, &tracer) {
+		stack = tracer.Stack()
+	}
+	oh.printStack(trimStack(stack))
+}
+
+// printStack prints the frames of a stack trace below an error, indented and dimmed
+func (oh *outputHandler) printStack(frames []string) {
+	if oh.config.DisableOutput || len(frames) == 0 {
+		return
+	}
+
+	var stack strings.Builder
+	for _, frame := range frames {
+		line := "    " + strings.ReplaceAll(frame, "\n", "\n        ")
+		if oh.config.UseColors && oh.IsSupported() {
+			line = strings.ReplaceAll(ColorDim+line, "\n", ColorReset+"\n"+ColorDim) + ColorReset
+		}
+		stack.WriteString(line + "\n")
+	}
+	oh.write(LevelError, stack.String())
+}
+
+// trimStack splits a stack captured by debug.Stack into frames of a function and its
+// location, such as "main.run(...)\nmain.go:12". The goroutine header and the frames of
+// the runtime's panic machinery and of the handler are left out.
+func trimStack(stack []byte) []string {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	var frames []string
+	for i := 1; i+1 < len(lines); i += 2 {
+		function, location := lines[i], strings.TrimSpace(lines[i+1])
+		if isInternalFrame(function) {
+			continue
+		}
+		if offset := strings.LastIndex(location, " +0x"); offset >= 0 {
+			location = location[:offset]
+		}
+		frames = append(frames, function+"\n"+location)
+	}
+	return frames
+}
+
+// isInternalFrame reports whether a stack frame belongs to the runtime or to the
+// handlers' own printing, rather than to the code that panicked
+func isInternalFrame(function string) bool {
+	return strings.HasPrefix(function, "runtime.") ||
+		strings.HasPrefix(function, "runtime/debug.") ||
+		strings.HasPrefix(function, "panic(") ||
+		strings.HasPrefix(function, "github.com/rocajuanma/palantir.(*outputHandler).") ||
+		strings.HasPrefix(function, "github.com/rocajuanma/palantir.(*PrefixedHandler).") ||
+		strings.HasPrefix(function, "github.com/rocajuanma/palantir.(*LevelFilterHandler).") ||
+		strings.HasPrefix(function, "github.com/rocajuanma/palantir.(*slogOutputHandler).") ||
+		strings.HasPrefix(function, "github.com/rocajuanma/palantir.rethrowTo(")
+}
+
+// rethrowTo panics again with a value recovered by a wrapping handler's RecoverAndPrint,
+// under inner's RecoverAndPrint, so inner prints it and applies its OnPanic. The frames
+// of the original panic are still on the stack, so inner prints them too.
+func rethrowTo(inner OutputHandler, r any) {
+	defer inner.RecoverAndPrint()
+	panic(r)
+}
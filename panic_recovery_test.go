@@ -0,0 +1,241 @@
+package palantir
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer safe for use by several goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// indexOutOfRange panics with a runtime error
+func indexOutOfRange(items []int) int {
+	return items[len(items)]
+}
+
+func TestRecoverAndPrint(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(&buf).Build())
+
+	func() {
+		defer handler.RecoverAndPrint()
+		indexOutOfRange([]int{1})
+	}()
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "[ERROR] panic: runtime error: index out of range [1] with length 1\n") {
+		t.Errorf("Expected the panic as an error, got:\n%s", output)
+	}
+	if !strings.Contains(output, "\n    github.com/rocajuanma/palantir.indexOutOfRange(") ||
+		!strings.Contains(output, "panic_recovery_test.go:") {
+		t.Errorf("Expected the stack of the panicking function, got:\n%s", output)
+	}
+	for _, internal := range []string{"[running]", "runtime/debug.Stack", "panic(", "(*outputHandler)", " +0x"} {
+		if strings.Contains(output, internal) {
+			t.Errorf("Expected %q to be trimmed from the stack, got:\n%s", internal, output)
+		}
+	}
+	if handler.ErrorCount() != 1 {
+		t.Errorf("ErrorCount() = %d, want 1", handler.ErrorCount())
+	}
+}
+
+func TestRecoverAndPrintWithoutPanic(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithWriter(&buf).Build())
+
+	func() {
+		defer handler.RecoverAndPrint()
+	}()
+
+	if buf.Len() != 0 || handler.HasErrors() {
+		t.Errorf("Expected nothing to be printed without a panic, got %q", buf.String())
+	}
+}
+
+func TestRecoverAndPrintRepanics(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithWriter(&buf).WithOnPanic(PanicRepanic).Build())
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected the panic to be raised again, got %v", r)
+		}
+		if !strings.Contains(buf.String(), "panic: boom") {
+			t.Errorf("Expected the panic to be printed first, got %q", buf.String())
+		}
+	}()
+	func() {
+		defer handler.RecoverAndPrint()
+		panic("boom")
+	}()
+}
+
+func TestRecoverAndPrintExits(t *testing.T) {
+	var exitCode int
+	originalExit := exitFunc
+	exitFunc = func(code int) { exitCode = code }
+	t.Cleanup(func() { exitFunc = originalExit })
+
+	var buf bytes.Buffer
+	config := NewConfigBuilder().WithCIMode(CIModeNone).WithWriter(&buf).WithOnPanic(PanicExit).
+		WithExitCodeFunc(func(errorCount int) int { return 70 }).Build()
+	handler := NewOutputHandler(config)
+
+	func() {
+		defer handler.RecoverAndPrint()
+		panic("boom")
+	}()
+
+	if exitCode != 70 {
+		t.Errorf("Exit code = %d, want 70", exitCode)
+	}
+}
+
+func TestSafeGo(t *testing.T) {
+	var buf lockedBuffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(&buf).Build())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	handler.SafeGo(func() {
+		defer wg.Done()
+		var config *OutputConfig
+		_ = config.UseColors // nil pointer dereference
+	})
+	wg.Wait()
+	handler.SafeGo(func() {}) // A goroutine that does not panic prints nothing
+
+	// RecoverAndPrint runs after the deferred Done, so wait for the stack to be printed
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "TestSafeGo") {
+		if time.Now().After(deadline) {
+			t.Fatalf("The panic was not printed in time, got %q", buf.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !strings.HasPrefix(buf.String(), "[ERROR] panic: runtime error: invalid memory address or nil pointer dereference\n") {
+		t.Errorf("Expected the goroutine's panic as an error, got:\n%s", buf.String())
+	}
+}
+
+// tracedError is an error carrying a fixed stack
+type tracedError struct{}
+
+func (tracedError) Error() string { return "traced failure" }
+
+func (tracedError) Stack() []byte {
+	return []byte("goroutine 1 [running]:\nmain.load(...)\n\t/src/main.go:42 +0x1d\n")
+}
+
+func TestPrintErrorWithStack(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbose   bool
+		err       error
+		wantStack string
+	}{
+		{"Not verbose", false, errors.New("disk full"), ""},
+		{"Verbose captures the caller", true, errors.New("disk full"), "palantir.TestPrintErrorWithStack"},
+		{"Verbose uses a StackTracer", true, fmt.Errorf("loading: %w", tracedError{}), "    main.load(...)\n        /src/main.go:42\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			builder := NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(&buf)
+			if tt.verbose {
+				builder.WithVerbose()
+			}
+			handler := NewOutputHandler(builder.Build())
+
+			handler.PrintErrorWithStack(tt.err)
+
+			lines := strings.SplitN(buf.String(), "\n", 2)
+			if lines[0] != "[ERROR] "+tt.err.Error() {
+				t.Errorf("Expected the error first, got %q", lines[0])
+			}
+			if tt.wantStack == "" && lines[1] != "" {
+				t.Errorf("Expected no stack outside verbose mode, got %q", lines[1])
+			}
+			if tt.wantStack != "" && !strings.Contains(lines[1], tt.wantStack) {
+				t.Errorf("Expected the stack to contain %q, got:\n%s", tt.wantStack, lines[1])
+			}
+			if handler.ErrorCount() != 1 {
+				t.Errorf("ErrorCount() = %d, want 1", handler.ErrorCount())
+			}
+		})
+	}
+}
+
+func TestRecoverAndPrintThroughWrappers(t *testing.T) {
+	var buf bytes.Buffer
+	inner := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(&buf).Build())
+	handlers := map[string]OutputHandler{
+		"Prefixed": NewPrefixedHandler(inner, "api"),
+		"Filtered": NewLevelFilterHandler(inner), // Panics pass through the filter
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+			inner.Reset()
+			func() {
+				defer handler.RecoverAndPrint()
+				indexOutOfRange([]int{1})
+			}()
+
+			output := buf.String()
+			if !strings.HasPrefix(output, "[ERROR] panic: runtime error: index out of range [1] with length 1\n") ||
+				!strings.Contains(output, "palantir.indexOutOfRange(") {
+				t.Errorf("Expected the panic with its stack, got:\n%s", output)
+			}
+			for _, internal := range []string{"(*PrefixedHandler)", "(*LevelFilterHandler)", "rethrowTo"} {
+				if strings.Contains(output, internal) {
+					t.Errorf("Expected %q to be trimmed from the stack, got:\n%s", internal, output)
+				}
+			}
+			if inner.ErrorCount() != 1 {
+				t.Errorf("ErrorCount() = %d, want 1", inner.ErrorCount())
+			}
+		})
+	}
+}
+
+func TestPrintErrorWithStackThroughWrappers(t *testing.T) {
+	var buf bytes.Buffer
+	inner := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithVerbose().WithWriter(&buf).Build())
+
+	var handler OutputHandler = NewPrefixedHandler(inner, "api").WithWidth(0)
+	handler.PrintErrorWithStack(fmt.Errorf("loading: %w", tracedError{}))
+	expected := "[ERROR] [api] loading: traced failure\n    main.load(...)\n        /src/main.go:42\n"
+	if buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+
+	buf.Reset()
+	NewLevelFilterHandler(inner, LevelWarning).PrintErrorWithStack(errors.New("hidden"))
+	if buf.Len() != 0 {
+		t.Errorf("Expected the filter to drop the error, got %q", buf.String())
+	}
+}
@@ -0,0 +1,263 @@
+package palantir
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+)
+
+// DefaultPrefixWidth is the width component tags such as "[api]" are padded to, so the
+// messages of components with short names line up
+const DefaultPrefixWidth = 8
+
+// prefixPalette holds the colors component tags are picked from, leaving out red so tags
+// are not mistaken for errors
+var prefixPalette = []string{ColorCyan, ColorPurple, ColorBlue, ColorGreen, ColorYellow}
+
+// PrefixedHandler wraps an OutputHandler to tag every message with a component name, such
+// as "✅ [api]    started". The tag follows the level prefix or emoji, so levels stay
+// aligned, and is padded to a fixed width. Wrapping a PrefixedHandler nests the tags,
+// outermost last.
+type PrefixedHandler struct {
+	inner  OutputHandler
+	prefix string
+	color  string
+	width  int
+	stages *stageCounter // Numbers the scopes begun on the handler, shared with WithPrefix
+}
+
+// NewPrefixedHandler creates a handler tagging the messages printed through inner with
+// "[prefix]". The tag's color is picked from a palette by hashing prefix, so a component
+// keeps its color across runs.
+func NewPrefixedHandler(inner OutputHandler, prefix string) *PrefixedHandler {
+	return &PrefixedHandler{inner: inner, prefix: prefix, color: prefixColor(prefix), width: DefaultPrefixWidth, stages: &stageCounter{}}
+}
+
+// WithColor sets the color of the tag, such as ColorCyan, and returns the handler
+func (p *PrefixedHandler) WithColor(color string) *PrefixedHandler {
+	p.color = color
+	return p
+}
+
+// WithWidth sets the width the tag is padded to, and returns the handler. Longer tags are
+// not truncated.
+func (p *PrefixedHandler) WithWidth(width int) *PrefixedHandler {
+	p.width = width
+	return p
+}
+
+// prefixColor deterministically picks a palette color for a component name
+func prefixColor(prefix string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(prefix))
+	return prefixPalette[hash.Sum32()%uint32(len(prefixPalette))]
+}
+
+// tag returns the padded tag preceding a message of the given level. A colored tag
+// restores the level's styling after it, so the rest of the message keeps its color.
+func (p *PrefixedHandler) tag(level OutputLevel) string {
+	color := outputColors[level]
+	if config := p.inner.Config(); config != nil {
+		color = config.levelStyle(level).Color
+		if config.ColorizeLevelOnly {
+			color = ""
+		}
+	}
+	if color == "" {
+		return p.styledTag("") // Plain messages, such as info by default, have nothing to restore
+	}
+	return p.styledTag(ColorBold + color)
+}
+
+// styledTag returns the padded tag, colored when the wrapped handler prints colors and
+// followed by the restore sequence
+func (p *PrefixedHandler) styledTag(restore string) string {
+	text := "[" + p.prefix + "]"
+	padding := strings.Repeat(" ", max(0, p.width-displayWidth(text))) + " "
+
+	config := p.inner.Config()
+	if config == nil || !config.UseColors || !config.UseFormatting || !p.inner.IsSupported() {
+		return text + padding
+	}
+	return p.color + text + ColorReset + restore + padding
+}
+
+// tagFormat prepends a tag to a format string, escaping any "%" in it
+func tagFormat(tag, format string) string {
+	return strings.ReplaceAll(tag, "%", "%%") + format
+}
+
+func (p *PrefixedHandler) PrintHeader(message string) {
+	p.inner.PrintHeader(p.tag(LevelHeader) + message)
+}
+
+func (p *PrefixedHandler) PrintHeaderWithSubtitle(title, subtitle string) {
+	p.inner.PrintHeaderWithSubtitle(p.tag(LevelHeader)+title, subtitle)
+}
+
+func (p *PrefixedHandler) PrintStage(message string) {
+	p.inner.PrintStage(p.tag(LevelStage) + message)
+}
+
+func (p *PrefixedHandler) PrintSuccess(message string) {
+	p.inner.PrintSuccess(p.tag(LevelSuccess) + message)
+}
+
+func (p *PrefixedHandler) PrintSuccessWithDuration(message string, d time.Duration) {
+	p.inner.PrintSuccessWithDuration(p.tag(LevelSuccess)+message, d)
+}
+
+func (p *PrefixedHandler) PrintError(format string, args ...interface{}) {
+	p.inner.PrintError(tagFormat(p.tag(LevelError), format), args...)
+}
+
+// PrintErrors tags each error, as the grouped list is printed by the wrapped handler
+func (p *PrefixedHandler) PrintErrors(errs ...error) {
+	errs = splitErrors(errs)
+	tagged := make([]error, len(errs))
+	for i, err := range errs {
+		tagged[i] = fmt.Errorf("%s%w", p.tag(LevelError), err)
+	}
+	p.inner.PrintErrors(tagged...)
+}
+
+func (p *PrefixedHandler) PrintCommandOutput(label, output string) {
+	p.inner.PrintCommandOutput(p.tag(LevelStage)+label, output)
+}
+
+// LogTree forwards the tree untagged, as its lines are drawn by the wrapped handler
+func (p *PrefixedHandler) LogTree(level OutputLevel, tree *TreeNode, opts ...BuildOption) {
+	p.inner.LogTree(level, tree, opts...)
+}
+
+func (p *PrefixedHandler) PrintWarning(format string, args ...interface{}) {
+	p.inner.PrintWarning(tagFormat(p.tag(LevelWarning), format), args...)
+}
+
+func (p *PrefixedHandler) PrintInfo(format string, args ...interface{}) {
+	p.inner.PrintInfo(tagFormat(p.tag(LevelInfo), format), args...)
+}
+
+func (p *PrefixedHandler) PrintAlreadyAvailable(format string, args ...interface{}) {
+	p.inner.PrintAlreadyAvailable(tagFormat(p.tag(LevelAvailable), format), args...)
+}
+
+func (p *PrefixedHandler) PrintColored(color string, format string, args ...interface{}) {
+	p.inner.PrintColored(color, tagFormat(p.styledTag(color), format), args...)
+}
+
+func (p *PrefixedHandler) PrintProgress(current, total int, message string) {
+	p.inner.PrintProgress(current, total, p.tag(LevelInfo)+message)
+}
+
+func (p *PrefixedHandler) Confirm(message string) bool {
+	return p.inner.Confirm(p.tag(LevelInfo) + message)
+}
+
+func (p *PrefixedHandler) ConfirmE(message string) (bool, error) {
+	return p.inner.ConfirmE(p.tag(LevelInfo) + message)
+}
+
+func (p *PrefixedHandler) IsSupported() bool {
+	return p.inner.IsSupported()
+}
+
+func (p *PrefixedHandler) Disable() {
+	p.inner.Disable()
+}
+
+// WithPrefix returns a PrefixedHandler with the same tag around inner.WithPrefix(prefix),
+// which labels the start of every line
+func (p *PrefixedHandler) WithPrefix(prefix string) OutputHandler {
+	return &PrefixedHandler{inner: p.inner.WithPrefix(prefix), prefix: p.prefix, color: p.color, width: p.width, stages: p.stages}
+}
+
+func (p *PrefixedHandler) Config() *OutputConfig {
+	return p.inner.Config()
+}
+
+// Close closes the wrapped handler
+func (p *PrefixedHandler) Close() error {
+	return p.inner.Close()
+}
+
+// Flush flushes the wrapped handler
+func (p *PrefixedHandler) Flush() error {
+	return p.inner.Flush()
+}
+
+// NewTaskList creates a task list printing each change as a tagged line, as the wrapped
+// handler cannot repaint lines it did not draw
+func (p *PrefixedHandler) NewTaskList() *TaskList {
+	return newTaskList(p)
+}
+
+// NewMultiProgress creates progress bars printing each update as a tagged line, as the
+// wrapped handler cannot redraw lines it did not draw
+func (p *PrefixedHandler) NewMultiProgress() *MultiProgress {
+	return newMultiProgress(p)
+}
+
+// RunStep runs fn like the built-in handler's RunStep, printing the stage and its result
+// as tagged lines without a spinner
+func (p *PrefixedHandler) RunStep(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return runStep(p, ctx, name, fn)
+}
+
+// RunSteps runs steps with RunStep and prints their summary as a tagged line
+func (p *PrefixedHandler) RunSteps(ctx context.Context, steps []Step) error {
+	return runSteps(ctx, steps, p.RunStep, printStepsSummaryTo(p))
+}
+
+// PrintErrorWithStack tags err, as it is printed with its stack by the wrapped handler
+func (p *PrefixedHandler) PrintErrorWithStack(err error) {
+	if err == nil {
+		return
+	}
+	p.inner.PrintErrorWithStack(fmt.Errorf("%s%w", p.tag(LevelError), err))
+}
+
+// RecoverAndPrint recovers a panic and has the wrapped handler print it, untagged, and
+// apply its OnPanic. It must be deferred directly, as in `defer handler.RecoverAndPrint()`.
+func (p *PrefixedHandler) RecoverAndPrint() {
+	if r := recover(); r != nil {
+		rethrowTo(p.inner, r)
+	}
+}
+
+// SafeGo runs fn in a new goroutine, printing a panic raised by it with RecoverAndPrint
+func (p *PrefixedHandler) SafeGo(fn func()) {
+	go func() {
+		defer p.RecoverAndPrint()
+		fn()
+	}()
+}
+
+// Bell rings the bell of the wrapped handler
+func (p *PrefixedHandler) Bell() {
+	p.inner.Bell()
+}
+
+// TimedStage runs fn like the built-in handler's TimedStage, printing the stage and its result as tagged lines
+func (p *PrefixedHandler) TimedStage(name string, fn func() error) error {
+	return timedStage(name, fn, p.PrintStage, stageResultPrinter(p))
+}
+
+// TimedStageContext runs fn like the built-in handler's TimedStageContext, printing the stage and its result as tagged lines
+func (p *PrefixedHandler) TimedStageContext(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return timedStageContext(ctx, name, fn, p.PrintStage, stageResultPrinter(p))
+}
+
+// BeginStage prints a numbered stage as a tagged line and returns its scope. Its numbering
+// is the handler's own, as the wrapped handler's is not reachable.
+func (p *PrefixedHandler) BeginStage(name string) *StageScope {
+	return beginStage(p, p.stages, name)
+}
+
+// CountLines returns how many terminal rows the wrapped handler would print the tagged
+// message on
+func (p *PrefixedHandler) CountLines(level OutputLevel, message string) int {
+	return p.inner.CountLines(level, p.tag(level)+message)
+}
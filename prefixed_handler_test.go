@@ -0,0 +1,118 @@
+package palantir
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrefixedHandlerPlacement(t *testing.T) {
+	setupSupportedTerminal(t)
+	inner := NewOutputHandler(&OutputConfig{UseFormatting: true})
+	api := NewPrefixedHandler(inner, "api")
+
+	output := captureOutput(func() {
+		api.PrintSuccess("started")
+		api.PrintWarning("retrying in %ds", 5)
+		api.PrintInfo("%d%% ready", 100)
+		NewPrefixedHandler(inner, "scheduler").PrintStage("queued")
+		NewPrefixedHandler(inner, "50%").PrintInfo("halfway")
+	})
+
+	expected := "[SUCCESS] [api]    started\n" +
+		"[WARNING] [api]    retrying in 5s\n" +
+		"[api]    100% ready\n" +
+		"[STAGE] [scheduler] queued\n" +
+		"[50%]    halfway\n"
+	if output != expected {
+		t.Errorf("PrefixedHandler output = %q, want %q", output, expected)
+	}
+}
+
+func TestPrefixedHandlerEmojiAndColors(t *testing.T) {
+	setupSupportedTerminal(t)
+	inner := NewOutputHandler(&OutputConfig{UseColors: true, UseEmojis: true, UseFormatting: true})
+	api := NewPrefixedHandler(inner, "api").WithColor(ColorCyan).WithWidth(0)
+
+	output := captureOutput(func() {
+		api.PrintSuccess("started")
+	})
+
+	// The tag follows the emoji, and the level's styling resumes after it
+	expected := ColorBold + ColorGreen + outputEmojis[LevelSuccess] +
+		ColorCyan + "[api]" + ColorReset + ColorBold + ColorGreen + " started" + ColorReset + "\n"
+	if output != expected {
+		t.Errorf("PrintSuccess() = %q, want %q", output, expected)
+	}
+
+	inner.Config().ColorizeLevelOnly = true
+	output = captureOutput(func() {
+		api.PrintSuccess("started")
+	})
+	expected = ColorBold + ColorGreen + outputEmojis[LevelSuccess] + ColorReset +
+		ColorCyan + "[api]" + ColorReset + " started\n"
+	if output != expected {
+		t.Errorf("PrintSuccess() with ColorizeLevelOnly = %q, want %q", output, expected)
+	}
+}
+
+func TestPrefixedHandlerNesting(t *testing.T) {
+	setupSupportedTerminal(t)
+	inner := NewOutputHandler(&OutputConfig{UseFormatting: true})
+	db := NewPrefixedHandler(NewPrefixedHandler(inner, "api").WithWidth(0), "db").WithWidth(0)
+
+	output := captureOutput(func() {
+		db.PrintError("connection lost")
+		db.PrintErrors(errors.New("disk full"), errors.New("timed out"))
+		db.WithPrefix("job-7").PrintInfo("done")
+	})
+
+	expected := "[ERROR] [api] [db] connection lost\n" +
+		"[ERROR] 2 errors occurred:\n" +
+		"  - [api] [db] disk full\n" +
+		"  - [api] [db] timed out\n" +
+		"[job-7] [api] [db] done\n"
+	if output != expected {
+		t.Errorf("Nested output = %q, want %q", output, expected)
+	}
+}
+
+func TestPrefixedHandlerForwards(t *testing.T) {
+	inner := NewOutputHandler(&OutputConfig{Reader: strings.NewReader("y\n")})
+	api := NewPrefixedHandler(inner, "api")
+
+	var confirmed bool
+	output := captureOutput(func() {
+		confirmed = api.Confirm("Deploy?")
+	})
+	if !confirmed || output != "? [api]    Deploy? (y/N): " {
+		t.Errorf("Confirm() = %v with prompt %q", confirmed, output)
+	}
+	if api.Config() != inner.Config() || api.IsSupported() != inner.IsSupported() {
+		t.Error("Expected Config and IsSupported to be forwarded")
+	}
+
+	api.Disable()
+	if !inner.Config().DisableOutput {
+		t.Error("Expected Disable to be forwarded")
+	}
+}
+
+func TestPrefixColorDeterministic(t *testing.T) {
+	seen := map[string]bool{}
+	for _, prefix := range []string{"api", "worker", "db", "scheduler", "cache", "web"} {
+		color := prefixColor(prefix)
+		if color != prefixColor(prefix) || color != NewPrefixedHandler(nil, prefix).color {
+			t.Errorf("Expected a stable color for %q", prefix)
+		}
+		seen[color] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Expected prefixes to spread over the palette, got %d colors", len(seen))
+	}
+	for color := range seen {
+		if color == ColorRed {
+			t.Error("Expected red to be reserved for errors")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package palantir
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultMaxReadSize is the most bytes the reader-based hierarchy functions read
+// unless WithMaxReadSize says otherwise
+const DefaultMaxReadSize = 10 << 20 // 10 MiB
+
+// StdinPath is the file path that makes the FromFile functions read standard input,
+// so tools can accept piped input such as "cat config.yaml | mytool -"
+const StdinPath = "-"
+
+// openInput opens a file for the FromFile functions, treating StdinPath as standard input.
+// Closing standard input is left to the caller's process.
+func openInput(filePath string) (io.ReadCloser, error) {
+	if filePath == StdinPath {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(filePath)
+}
+
+// readLimited reads all of r, failing when it holds more than the configured limit
+func readLimited(r io.Reader, options BuildOptions) ([]byte, error) {
+	limit := options.MaxReadSize
+	if limit <= 0 {
+		limit = DefaultMaxReadSize
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("input exceeds the %d byte limit", limit)
+	}
+	return content, nil
+}
+
+// ParseYAMLToTreeFromReader reads YAML from r, up to the WithMaxReadSize limit, and converts it to a TreeNode structure
+func ParseYAMLToTreeFromReader(r io.Reader, opts ...BuildOption) (*TreeNode, error) {
+	content, err := readLimited(r, newBuildOptions(opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read YAML: %w", err)
+	}
+	return ParseYAMLToTree(content, opts...)
+}
+
+// ShowYAMLHierarchyFromReader reads YAML from r, up to the WithMaxReadSize limit, and displays it as a tree structure
+func ShowYAMLHierarchyFromReader(r io.Reader, opts ...BuildOption) error {
+	content, err := readLimited(r, newBuildOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to read YAML: %w", err)
+	}
+	return ShowYAMLHierarchy(content, opts...)
+}
+
+// ParseJSONToTreeFromReader reads JSON from r, up to the WithMaxReadSize limit, and converts it to a TreeNode structure
+func ParseJSONToTreeFromReader(r io.Reader, opts ...BuildOption) (*TreeNode, error) {
+	content, err := readLimited(r, newBuildOptions(opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON: %w", err)
+	}
+	return ParseJSONToTree(content, opts...)
+}
+
+// ShowJSONHierarchyFromReader reads JSON from r, up to the WithMaxReadSize limit, and displays it as a tree structure
+func ShowJSONHierarchyFromReader(r io.Reader, opts ...BuildOption) error {
+	content, err := readLimited(r, newBuildOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to read JSON: %w", err)
+	}
+	return ShowJSONHierarchy(content, opts...)
+}
+
+// ParseTOMLToTreeFromReader reads TOML from r, up to the WithMaxReadSize limit, and converts it to a TreeNode structure
+func ParseTOMLToTreeFromReader(r io.Reader, opts ...BuildOption) (*TreeNode, error) {
+	content, err := readLimited(r, newBuildOptions(opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TOML: %w", err)
+	}
+	return ParseTOMLToTree(content, opts...)
+}
+
+// ShowTOMLHierarchyFromReader reads TOML from r, up to the WithMaxReadSize limit, and displays it as a tree structure
+func ShowTOMLHierarchyFromReader(r io.Reader, opts ...BuildOption) error {
+	content, err := readLimited(r, newBuildOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to read TOML: %w", err)
+	}
+	return ShowTOMLHierarchy(content, opts...)
+}
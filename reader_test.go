@@ -0,0 +1,172 @@
+package palantir
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// erroringReader fails every read
+type erroringReader struct{}
+
+func (erroringReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestShowHierarchyFromReader(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	tests := []struct {
+		name    string
+		show    func(r io.Reader, opts ...BuildOption) error
+		content string
+	}{
+		{"YAML", ShowYAMLHierarchyFromReader, "server:\n  port: 8080\n"},
+		{"JSON", ShowJSONHierarchyFromReader, `{"server": {"port": 8080}}`},
+		{"TOML", ShowTOMLHierarchyFromReader, "[server]\nport = 8080\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				if err := tt.show(strings.NewReader(tt.content), WithShowValues()); err != nil {
+					t.Errorf("Show%sHierarchyFromReader() error = %v", tt.name, err)
+				}
+			})
+			if expected := "└── server\n    └── port: 8080\n"; output != expected {
+				t.Errorf("Show%sHierarchyFromReader() = %q, want %q", tt.name, output, expected)
+			}
+		})
+	}
+}
+
+func TestParseToTreeFromReaderLimits(t *testing.T) {
+	parsers := []struct {
+		name  string
+		parse func(r io.Reader, opts ...BuildOption) (*TreeNode, error)
+	}{
+		{"YAML", ParseYAMLToTreeFromReader},
+		{"JSON", ParseJSONToTreeFromReader},
+		{"TOML", ParseTOMLToTreeFromReader},
+	}
+
+	for _, p := range parsers {
+		t.Run(p.name, func(t *testing.T) {
+			content := strings.NewReader(`{"a": 1}` + strings.Repeat(" ", 100))
+			_, err := p.parse(content, WithMaxReadSize(64))
+			if err == nil || !strings.Contains(err.Error(), "exceeds the 64 byte limit") {
+				t.Errorf("Expected a size limit error, got %v", err)
+			}
+
+			_, err = p.parse(erroringReader{})
+			if err == nil || !strings.Contains(err.Error(), "connection reset") {
+				t.Errorf("Expected the read error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestShowHierarchyFromFileLimits(t *testing.T) {
+	files := []struct {
+		name string
+		show func(filePath string, opts ...BuildOption) error
+	}{
+		{"XML", ShowXMLHierarchyFromFile},
+		{"env", ShowEnvHierarchyFromFile},
+		{"INI", ShowINIHierarchyFromFile},
+	}
+
+	filePath := filepath.Join(t.TempDir(), "large")
+	if err := os.WriteFile(filePath, []byte(strings.Repeat("a", 100)), 0o644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	for _, f := range files {
+		t.Run(f.name, func(t *testing.T) {
+			err := f.show(filePath, WithMaxReadSize(64))
+			if err == nil || !strings.Contains(err.Error(), "exceeds the 64 byte limit") {
+				t.Errorf("Expected a size limit error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReadLimitedAtLimit(t *testing.T) {
+	content, err := readLimited(strings.NewReader("12345678"), BuildOptions{MaxReadSize: 8})
+	if err != nil || string(content) != "12345678" {
+		t.Errorf("readLimited() = %q, %v; want the whole input", content, err)
+	}
+
+	if _, err := readLimited(strings.NewReader("123456789"), BuildOptions{MaxReadSize: 8}); err == nil {
+		t.Error("Expected an error one byte past the limit")
+	}
+}
+
+// pipeStdin replaces os.Stdin with a pipe holding content for the duration of the test
+func pipeStdin(t *testing.T, content string) {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	writer.WriteString(content)
+	writer.Close()
+
+	original := os.Stdin
+	os.Stdin = reader
+	t.Cleanup(func() {
+		os.Stdin = original
+		reader.Close()
+	})
+}
+
+func TestShowYAMLHierarchyFromStdin(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	pipeStdin(t, "server:\n  host: localhost\n")
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchyFromFile(StdinPath, WithShowValues()); err != nil {
+			t.Errorf("ShowYAMLHierarchyFromFile() error = %v", err)
+		}
+	})
+
+	if expected := "└── server\n    └── host: localhost\n"; output != expected {
+		t.Errorf("ShowYAMLHierarchyFromFile() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowJSONHierarchyFromStdin(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	pipeStdin(t, `{"port": 8080}`)
+
+	output := captureOutput(func() {
+		if err := ShowJSONHierarchyFromFile("-", WithShowValues()); err != nil {
+			t.Errorf("ShowJSONHierarchyFromFile() error = %v", err)
+		}
+	})
+
+	if expected := "└── port: 8080\n"; output != expected {
+		t.Errorf("ShowJSONHierarchyFromFile() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowYAMLHierarchyFromReaderInvalid(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	var err error
+	output := captureOutput(func() {
+		err = ShowYAMLHierarchyFromReader(strings.NewReader("server:\n  port: [8080\n"))
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to parse YAML") {
+		t.Errorf("Expected a parse error, got %v", err)
+	}
+	if output != "" {
+		t.Errorf("Expected nothing printed for invalid YAML, got %q", output)
+	}
+
+	if err := ShowYAMLHierarchyFromReader(erroringReader{}); err == nil || !strings.Contains(err.Error(), "failed to read YAML") {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package palantir
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RedactionMask replaces the values of redacted keys in rendered trees
+const RedactionMask = "••••••"
+
+// DefaultSecretPatterns matches the key names that commonly hold credentials
+var DefaultSecretPatterns = []string{
+	"*password*",
+	"*passwd*",
+	"*secret*",
+	"*token*",
+	"*api_key*",
+	"*apikey*",
+	"*api-key*",
+	"*private_key*",
+	"*credential*",
+}
+
+// keyMatcher matches mapping keys case-insensitively against glob patterns, or
+// regular expressions when the pattern is wrapped in slashes such as "/^pass(word)?$/".
+// Globs are compiled to regular expressions too, as keys are not paths: "*" also matches
+// "/", so "*password*" matches "vault.io/password".
+type keyMatcher struct {
+	regexps []*regexp.Regexp
+}
+
+// newKeyMatcher compiles patterns into a keyMatcher, returning nil when there are none
+func newKeyMatcher(patterns []string) (*keyMatcher, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	matcher := &keyMatcher{}
+	for _, pattern := range patterns {
+		if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+			}
+			matcher.regexps = append(matcher.regexps, re)
+			continue
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+		}
+		re, err := regexp.Compile(globToRegexp(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+		}
+		matcher.regexps = append(matcher.regexps, re)
+	}
+	return matcher, nil
+}
+
+// globToRegexp translates a glob in the syntax of path.Match into a case-insensitive
+// regular expression matching whole keys, without treating "/" as a separator
+func globToRegexp(glob string) string {
+	var re strings.Builder
+	re.WriteString("(?is)^")
+	inClass := false
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '\\' && i+1 < len(glob):
+			i++
+			re.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+			re.WriteByte(c)
+		case c == '[':
+			inClass = true
+			re.WriteByte(c)
+		case c == '*':
+			re.WriteString(".*")
+		case c == '?':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	re.WriteString("$")
+	return re.String()
+}
+
+// matches reports whether key matches any of the patterns
+func (m *keyMatcher) matches(key string) bool {
+	if m == nil {
+		return false
+	}
+
+	for _, re := range m.regexps {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// styleRedactedValue renders the mask in place of a value, keeping the value's type
+// color and adding a lock when emojis are enabled
+func styleRedactedValue(value interface{}) string {
+	outputConfig := treeOutputConfig()
+
+	text := RedactionMask
+	if outputConfig.emojisEnabled() {
+		text += " 🔒"
+	}
+
+	return styleTreeText(text, yamlValueColor(value), false)
+}
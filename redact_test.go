@@ -0,0 +1,160 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+var secretsYAML = []byte(`
+service:
+  name: billing
+  database:
+    user: admin
+    Password: hunter2
+  API_KEY: abc123
+users:
+  - name: alice
+    auth_token: t0k3n
+  - name: bob
+    auth_token: s3cr3t
+tokens:
+  - first-token
+  - second-token
+password_policy: strict
+`)
+
+func TestShowYAMLHierarchyRedactsSecrets(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		err := ShowYAMLHierarchy(secretsYAML, WithShowValues(), WithRedactKeys(DefaultSecretPatterns...))
+		if err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	for _, secret := range []string{"hunter2", "abc123", "t0k3n", "s3cr3t", "first-token", "second-token", "strict"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expected %q to be redacted, got:\n%s", secret, output)
+		}
+	}
+
+	expected := []string{
+		"name: billing",
+		"user: admin",
+		"Password: " + RedactionMask,
+		"API_KEY: " + RedactionMask,
+		"name: alice",
+		"auth_token: " + RedactionMask,
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestShowYAMLHierarchyRedactionOff(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowYAMLHierarchy(secretsYAML, WithShowValues())
+	})
+
+	if !strings.Contains(output, "Password: hunter2") || strings.Contains(output, RedactionMask) {
+		t.Errorf("Expected values to be shown without WithRedactKeys, got:\n%s", output)
+	}
+}
+
+func TestShowYAMLHierarchyRedactionAllowlist(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowYAMLHierarchy(secretsYAML, WithShowValues(),
+			WithRedactKeys(DefaultSecretPatterns...), WithAllowKeys("password_policy", "/^tokens$/"))
+	})
+
+	for _, line := range []string{"password_policy: strict", "first-token", "Password: " + RedactionMask} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestRedactedValueStyling(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true, UseEmojis: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	if result, expected := styleRedactedValue(8080), ColorCyan+RedactionMask+" 🔒"+ColorReset; result != expected {
+		t.Errorf("styleRedactedValue() = %q, want %q", result, expected)
+	}
+
+	root, err := ParseYAMLToTree(secretsYAML, WithRedactKeys(DefaultSecretPatterns...))
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+	password := findChild(findChild(findChild(root, "service"), "database"), "Password")
+	if data := password.Data.(YAMLNode); !data.Redacted || data.Value != "hunter2" {
+		t.Errorf("Expected a redacted node keeping its value, got %+v", data)
+	}
+}
+
+func TestKeyMatcherInvalidPattern(t *testing.T) {
+	if _, err := ParseYAMLToTree(secretsYAML, WithRedactKeys("/(unclosed/")); err == nil {
+		t.Error("Expected an error for an invalid regular expression")
+	}
+	if _, err := ParseYAMLToTree(secretsYAML, WithRedactKeys("[pass")); err == nil {
+		t.Error("Expected an error for an invalid glob")
+	}
+}
+
+func TestShowYAMLHierarchyRedactsKeysWithSlashes(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	annotations := []byte("annotations:\n  vault.io/password: hunter2\n  db_password: hunter3\n  app.io/name: billing\n")
+
+	output := captureOutput(func() {
+		err := ShowYAMLHierarchy(annotations, WithShowValues(), WithRedactKeys(DefaultSecretPatterns...))
+		if err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	for _, line := range []string{"vault.io/password: " + RedactionMask, "db_password: " + RedactionMask, "app.io/name: billing"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestKeyMatcherGlobs(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		matches bool
+	}{
+		{"*password*", "vault.io/password", true},
+		{"*password*", "DB_PASSWORD", true},
+		{"*password*", "username", false},
+		{"api?key", "api-key", true},
+		{"api?key", "api/key", true},
+		{"api?key", "apikey", false},
+		{"token", "auth_token", false},
+		{"[st]ecret", "Secret", true},
+		{"[^st]ecret", "secret", false},
+		{`a\*b`, "a*b", true},
+		{`a\*b`, "axb", false},
+		{"key.name", "keyxname", false},
+	}
+
+	for _, tt := range tests {
+		matcher, err := newKeyMatcher([]string{tt.pattern})
+		if err != nil {
+			t.Fatalf("newKeyMatcher(%q) error = %v", tt.pattern, err)
+		}
+		if got := matcher.matches(tt.key); got != tt.matches {
+			t.Errorf("Pattern %q matching %q = %v, want %v", tt.pattern, tt.key, got, tt.matches)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package palantir
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are drawn in turn by the spinner of RunStep
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is how often the spinner of RunStep is redrawn
+const spinnerInterval = 100 * time.Millisecond
+
+// clearLine erases the line under the cursor, such as a spinner
+const clearLine = "\r\033[K"
+
+// Step is an operation run by RunSteps
+type Step struct {
+	Name string                          // Printed as the stage and in its result
+	Run  func(ctx context.Context) error // The work of the step
+}
+
+// PanicError is returned by RunStep when its function panics. It is a StackTracer, so
+// PrintErrorWithStack prints where the panic happened.
+type PanicError struct {
+	Value any // The value passed to panic
+	stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Stack returns the stack of the goroutine that panicked
+func (e *PanicError) Stack() []byte {
+	return e.stack
+}
+
+// Unwrap returns the panic's value when it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// RunStep prints name as a stage, runs fn and reports the result with its duration like
+// TimedStage. On a terminal a spinner with the elapsed time is drawn below the stage while
+// fn runs; elsewhere the stage line stays on its own. fn's error is returned unmodified,
+// and a panic in fn is returned as a *PanicError. Cancelling ctx is left to fn, which
+// should return promptly, usually with ctx's error.
+func (oh *outputHandler) RunStep(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	oh.PrintStage(name)
+	start := nowFunc()
+
+	stop := oh.startSpinner(start)
+	err := runRecovered(ctx, fn)
+	duration := nowFunc().Sub(start)
+	stop()
+
+	oh.printStageResult(name, err, duration)
+	return err
+}
+
+// RunSteps runs steps in order with RunStep, stopping at the first failure or once ctx is
+// done, then prints a summary such as "3 steps completed (4.1s)". It returns the error of
+// the failed step, or ctx's error when it stopped before a step.
+func (oh *outputHandler) RunSteps(ctx context.Context, steps []Step) error {
+	return runSteps(ctx, steps, oh.RunStep, oh.printStepsSummary)
+}
+
+// runStep is RunStep for handlers other than the built-in one: the stage and its result
+// are printed through out, without a spinner
+func runStep(out OutputHandler, ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	out.PrintStage(name)
+	start := nowFunc()
+	err := runRecovered(ctx, fn)
+	printStageResultTo(out, name, err, nowFunc().Sub(start))
+	return err
+}
+
+// runSteps runs steps in order with run, stopping at the first failure or once ctx is
+// done, and prints the summary of RunSteps with summarize
+func runSteps(ctx context.Context, steps []Step, run func(ctx context.Context, name string, fn func(ctx context.Context) error) error,
+	summarize func(level OutputLevel, summary string, d time.Duration)) error {
+	start := nowFunc()
+	for i, step := range steps {
+		err := ctx.Err()
+		if err == nil {
+			err = run(ctx, step.Name, step.Run)
+		}
+		if err != nil {
+			summary := fmt.Sprintf("%d of %s completed, stopped at %s", i, pluralize(len(steps), "step", "steps"), step.Name)
+			summarize(LevelError, summary, nowFunc().Sub(start))
+			return err
+		}
+	}
+
+	summarize(LevelSuccess, pluralize(len(steps), "step", "steps")+" completed", nowFunc().Sub(start))
+	return nil
+}
+
+// printStepsSummary prints the summary of RunSteps with its duration. A failure was already
+// counted by the failed step, so it is not counted again.
+func (oh *outputHandler) printStepsSummary(level OutputLevel, summary string, d time.Duration) {
+	if level == LevelSuccess {
+		oh.PrintSuccessWithDuration(summary, d)
+		return
+	}
+	oh.printMessage(level, fmt.Sprintf("%s %s", summary, oh.durationSuffix(d)))
+}
+
+// printStepsSummaryTo returns the summary printer of RunSteps for handlers other than the
+// built-in one. A failure's summary is printed as a warning, as the failed step was
+// already counted as an error.
+func printStepsSummaryTo(out OutputHandler) func(level OutputLevel, summary string, d time.Duration) {
+	return func(level OutputLevel, summary string, d time.Duration) {
+		if level == LevelSuccess {
+			out.PrintSuccessWithDuration(summary, d)
+			return
+		}
+		out.PrintWarning("%s (%s)", summary, formatDuration(d))
+	}
+}
+
+// runRecovered calls fn, returning a panic in it as a *PanicError
+func runRecovered(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
+
+// liveBlock redraws a block of lines in place on a terminal, such as a TaskList, by moving
+// the cursor up over the lines it painted before. It is not safe for concurrent use.
+type liveBlock struct {
+	painted int // Lines written by the last paint
+}
+
+// paint writes lines over the previously painted ones. The block is written at once, so
+// output from other goroutines cannot end up inside it.
+func (b *liveBlock) paint(w io.Writer, lines []string) {
+	var block strings.Builder
+	if b.painted > 0 {
+		fmt.Fprintf(&block, "\033[%dA", b.painted)
+	}
+	for _, line := range lines {
+		block.WriteString(clearLine + line + "\n")
+	}
+	b.painted = len(lines)
+	fmt.Fprint(w, block.String())
+}
+
+// redrawable reports whether output can be redrawn in place, such as a spinner: the
+// LevelInfo writer is a terminal and output is shown and not meant for screen readers
+func (oh *outputHandler) redrawable() bool {
+	return !oh.config.DisableOutput && !oh.isQuieted(LevelInfo) && !oh.config.accessible() && !oh.config.githubActions() &&
+		oh.IsSupported() && isTerminal(oh.destinationFor(LevelInfo))
+}
+
+// startSpinner draws a spinner with the time elapsed since start until the returned
+// function is called, which clears it. The spinner ends with a carriage return, so
+// messages printed meanwhile overwrite it. It is only drawn when output is redrawable.
+func (oh *outputHandler) startSpinner(start time.Time) (stop func()) {
+	if !oh.redrawable() {
+		return func() {}
+	}
+
+	w := oh.writerFor(LevelInfo)
+	draw := func(frame int) {
+		spinner := spinnerFrames[frame%len(spinnerFrames)]
+		if oh.config.UseColors {
+			spinner = ColorCyan + spinner + ColorReset
+		}
+		fmt.Fprintf(w, "%s%s %s\r", clearLine, spinner, formatDuration(nowFunc().Sub(start)))
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer recoverPanic(oh.config)
+
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			draw(frame)
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	stopOnce := func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			fmt.Fprint(w, clearLine)
+		})
+	}
+	id := oh.spinners.add(stopOnce)
+	return func() {
+		oh.spinners.remove(id)
+		stopOnce()
+	}
+}
+
+// spinnerSet tracks the spinners being drawn, so Close can stop them. A nil set tracks
+// nothing.
+type spinnerSet struct {
+	mu     sync.Mutex
+	nextID int
+	active map[int]func()
+}
+
+// add tracks the stop function of a spinner, returning its id for remove
+func (s *spinnerSet) add(stop func()) int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		s.active = make(map[int]func())
+	}
+	s.nextID++
+	s.active[s.nextID] = stop
+	return s.nextID
+}
+
+// remove forgets a spinner that was stopped
+func (s *spinnerSet) remove(id int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, id)
+}
+
+// stopAll stops and clears the spinners still being drawn
+func (s *spinnerSet) stopAll() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	active := s.active
+	s.active = nil
+	s.mu.Unlock()
+
+	for _, stop := range active {
+		stop()
+	}
+}
@@ -0,0 +1,244 @@
+package palantir
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunStep(t *testing.T) {
+	errChecksum := errors.New("checksum mismatch")
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Success", nil, "[STAGE] Download\n[SUCCESS] Download (2.4s)\n"},
+		{"Failure", errChecksum, "[STAGE] Download\n[ERROR] Download failed: checksum mismatch (2.4s)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := stubFakeClock(t)
+			var buf bytes.Buffer
+			handler := newTimedStageHandler(&buf)
+
+			err := handler.RunStep(context.Background(), "Download", func(ctx context.Context) error {
+				clock.Advance(2400 * time.Millisecond)
+				return tt.err
+			})
+
+			if err != tt.err {
+				t.Errorf("RunStep() error = %v, want %v unmodified", err, tt.err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Output = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunStepPanic(t *testing.T) {
+	stubFakeClock(t)
+	var buf bytes.Buffer
+	handler := newTimedStageHandler(&buf)
+
+	err := handler.RunStep(context.Background(), "Deploy", func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("Expected a *PanicError for %q, got %v", "boom", err)
+	}
+	if !strings.Contains(string(panicErr.Stack()), "TestRunStepPanic") {
+		t.Errorf("Expected the stack of the panic, got %q", panicErr.Stack())
+	}
+	if expected := "[STAGE] Deploy\n[ERROR] Deploy failed: panic: boom (0ms)\n"; buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+	if handler.ErrorCount() != 1 {
+		t.Errorf("Expected the panic to count as an error, got %d", handler.ErrorCount())
+	}
+
+	cause := errors.New("nil map")
+	err = handler.RunStep(context.Background(), "Deploy", func(ctx context.Context) error {
+		panic(cause)
+	})
+	if !errors.Is(err, cause) {
+		t.Errorf("Expected a panic with an error to unwrap to it, got %v", err)
+	}
+}
+
+func TestRunStepContextCancellation(t *testing.T) {
+	stubFakeClock(t)
+	var buf bytes.Buffer
+	handler := newTimedStageHandler(&buf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := handler.RunStep(ctx, "Upload", func(ctx context.Context) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected fn's context error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "[ERROR] Upload failed: context canceled") {
+		t.Errorf("Expected a failure line, got %q", buf.String())
+	}
+}
+
+func TestRunStepSpinner(t *testing.T) {
+	stubFakeClock(t)
+	var tty bytes.Buffer
+	stubTerminal(t, &tty)
+	handler := newTimedStageHandler(&tty)
+
+	handler.RunStep(context.Background(), "Build", func(ctx context.Context) error { return nil })
+
+	output := tty.String()
+	expectedSpinner := clearLine + spinnerFrames[0] + " 0ms\r"
+	if !strings.HasPrefix(output, "[STAGE] Build\n"+expectedSpinner) {
+		t.Errorf("Expected the spinner below the stage, got %q", output)
+	}
+	if !strings.HasSuffix(output, clearLine+"[SUCCESS] Build (0ms)\n") {
+		t.Errorf("Expected the spinner to be cleared before the result, got %q", output)
+	}
+
+	var file bytes.Buffer
+	newTimedStageHandler(&file).RunStep(context.Background(), "Build", func(ctx context.Context) error { return nil })
+	if strings.Contains(file.String(), "\r") {
+		t.Errorf("Expected no spinner for a writer that is not a terminal, got %q", file.String())
+	}
+}
+
+func TestRunSteps(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		clock := stubFakeClock(t)
+		var buf bytes.Buffer
+		handler := newTimedStageHandler(&buf)
+
+		step := func(ctx context.Context) error {
+			clock.Advance(time.Second)
+			return nil
+		}
+		err := handler.RunSteps(context.Background(), []Step{{"Build", step}, {"Test", step}})
+
+		if err != nil {
+			t.Errorf("RunSteps() error = %v", err)
+		}
+		expected := "[STAGE] Build\n[SUCCESS] Build (1.0s)\n[STAGE] Test\n[SUCCESS] Test (1.0s)\n[SUCCESS] 2 steps completed (2.0s)\n"
+		if buf.String() != expected {
+			t.Errorf("Output = %q, want %q", buf.String(), expected)
+		}
+	})
+
+	t.Run("Stops at the first failure", func(t *testing.T) {
+		stubFakeClock(t)
+		var buf bytes.Buffer
+		handler := newTimedStageHandler(&buf)
+
+		errFailed := errors.New("2 tests failed")
+		ran := false
+		err := handler.RunSteps(context.Background(), []Step{
+			{"Build", func(ctx context.Context) error { return nil }},
+			{"Test", func(ctx context.Context) error { return errFailed }},
+			{"Deploy", func(ctx context.Context) error { ran = true; return nil }},
+		})
+
+		if err != errFailed {
+			t.Errorf("RunSteps() error = %v, want %v", err, errFailed)
+		}
+		if ran {
+			t.Error("Expected the steps after a failure not to run")
+		}
+		if !strings.HasSuffix(buf.String(), "[ERROR] Test failed: 2 tests failed (0ms)\n[ERROR] 1 of 3 steps completed, stopped at Test (0ms)\n") {
+			t.Errorf("Expected a failure summary, got %q", buf.String())
+		}
+		if handler.ErrorCount() != 1 {
+			t.Errorf("Expected the summary not to count as another error, got %d", handler.ErrorCount())
+		}
+	})
+
+	t.Run("Stops once the context is done", func(t *testing.T) {
+		stubFakeClock(t)
+		var buf bytes.Buffer
+		handler := newTimedStageHandler(&buf)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ran := false
+		err := handler.RunSteps(ctx, []Step{
+			{"Build", func(ctx context.Context) error { cancel(); return nil }},
+			{"Deploy", func(ctx context.Context) error { ran = true; return nil }},
+		})
+
+		if !errors.Is(err, context.Canceled) || ran {
+			t.Errorf("Expected to stop before Deploy with the context's error, got %v (ran %v)", err, ran)
+		}
+		if !strings.HasSuffix(buf.String(), "[ERROR] 1 of 2 steps completed, stopped at Deploy (0ms)\n") {
+			t.Errorf("Expected a summary naming the step not run, got %q", buf.String())
+		}
+	})
+}
+
+func TestCloseStopsSpinner(t *testing.T) {
+	stubFakeClock(t)
+	var tty lockedBuffer
+	stubTerminal(t, &tty)
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(&tty).Build())
+
+	var closed string
+	handler.RunStep(context.Background(), "Build", func(ctx context.Context) error {
+		if err := handler.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+		closed = tty.String()
+		return nil
+	})
+
+	if !strings.HasSuffix(closed, spinnerFrames[0]+" 0ms\r"+clearLine) {
+		t.Errorf("Expected Close to clear the spinner, got %q", closed)
+	}
+	if output := tty.String(); output != closed+"[SUCCESS] Build (0ms)\n" {
+		t.Errorf("Expected the spinner to stay stopped after Close, got %q", output)
+	}
+}
+
+func TestRunStepsThroughWrappers(t *testing.T) {
+	clock := stubFakeClock(t)
+	var buf bytes.Buffer
+	inner := newTimedStageHandler(&buf)
+	steps := []Step{
+		{"Build", func(ctx context.Context) error { clock.Advance(time.Second); return nil }},
+		{"Test", func(ctx context.Context) error { return errors.New("2 tests failed") }},
+	}
+
+	var handler OutputHandler = NewPrefixedHandler(inner, "ci").WithWidth(0)
+	if err := handler.RunSteps(context.Background(), steps); err == nil {
+		t.Error("Expected the error of the failed step")
+	}
+	expected := "[STAGE] [ci] Build\n[SUCCESS] [ci] Build (1.0s)\n[STAGE] [ci] Test\n" +
+		"[ERROR] [ci] Test failed: 2 tests failed (0ms)\n[WARNING] [ci] 1 of 2 steps completed, stopped at Test (1.0s)\n"
+	if buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+	if inner.ErrorCount() != 1 {
+		t.Errorf("Expected the summary not to count as another error, got %d", inner.ErrorCount())
+	}
+
+	buf.Reset()
+	handler = NewLevelFilterHandler(inner, LevelError)
+	err := handler.RunStep(context.Background(), "Deploy", func(ctx context.Context) error { panic("boom") })
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Errorf("Expected a *PanicError, got %v", err)
+	}
+	if buf.String() != "[ERROR] Deploy failed: panic: boom (0ms)\n" {
+		t.Errorf("Expected only the filtered error, got %q", buf.String())
+	}
+}
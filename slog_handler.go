@@ -0,0 +1,264 @@
+package palantir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// slogOutputHandler implements the OutputHandler interface on top of a *slog.Logger
+type slogOutputHandler struct {
+	logger   *slog.Logger
+	config   *OutputConfig
+	prefixes []string
+	errs     *errorCounter // Shared with the handlers returned by WithPrefix
+	stages   *stageCounter // Numbers the scopes begun on the handler, shared with WithPrefix
+}
+
+// NewSlogHandler creates an OutputHandler that logs through logger instead of writing to
+// the terminal. Info, warnings and errors map to the matching slog levels; headers,
+// stages and successes log at Info with a "kind" attribute. Progress logs at Info and
+// confirmations are never granted.
+func NewSlogHandler(logger *slog.Logger) OutputHandler {
+	return &slogOutputHandler{
+		logger: logger,
+		config: &OutputConfig{UseColors: false, UseEmojis: false, UseFormatting: false},
+		errs:   &errorCounter{},
+		stages: &stageCounter{},
+	}
+}
+
+// log emits a record unless output is disabled, adding the handler's prefixes
+func (sh *slogOutputHandler) log(level slog.Level, message string, attrs ...slog.Attr) {
+	if sh.config.DisableOutput {
+		return
+	}
+	if len(sh.prefixes) > 0 {
+		attrs = append(attrs, slog.String("prefix", strings.Join(sh.prefixes, ".")))
+	}
+	sh.logger.LogAttrs(context.Background(), level, message, attrs...)
+}
+
+func (sh *slogOutputHandler) PrintHeader(message string) {
+	sh.log(slog.LevelInfo, message, slog.String("kind", "header"))
+}
+
+func (sh *slogOutputHandler) PrintHeaderWithSubtitle(title, subtitle string) {
+	sh.log(slog.LevelInfo, title, slog.String("kind", "header"), slog.String("subtitle", subtitle))
+}
+
+func (sh *slogOutputHandler) PrintStage(message string) {
+	sh.log(slog.LevelInfo, message, slog.String("kind", "stage"))
+}
+
+func (sh *slogOutputHandler) PrintSuccess(message string) {
+	sh.log(slog.LevelInfo, message, slog.String("kind", "success"))
+}
+
+func (sh *slogOutputHandler) PrintSuccessWithDuration(message string, d time.Duration) {
+	sh.log(slog.LevelInfo, message, slog.String("kind", "success"), slog.Duration("duration", d))
+}
+
+func (sh *slogOutputHandler) PrintError(format string, args ...interface{}) {
+	sh.errs.add(1)
+	sh.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+// PrintErrors logs each error, with errors.Join results split into their parts, as its own record
+func (sh *slogOutputHandler) PrintErrors(errs ...error) {
+	errs = splitErrors(errs)
+	sh.errs.add(len(errs))
+	for _, err := range errs {
+		sh.log(slog.LevelError, err.Error())
+	}
+}
+
+// PrintCommandOutput logs the output of a command as a stage record with an "output" attribute
+func (sh *slogOutputHandler) PrintCommandOutput(label, output string) {
+	sh.log(slog.LevelInfo, label, slog.String("kind", "stage"), slog.String("output", output))
+}
+
+// LogTree logs a tree, rendered without colors and labelled with its root, as the message
+// of a single record with a "tree" kind
+func (sh *slogOutputHandler) LogTree(level OutputLevel, tree *TreeNode, opts ...BuildOption) {
+	if tree == nil {
+		return
+	}
+	if level == LevelError {
+		sh.errs.add(1)
+	}
+	sh.log(slogLevel(level), stripANSI(renderTreeString(tree, opts)), slog.String("kind", "tree"))
+}
+
+// slogLevel maps an output level to a slog level: errors and warnings to their own, and
+// every other level to Info
+func slogLevel(level OutputLevel) slog.Level {
+	switch level {
+	case LevelError:
+		return slog.LevelError
+	case LevelWarning:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func (sh *slogOutputHandler) PrintWarning(format string, args ...interface{}) {
+	sh.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (sh *slogOutputHandler) PrintInfo(format string, args ...interface{}) {
+	sh.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (sh *slogOutputHandler) PrintAlreadyAvailable(format string, args ...interface{}) {
+	sh.log(slog.LevelInfo, fmt.Sprintf(format, args...), slog.String("kind", "available"))
+}
+
+func (sh *slogOutputHandler) PrintColored(color string, format string, args ...interface{}) {
+	sh.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (sh *slogOutputHandler) PrintProgress(current, total int, message string) {
+	sh.log(slog.LevelInfo, message, slog.String("kind", "progress"), slog.Int("current", current), slog.Int("total", total))
+}
+
+// Confirm always returns false since a logger cannot ask questions
+func (sh *slogOutputHandler) Confirm(message string) bool {
+	confirmed, _ := sh.ConfirmE(message)
+	return confirmed
+}
+
+// ConfirmE logs the question and returns false with an error, since a logger cannot ask questions
+func (sh *slogOutputHandler) ConfirmE(message string) (bool, error) {
+	sh.log(slog.LevelInfo, message, slog.String("kind", "confirm"))
+	return false, errors.New("confirmation is not supported when logging through slog")
+}
+
+// IsSupported always returns true since logging does not depend on the terminal
+func (sh *slogOutputHandler) IsSupported() bool {
+	return true
+}
+
+// Disable disables all output
+func (sh *slogOutputHandler) Disable() {
+	sh.config.DisableOutput = true
+}
+
+// WithPrefix returns a handler sharing this handler's logger and configuration that adds a
+// "prefix" attribute to every record. Prefixes compose as "outer.inner".
+func (sh *slogOutputHandler) WithPrefix(prefix string) OutputHandler {
+	prefixes := make([]string, 0, len(sh.prefixes)+1)
+	prefixes = append(prefixes, sh.prefixes...)
+	prefixes = append(prefixes, prefix)
+	return &slogOutputHandler{logger: sh.logger, config: sh.config, prefixes: prefixes, errs: sh.errs, stages: sh.stages}
+}
+
+// Config returns the handler's output configuration, which disables colors and emojis
+func (sh *slogOutputHandler) Config() *OutputConfig {
+	return sh.config
+}
+
+// Close does nothing, as the logger owns its output
+func (sh *slogOutputHandler) Close() error {
+	return nil
+}
+
+// Flush does nothing, as records are not batched
+func (sh *slogOutputHandler) Flush() error {
+	return nil
+}
+
+// NewTaskList creates a task list logging each change as a record
+func (sh *slogOutputHandler) NewTaskList() *TaskList {
+	return newTaskList(sh)
+}
+
+// NewMultiProgress creates progress bars logging each update as a progress record
+func (sh *slogOutputHandler) NewMultiProgress() *MultiProgress {
+	return newMultiProgress(sh)
+}
+
+// RunStep runs fn like the built-in handler's RunStep, logging the stage and its result
+func (sh *slogOutputHandler) RunStep(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return runStep(sh, ctx, name, fn)
+}
+
+// RunSteps runs steps with RunStep and logs their summary
+func (sh *slogOutputHandler) RunSteps(ctx context.Context, steps []Step) error {
+	return runSteps(ctx, steps, sh.RunStep, printStepsSummaryTo(sh))
+}
+
+// PrintErrorWithStack logs err as an error. In VerboseMode the record has a "stack"
+// attribute, with the stack carried by err's chain or else the stack of the caller.
+func (sh *slogOutputHandler) PrintErrorWithStack(err error) {
+	if err == nil {
+		return
+	}
+	sh.errs.add(1)
+	if !sh.config.VerboseMode {
+		sh.log(slog.LevelError, err.Error())
+		return
+	}
+
+	stack := debug.Stack()
+	var tracer StackTracer
+	if errors.As(err, &tracer) {
+		stack = tracer.Stack()
+	}
+	sh.log(slog.LevelError, err.Error(), slog.String("stack", strings.Join(trimStack(stack), "\n")))
+}
+
+// RecoverAndPrint recovers a panic and logs it as an error with a "stack" attribute, then
+// applies OutputConfig.OnPanic. It must be deferred directly, as in
+// `defer handler.RecoverAndPrint()`.
+func (sh *slogOutputHandler) RecoverAndPrint() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	sh.errs.add(1)
+	sh.log(slog.LevelError, fmt.Sprintf("panic: %v", r), slog.String("stack", strings.Join(trimStack(debug.Stack()), "\n")))
+	switch sh.config.OnPanic {
+	case PanicRepanic:
+		panic(r)
+	case PanicExit:
+		exitFunc(max(sh.ExitCode(), 1))
+	}
+}
+
+// SafeGo runs fn in a new goroutine, logging a panic raised by it with RecoverAndPrint
+func (sh *slogOutputHandler) SafeGo(fn func()) {
+	go func() {
+		defer sh.RecoverAndPrint()
+		fn()
+	}()
+}
+
+// Bell does nothing, as a logger has no terminal to ring
+func (sh *slogOutputHandler) Bell() {}
+
+// TimedStage runs fn like the built-in handler's TimedStage, logging the stage and its result
+func (sh *slogOutputHandler) TimedStage(name string, fn func() error) error {
+	return timedStage(name, fn, sh.PrintStage, stageResultPrinter(sh))
+}
+
+// TimedStageContext runs fn like the built-in handler's TimedStageContext, logging the stage and its result
+func (sh *slogOutputHandler) TimedStageContext(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return timedStageContext(ctx, name, fn, sh.PrintStage, stageResultPrinter(sh))
+}
+
+// BeginStage logs a numbered stage and returns its scope, whose lines are logged as records
+func (sh *slogOutputHandler) BeginStage(name string) *StageScope {
+	return beginStage(sh, sh.stages, name)
+}
+
+// CountLines returns 0, as records are not drawn on a terminal that could be redrawn
+func (sh *slogOutputHandler) CountLines(level OutputLevel, message string) int {
+	return 0
+}
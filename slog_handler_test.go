@@ -0,0 +1,189 @@
+package palantir
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capturingSlogHandler records every slog record it handles
+type capturingSlogHandler struct {
+	records []slog.Record
+}
+
+func (h *capturingSlogHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *capturingSlogHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *capturingSlogHandler) WithGroup(string) slog.Handler            { return h }
+func (h *capturingSlogHandler) Handle(_ context.Context, record slog.Record) error {
+	h.records = append(h.records, record)
+	return nil
+}
+
+// recordAttrs collects the attributes of a record as strings
+func recordAttrs(record slog.Record) map[string]string {
+	attrs := make(map[string]string)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+	return attrs
+}
+
+func TestSlogHandlerLevelMapping(t *testing.T) {
+	capture := &capturingSlogHandler{}
+	handler := NewSlogHandler(slog.New(capture))
+
+	handler.PrintInfo("info %d", 1)
+	handler.PrintWarning("warning %d", 2)
+	handler.PrintError("error %d", 3)
+	handler.PrintStage("stage")
+	handler.PrintSuccess("success")
+	handler.PrintHeader("header")
+	handler.PrintSuccessWithDuration("built", 1500*time.Millisecond)
+	handler.PrintProgress(2, 4, "copying")
+
+	expected := []struct {
+		level   slog.Level
+		message string
+		kind    string
+	}{
+		{slog.LevelInfo, "info 1", ""},
+		{slog.LevelWarn, "warning 2", ""},
+		{slog.LevelError, "error 3", ""},
+		{slog.LevelInfo, "stage", "stage"},
+		{slog.LevelInfo, "success", "success"},
+		{slog.LevelInfo, "header", "header"},
+		{slog.LevelInfo, "built", "success"},
+		{slog.LevelInfo, "copying", "progress"},
+	}
+
+	if len(capture.records) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(capture.records))
+	}
+	for i, want := range expected {
+		record := capture.records[i]
+		if record.Level != want.level || record.Message != want.message {
+			t.Errorf("Record %d = %v %q, want %v %q", i, record.Level, record.Message, want.level, want.message)
+		}
+		if kind := recordAttrs(record)["kind"]; kind != want.kind {
+			t.Errorf("Record %d kind = %q, want %q", i, kind, want.kind)
+		}
+	}
+
+	if attrs := recordAttrs(capture.records[6]); attrs["duration"] != "1.5s" {
+		t.Errorf("Expected a duration attribute, got %v", attrs)
+	}
+	if attrs := recordAttrs(capture.records[7]); attrs["current"] != "2" || attrs["total"] != "4" {
+		t.Errorf("Expected progress attributes, got %v", attrs)
+	}
+}
+
+func TestSlogHandlerConfirmAndPrefix(t *testing.T) {
+	capture := &capturingSlogHandler{}
+	handler := NewSlogHandler(slog.New(capture))
+
+	if handler.Confirm("Proceed?") {
+		t.Error("Confirm() = true, want false")
+	}
+	if _, err := handler.ConfirmE("Proceed?"); err == nil {
+		t.Error("Expected ConfirmE() to report that it cannot ask")
+	}
+
+	handler.WithPrefix("deploy").WithPrefix("db").PrintInfo("migrating")
+	if attrs := recordAttrs(capture.records[len(capture.records)-1]); attrs["prefix"] != "deploy.db" {
+		t.Errorf("Expected a composed prefix attribute, got %v", attrs)
+	}
+
+	handler.Disable()
+	count := len(capture.records)
+	handler.PrintError("ignored")
+	if len(capture.records) != count {
+		t.Error("Expected no records once disabled")
+	}
+}
+
+func TestSlogHandlerPrintErrors(t *testing.T) {
+	capture := &capturingSlogHandler{}
+	handler := NewSlogHandler(slog.New(capture))
+
+	handler.PrintErrors(errors.Join(errors.New("disk full"), errors.New("timed out")), nil)
+
+	if len(capture.records) != 2 {
+		t.Fatalf("Expected a record per error, got %d", len(capture.records))
+	}
+	for i, message := range []string{"disk full", "timed out"} {
+		if record := capture.records[i]; record.Message != message || record.Level != slog.LevelError {
+			t.Errorf("Record %d = %s %q, want ERROR %q", i, record.Level, record.Message, message)
+		}
+	}
+}
+
+func TestSlogHandlerPrintCommandOutput(t *testing.T) {
+	capture := &capturingSlogHandler{}
+	handler := NewSlogHandler(slog.New(capture))
+
+	handler.PrintCommandOutput("go test", "ok\n")
+
+	if len(capture.records) != 1 {
+		t.Fatalf("Expected one record, got %d", len(capture.records))
+	}
+	record := capture.records[0]
+	attrs := map[string]string{}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+	if record.Message != "go test" || attrs["kind"] != "stage" || attrs["output"] != "ok\n" {
+		t.Errorf("Unexpected record %q with attributes %v", record.Message, attrs)
+	}
+}
+
+func TestSlogHandlerLogTree(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+	capture := &capturingSlogHandler{}
+	handler := NewSlogHandler(slog.New(capture))
+	tree, err := ParseYAMLToTree([]byte("server:\n  port: 8080\n"))
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	handler.LogTree(LevelWarning, tree)
+
+	if len(capture.records) != 1 {
+		t.Fatalf("Expected the tree as one record, got %d", len(capture.records))
+	}
+	record := capture.records[0]
+	if expected := "root\n└── server\n    └── port"; record.Message != expected || record.Level != slog.LevelWarn {
+		t.Errorf("Record = %s %q, want WARN %q", record.Level, record.Message, expected)
+	}
+}
+
+func TestSlogHandlerRecoverAndPrint(t *testing.T) {
+	capture := &capturingSlogHandler{}
+	handler := NewSlogHandler(slog.New(capture))
+
+	func() {
+		defer handler.RecoverAndPrint()
+		panic("boom")
+	}()
+
+	if len(capture.records) != 1 {
+		t.Fatalf("Expected one record, got %d", len(capture.records))
+	}
+	record := capture.records[0]
+	if record.Message != "panic: boom" || record.Level != slog.LevelError {
+		t.Errorf("Record = %s %q, want ERROR %q", record.Level, record.Message, "panic: boom")
+	}
+	if stack := recordAttrs(record)["stack"]; !strings.Contains(stack, "TestSlogHandlerRecoverAndPrint") {
+		t.Errorf("Expected the stack of the panicking function, got %q", stack)
+	}
+	if ExitCodeOf(handler) != 1 {
+		t.Errorf("Expected the panic to count as an error")
+	}
+}
@@ -0,0 +1,57 @@
+package palantir
+
+import (
+	"fmt"
+	"sync"
+)
+
+// stageCounter numbers stages for OutputConfig.NumberStages. It is shared by a handler
+// and the handlers returned by its WithPrefix, and is safe for concurrent use.
+type stageCounter struct {
+	mu    sync.Mutex
+	count int // Stages printed since the last reset
+	total int // Expected number of stages, 0 when unknown
+}
+
+// next numbers a stage, returning a label such as "[2/5]" or "[2/?]" without a total
+func (c *stageCounter) next() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.count++
+	if c.total > 0 {
+		return fmt.Sprintf("[%d/%d]", c.count, c.total)
+	}
+	return fmt.Sprintf("[%d/?]", c.count)
+}
+
+// SetTotalStages sets the number of stages shown after each stage number under
+// NumberStages, such as "[2/5]". Zero shows "[2/?]".
+func (oh *outputHandler) SetTotalStages(total int) {
+	if oh.stages == nil {
+		return
+	}
+	oh.stages.mu.Lock()
+	defer oh.stages.mu.Unlock()
+	oh.stages.total = max(total, 0)
+}
+
+// ResetStages restarts stage numbering at 1 and forgets the total, such as before
+// running another command
+func (oh *outputHandler) ResetStages() {
+	if oh.stages == nil {
+		return
+	}
+	oh.stages.mu.Lock()
+	defer oh.stages.mu.Unlock()
+	oh.stages.count, oh.stages.total = 0, 0
+}
+
+// numberStage prefixes a stage message with its number under NumberStages. Stages that
+// are not printed are not counted.
+func (oh *outputHandler) numberStage(message string) string {
+	if !oh.config.NumberStages || oh.stages == nil || oh.config.DisableOutput || oh.isQuieted(LevelStage) {
+		return message
+	}
+	return oh.stages.next() + " " + message
+}
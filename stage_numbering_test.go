@@ -0,0 +1,75 @@
+package palantir
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNumberStages(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithNumberStages().WithWriter(&buf).Build())
+
+	handler.PrintStage("fetch")
+	handler.WithPrefix("api").PrintStage("build")
+	handler.SetTotalStages(5)
+	handler.PrintStage("test")
+	handler.PrintInfo("not a stage")
+	handler.ResetStages()
+	handler.PrintStage("fetch again")
+
+	expected := "[STAGE] [1/?] fetch\n[api] [STAGE] [2/?] build\n[STAGE] [3/5] test\nnot a stage\n[STAGE] [1/?] fetch again\n"
+	if buf.String() != expected {
+		t.Errorf("Output =\n%q\nwant:\n%q", buf.String(), expected)
+	}
+}
+
+func TestNumberStagesDisabledByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(&buf).Build())
+
+	handler.SetTotalStages(3)
+	handler.PrintStage("fetch")
+	if buf.String() != "[STAGE] fetch\n" {
+		t.Errorf("Output = %q, want %q", buf.String(), "[STAGE] fetch\n")
+	}
+}
+
+func TestNumberStagesSkipsSuppressedStages(t *testing.T) {
+	var buf bytes.Buffer
+	config := NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithNumberStages().WithQuiet().WithWriter(&buf).Build()
+	handler := NewOutputHandler(config)
+
+	handler.PrintStage("hidden")
+	handler.PrintStage("also hidden")
+	config.QuietMode = false
+	handler.PrintStage("shown")
+
+	if buf.String() != "[STAGE] [1/?] shown\n" {
+		t.Errorf("Expected quieted stages not to be counted, got %q", buf.String())
+	}
+}
+
+func TestNumberStagesConcurrently(t *testing.T) {
+	var buf lockedBuffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithNumberStages().WithWriter(&buf).Build())
+	handler.SetTotalStages(20)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.PrintStage("step")
+		}()
+	}
+	wg.Wait()
+
+	for i := 1; i <= 20; i++ {
+		if label := fmt.Sprintf("[%d/20] step\n", i); strings.Count(buf.String(), label) != 1 {
+			t.Errorf("Expected %q exactly once, got:\n%s", label, buf.String())
+		}
+	}
+}
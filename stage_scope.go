@@ -0,0 +1,168 @@
+package palantir
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StageScope is a stage of a pipeline holding numbered sub-stages, such as "2. Build"
+// containing "2.1 compile" and "2.2 test". The built-in handler indents its lines by its
+// depth, and End prints a summary with the stage's duration. A failure in a nested scope
+// marks every enclosing scope as failed.
+type StageScope struct {
+	handler *outputHandler // Indents the scope's lines; nil when printing through out
+	out     OutputHandler
+	parent  *StageScope
+	name    string
+	number  string // Hierarchical number, such as "2.1"
+	depth   int    // 0 for a scope begun on the handler
+	start   time.Time
+
+	mu       sync.Mutex
+	children int  // Sub-stages and nested scopes numbered so far
+	failed   bool // Set when a nested scope failed
+	ended    bool
+}
+
+// BeginStage prints a numbered stage, such as "2. Build", and returns its scope. Top-level
+// stages share their numbering with NumberStages.
+func (oh *outputHandler) BeginStage(name string) *StageScope {
+	scope := &StageScope{handler: oh, out: oh}
+	return scope.begin(nil, name, fmt.Sprint(oh.stages.nextScope()), 0)
+}
+
+// beginStage prints a numbered top-level stage through out and returns its scope, for
+// handlers other than the built-in one. Its lines are not indented.
+func beginStage(out OutputHandler, stages *stageCounter, name string) *StageScope {
+	scope := &StageScope{out: out}
+	return scope.begin(nil, name, fmt.Sprint(stages.nextScope()), 0)
+}
+
+// begin prints the opening line of a new scope and starts timing it
+func (s *StageScope) begin(parent *StageScope, name, number string, depth int) *StageScope {
+	s.parent, s.name, s.number, s.depth, s.start = parent, name, number, depth, nowFunc()
+	s.printStage(depth, scopeLabel(number, depth)+name)
+	return s
+}
+
+// BeginStage prints a nested stage numbered below this one, such as "2.3 package", and
+// returns its scope
+func (s *StageScope) BeginStage(name string) *StageScope {
+	scope := &StageScope{handler: s.handler, out: s.out}
+	return scope.begin(s, name, s.nextNumber(), s.depth+1)
+}
+
+// PrintSubStage prints a numbered step of this stage, such as "2.1 compile", without
+// opening a scope for it
+func (s *StageScope) PrintSubStage(name string) {
+	s.printStage(s.depth+1, scopeLabel(s.nextNumber(), s.depth+1)+name)
+}
+
+// PrintStage prints an unnumbered message within this stage
+func (s *StageScope) PrintStage(message string) {
+	s.printStage(s.depth+1, message)
+}
+
+// End prints the stage's summary with its duration: a success, or an error when err is not
+// nil or a nested scope failed, which also fails the enclosing scopes. It returns err, and
+// later calls do nothing.
+func (s *StageScope) End(err error) error {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return err
+	}
+	s.ended = true
+	failed := s.failed || err != nil
+	s.mu.Unlock()
+
+	duration := nowFunc().Sub(s.start)
+	switch {
+	case err != nil:
+		s.printResult(LevelError, fmt.Sprintf("%s failed: %v", s.name, err), duration)
+	case failed:
+		s.printResult(LevelError, s.name+" failed", duration)
+	default:
+		s.printResult(LevelSuccess, s.name, duration)
+	}
+
+	for parent := s.parent; failed && parent != nil; parent = parent.parent {
+		parent.mu.Lock()
+		parent.failed = true
+		parent.mu.Unlock()
+	}
+	return err
+}
+
+// printStage prints a stage line of the scope, indented by depth on the built-in handler
+func (s *StageScope) printStage(depth int, message string) {
+	if s.handler != nil {
+		s.handler.printIndented(LevelStage, depth, message, "")
+		return
+	}
+	s.out.PrintStage(message)
+}
+
+// printResult prints the scope's summary as a success or an error followed by its duration
+func (s *StageScope) printResult(level OutputLevel, message string, d time.Duration) {
+	switch {
+	case s.handler != nil:
+		s.handler.printIndented(level, s.depth, message, s.handler.durationSuffix(d))
+	case level == LevelError:
+		s.out.PrintError("%s (%s)", message, formatDuration(d))
+	default:
+		s.out.PrintSuccessWithDuration(message, d)
+	}
+}
+
+// nextScope counts a top-level scope as a stage, returning its number. A nil counter
+// numbers every scope 1.
+func (c *stageCounter) nextScope() int {
+	if c == nil {
+		return 1
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count++
+	return c.count
+}
+
+// nextNumber numbers the next sub-stage or nested scope, such as "2.3"
+func (s *StageScope) nextNumber() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.children++
+	return fmt.Sprintf("%s.%d", s.number, s.children)
+}
+
+// scopeLabel formats a stage number before its name: "2. " at the top level and "2.1 "
+// below it
+func scopeLabel(number string, depth int) string {
+	if depth == 0 {
+		return number + ". "
+	}
+	return number + " "
+}
+
+// printIndented prints a message indented by two spaces per depth, before its level prefix,
+// and followed by suffix, such as a duration. Errors count towards ErrorCount.
+func (oh *outputHandler) printIndented(level OutputLevel, depth int, message, suffix string) {
+	if level == LevelError {
+		oh.errs.add(1)
+	}
+	if oh.config.DisableOutput || oh.isQuieted(level) {
+		return
+	}
+	if suffix != "" {
+		suffix = " " + suffix
+	}
+	if oh.config.githubActions() && oh.printWorkflowCommand(level, oh.tidyMessage(message)+stripANSI(suffix)) {
+		return
+	}
+
+	indent := strings.Repeat("  ", depth)
+	formatted := strings.TrimSuffix(oh.FormatMessage(level, message), "\n")
+	oh.write(level, indent+strings.ReplaceAll(formatted, "\n", "\n"+indent)+suffix+"\n")
+}
@@ -0,0 +1,75 @@
+package palantir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStageScopeTranscript(t *testing.T) {
+	stubTickingNow(t, 500*time.Millisecond)
+	var buf bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(&buf).Build())
+
+	fetch := handler.BeginStage("Fetch")
+	fetch.PrintSubStage("download")
+	fetch.End(nil)
+
+	build := handler.BeginStage("Build")
+	build.PrintSubStage("compile")
+	test := build.BeginStage("test")
+	test.PrintStage("running 12 tests")
+	err := errors.New("2 tests failed")
+	if got := test.End(err); got != err {
+		t.Errorf("End() = %v, want %v", got, err)
+	}
+	build.PrintSubStage("package")
+	build.End(nil)
+	build.End(nil) // Ending twice prints nothing
+
+	assertGolden(t, "stage_scope", buf.String())
+	if handler.ErrorCount() != 2 {
+		t.Errorf("ErrorCount() = %d, want 2", handler.ErrorCount())
+	}
+}
+
+func TestStageScopeSharesNumberStages(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithNumberStages().WithWriter(&buf).Build())
+
+	handler.PrintStage("fetch")
+	handler.BeginStage("build")
+
+	expected := "[STAGE] [1/?] fetch\n[STAGE] 2. build\n"
+	if buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestStageScopeThroughWrappers(t *testing.T) {
+	stubTickingNow(t, 500*time.Millisecond)
+	var buf bytes.Buffer
+	inner := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(&buf).Build())
+	handler := NewPrefixedHandler(inner, "api").WithWidth(0)
+
+	handler.BeginStage("Fetch").End(nil)
+	build := handler.BeginStage("Build")
+	build.PrintSubStage("compile")
+	build.BeginStage("test").End(errors.New("2 tests failed"))
+	build.End(nil)
+
+	expected := "[STAGE] [api] 1. Fetch\n" +
+		"[SUCCESS] [api] Fetch (500ms)\n" +
+		"[STAGE] [api] 2. Build\n" +
+		"[STAGE] [api] 2.1 compile\n" +
+		"[STAGE] [api] 2.2 test\n" +
+		"[ERROR] [api] test failed: 2 tests failed (500ms)\n" +
+		"[ERROR] [api] Build failed (1.5s)\n"
+	if buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+	if inner.ErrorCount() != 2 {
+		t.Errorf("ErrorCount() = %d, want 2", inner.ErrorCount())
+	}
+}
@@ -0,0 +1,306 @@
+package palantir
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TaskStatus is the state of a Task in a TaskList
+type TaskStatus int
+
+const (
+	TaskPending TaskStatus = iota // Added but not started
+	TaskRunning                   // Started and not finished
+	TaskDone                      // Finished with Success
+	TaskFailed                    // Finished with Fail
+)
+
+// taskPendingGlyph marks pending tasks in a live TaskList
+const taskPendingGlyph = "◌"
+
+// TaskList shows named tasks with their status, like the block printed by docker compose.
+// On a terminal the block is repainted in place as tasks change, with a spinner for the
+// running ones. Elsewhere, and through wrapping handlers such as PrefixedHandler, each
+// change is printed as a line, such as "[SUCCESS] db (1.2s)". Tasks may finish in any
+// order and from several goroutines. Other output printed while the block is live is
+// overwritten by the next repaint.
+type TaskList struct {
+	handler *outputHandler // The handler drawing the list, nil when it is printed through out
+	out     OutputHandler  // The handler the list was created on
+	live    bool           // Whether the block is repainted in place
+
+	mu        sync.Mutex
+	tasks     []*Task
+	block     liveBlock
+	frame     int           // Spinner frame of running tasks
+	stop      chan struct{} // Closed to stop the spinner goroutine, nil when it is not running
+	spinnerID int           // Registration of the spinner goroutine for Close
+}
+
+// Task is a task of a TaskList, created by AddTask
+type Task struct {
+	list     *TaskList
+	name     string
+	status   TaskStatus
+	detail   string
+	err      error
+	start    time.Time
+	duration time.Duration
+}
+
+// NewTaskList creates an empty task list, live when output is a terminal
+func (oh *outputHandler) NewTaskList() *TaskList {
+	return &TaskList{handler: oh, out: oh, live: oh.redrawable()}
+}
+
+// newTaskList creates an empty task list printing each change as a line through out, for
+// handlers other than the built-in one
+func newTaskList(out OutputHandler) *TaskList {
+	return &TaskList{out: out}
+}
+
+// AddTask adds a pending task at the end of the list
+func (l *TaskList) AddTask(name string) *Task {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	task := &Task{list: l, name: name}
+	l.tasks = append(l.tasks, task)
+	if l.live {
+		l.paint()
+	}
+	return task
+}
+
+// Start marks a pending task as running
+func (t *Task) Start() {
+	t.list.update(t, false, func() bool {
+		if t.status != TaskPending {
+			return false
+		}
+		t.status, t.start = TaskRunning, nowFunc()
+		return true
+	})
+}
+
+// Success marks a task as done, with the time since Start
+func (t *Task) Success() {
+	t.finish(TaskDone, nil)
+}
+
+// Fail marks a task as failed with err, which may be nil. Failures count towards ErrorCount.
+func (t *Task) Fail(err error) {
+	t.finish(TaskFailed, err)
+}
+
+// SetDetail shows detail next to the task's name, such as "pulling image"
+func (t *Task) SetDetail(detail string) {
+	t.list.update(t, true, func() bool {
+		if t.detail == detail || t.status == TaskDone || t.status == TaskFailed {
+			return false
+		}
+		t.detail = detail
+		return true
+	})
+}
+
+// Status returns the task's current status
+func (t *Task) Status() TaskStatus {
+	t.list.mu.Lock()
+	defer t.list.mu.Unlock()
+	return t.status
+}
+
+// finish marks a task as done or failed. Finished tasks do not change anymore.
+func (t *Task) finish(status TaskStatus, err error) {
+	t.list.update(t, false, func() bool {
+		if t.status == TaskDone || t.status == TaskFailed {
+			return false
+		}
+		if !t.start.IsZero() {
+			t.duration = nowFunc().Sub(t.start)
+		}
+		t.status, t.err = status, err
+		if status == TaskFailed && t.list.handler != nil {
+			t.list.handler.errs.add(1)
+		}
+		return true
+	})
+}
+
+// update applies change to a task and shows it when it changed anything: by repainting
+// the block when live, and otherwise by printing the task's new state or detail
+func (l *TaskList) update(t *Task, detail bool, change func() bool) {
+	defer recoverPanic(l.out.Config())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !change() {
+		return
+	}
+	if !l.live {
+		l.printTransition(t, detail)
+		return
+	}
+	l.paint()
+	if t.status == TaskRunning && l.stop == nil {
+		l.stop = make(chan struct{})
+		l.spinnerID = l.handler.spinners.add(l.stopAnimation)
+		go l.animate(l.stop)
+	}
+}
+
+// printTransition prints a task's new state, or its new detail, as a line. Errors were
+// already counted by the built-in handler; other handlers count them as they print them.
+func (l *TaskList) printTransition(t *Task, detail bool) {
+	oh := l.handler
+	if oh == nil {
+		l.printTransitionTo(l.out, t, detail)
+		return
+	}
+	switch {
+	case detail:
+		oh.printMessage(LevelInfo, t.label())
+	case t.status == TaskRunning:
+		oh.printMessage(LevelStage, t.label())
+	case t.status == TaskDone:
+		oh.printMessage(LevelSuccess, fmt.Sprintf("%s %s", t.name, oh.durationSuffix(t.duration)))
+	case t.status == TaskFailed:
+		oh.printMessage(LevelError, fmt.Sprintf("%s %s", t.failure(), oh.durationSuffix(t.duration)))
+	}
+}
+
+// printTransitionTo prints a task's new state, or its new detail, through the methods of a
+// handler other than the built-in one
+func (l *TaskList) printTransitionTo(out OutputHandler, t *Task, detail bool) {
+	switch {
+	case detail:
+		out.PrintInfo("%s", t.label())
+	case t.status == TaskRunning:
+		out.PrintStage(t.label())
+	case t.status == TaskDone:
+		out.PrintSuccessWithDuration(t.name, t.duration)
+	case t.status == TaskFailed:
+		out.PrintError("%s (%s)", t.failure(), formatDuration(t.duration))
+	}
+}
+
+// paint redraws the block with l.mu held. Every task is redrawn at once, so tasks
+// finishing out of order cannot corrupt it.
+func (l *TaskList) paint() {
+	lines := make([]string, len(l.tasks))
+	for i, task := range l.tasks {
+		lines[i] = l.line(task)
+	}
+	l.block.paint(l.handler.writerFor(LevelInfo), lines)
+}
+
+// line formats a task of the live block, such as "✅ db (1.2s)"
+func (l *TaskList) line(t *Task) string {
+	oh := l.handler
+	colors := oh.config.UseColors && oh.config.UseFormatting
+	switch t.status {
+	case TaskRunning:
+		glyph := spinnerFrames[l.frame%len(spinnerFrames)]
+		if colors {
+			glyph = ColorCyan + glyph + ColorReset
+		}
+		return glyph + " " + t.label()
+	case TaskDone:
+		return l.levelLine(LevelSuccess, t.name, t.duration)
+	case TaskFailed:
+		return l.levelLine(LevelError, t.failure(), t.duration)
+	}
+	if colors {
+		return ColorDim + taskPendingGlyph + " " + t.label() + ColorReset
+	}
+	return taskPendingGlyph + " " + t.label()
+}
+
+// levelLine formats a finished task with its level's emoji or prefix, color and duration
+func (l *TaskList) levelLine(level OutputLevel, text string, d time.Duration) string {
+	oh := l.handler
+	style := oh.config.levelStyle(level)
+	glyph := style.Prefix
+	if oh.config.UseColors && oh.config.emojisEnabled() && oh.config.UseFormatting {
+		glyph = style.Emoji
+	}
+	if oh.config.UseColors && oh.config.UseFormatting && style.Color != "" {
+		text = style.Color + text + ColorReset
+	}
+	return glyph + text + " " + oh.durationSuffix(d)
+}
+
+// label is the task's name followed by its detail, such as "db: pulling image"
+func (t *Task) label() string {
+	if t.detail == "" {
+		return t.name
+	}
+	return t.name + ": " + t.detail
+}
+
+// failure describes a failed task, such as "db failed: connection refused"
+func (t *Task) failure() string {
+	if t.err == nil {
+		return t.name + " failed"
+	}
+	return fmt.Sprintf("%s failed: %v", t.name, t.err)
+}
+
+// animate advances the spinner of running tasks until none is left or stop is closed
+func (l *TaskList) animate(stop chan struct{}) {
+	defer recoverPanic(l.out.Config())
+
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		l.mu.Lock()
+		if l.stop != stop { // Stopped while waiting for the lock
+			l.mu.Unlock()
+			return
+		}
+		if !l.running() {
+			l.stopLocked()
+			l.mu.Unlock()
+			return
+		}
+		l.frame++
+		l.paint()
+		l.mu.Unlock()
+	}
+}
+
+// stopAnimation stops the spinner of running tasks, such as when the handler is closed.
+// The block keeps its last state.
+func (l *TaskList) stopAnimation() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopLocked()
+}
+
+// stopLocked stops the spinner goroutine with l.mu held
+func (l *TaskList) stopLocked() {
+	if l.stop == nil {
+		return
+	}
+	close(l.stop)
+	l.stop = nil
+	l.handler.spinners.remove(l.spinnerID)
+}
+
+// running reports whether any task is running, with l.mu held
+func (l *TaskList) running() bool {
+	for _, task := range l.tasks {
+		if task.status == TaskRunning {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package palantir
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeScreen is a terminal that applies the cursor movements of a live TaskList, so tests
+// can assert what ends up on screen. It is safe for use by the spinner goroutine.
+type fakeScreen struct {
+	mu       sync.Mutex
+	lines    []string
+	row, col int
+}
+
+func (s *fakeScreen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	text := string(p)
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "\033["):
+			end := i + 2
+			for end < len(text) && text[end] >= '0' && text[end] <= '9' {
+				end++
+			}
+			count, _ := strconv.Atoi(text[i+2 : end])
+			switch text[end] {
+			case 'A':
+				s.row = max(s.row-count, 0)
+			case 'K':
+				s.line()
+				s.lines[s.row] = s.lines[s.row][:s.col]
+			}
+			i = end
+		case text[i] == '\r':
+			s.col = 0
+		case text[i] == '\n':
+			s.row, s.col = s.row+1, 0
+		default:
+			line := s.line()
+			s.lines[s.row] = line[:s.col] + text[i:i+1] + line[min(s.col+1, len(line)):]
+			s.col++
+		}
+	}
+	return len(p), nil
+}
+
+// line returns the line under the cursor, padded to the cursor's column
+func (s *fakeScreen) line() string {
+	for len(s.lines) <= s.row {
+		s.lines = append(s.lines, "")
+	}
+	if len(s.lines[s.row]) < s.col {
+		s.lines[s.row] += strings.Repeat(" ", s.col-len(s.lines[s.row]))
+	}
+	return s.lines[s.row]
+}
+
+// screen returns the non-empty lines on screen
+func (s *fakeScreen) screen() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []string
+	for _, line := range s.lines {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestTaskListLiveOutOfOrder(t *testing.T) {
+	clock := stubFakeClock(t)
+	screen := &fakeScreen{}
+	stubTerminal(t, screen)
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(screen).Build())
+
+	list := handler.NewTaskList()
+	db, api, web := list.AddTask("db"), list.AddTask("api"), list.AddTask("web")
+	if got := strings.Join(screen.screen(), "|"); got != "◌ db|◌ api|◌ web" {
+		t.Errorf("Expected pending tasks, got %q", got)
+	}
+
+	db.Start()
+	api.Start()
+	web.Start()
+	api.SetDetail("migrating")
+	lines := screen.screen()
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], " db") || !strings.HasSuffix(lines[1], " api: migrating") {
+		t.Errorf("Expected running tasks with their detail, got %q", lines)
+	}
+
+	clock.Advance(time.Second)
+	web.Success()
+	clock.Advance(time.Second)
+	db.Fail(errors.New("connection refused"))
+	clock.Advance(time.Second)
+	api.Success()
+
+	expected := []string{
+		"[ERROR] db failed: connection refused (2.0s)",
+		"[SUCCESS] api (3.0s)",
+		"[SUCCESS] web (1.0s)",
+	}
+	if got := screen.screen(); strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Final block = %q, want %q", got, expected)
+	}
+	if handler.ErrorCount() != 1 {
+		t.Errorf("Expected the failed task to count as an error, got %d", handler.ErrorCount())
+	}
+}
+
+func TestTaskListLiveEmoji(t *testing.T) {
+	stubFakeClock(t)
+	screen := &fakeScreen{}
+	stubTerminal(t, screen)
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithWriter(screen).Build())
+
+	list := handler.NewTaskList()
+	build, test := list.AddTask("build"), list.AddTask("test")
+	build.Start()
+	test.Start()
+	test.Fail(nil)
+	build.Success()
+
+	var got []string
+	for _, line := range screen.screen() {
+		got = append(got, stripANSI(line))
+	}
+	expected := []string{"✅ build (0ms)", "❌ test failed (0ms)"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Final block = %q, want %q", got, expected)
+	}
+}
+
+func TestTaskListCloseStopsSpinner(t *testing.T) {
+	stubFakeClock(t)
+	screen := &fakeScreen{}
+	stubTerminal(t, screen)
+	handler := NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithWriter(screen).Build())
+
+	list := handler.NewTaskList()
+	list.AddTask("build").Start()
+	if err := handler.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	if list.stop != nil {
+		t.Error("Expected Close to stop the spinner of running tasks")
+	}
+}
+
+func TestTaskListTranscript(t *testing.T) {
+	clock := stubFakeClock(t)
+	var buf bytes.Buffer
+	handler := newTimedStageHandler(&buf)
+
+	list := handler.NewTaskList()
+	db, api := list.AddTask("db"), list.AddTask("api")
+	db.Start()
+	api.Start()
+	api.SetDetail("migrating")
+	clock.Advance(time.Second)
+	api.Success()
+	clock.Advance(time.Second)
+	db.Fail(errors.New("connection refused"))
+	db.Success() // Finished tasks do not change anymore
+
+	expected := "[STAGE] db\n[STAGE] api\napi: migrating\n[SUCCESS] api (1.0s)\n[ERROR] db failed: connection refused (2.0s)\n"
+	if buf.String() != expected {
+		t.Errorf("Transcript = %q, want %q", buf.String(), expected)
+	}
+	if db.Status() != TaskFailed || api.Status() != TaskDone {
+		t.Errorf("Expected failed and done statuses, got %v and %v", db.Status(), api.Status())
+	}
+}
+
+func TestTaskListThroughWrappers(t *testing.T) {
+	clock := stubFakeClock(t)
+	var buf bytes.Buffer
+	inner := newTimedStageHandler(&buf)
+	handlers := map[string]OutputHandler{
+		"Prefixed": NewPrefixedHandler(inner, "api").WithWidth(0),
+		"Filtered": NewLevelFilterHandler(inner, LevelError),
+	}
+	expected := map[string]string{
+		"Prefixed": "[STAGE] [api] db\n[api] db: migrating\n[ERROR] [api] db failed: connection refused (1.0s)\n",
+		"Filtered": "[ERROR] db failed: connection refused (1.0s)\n",
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+			inner.Reset()
+			db := handler.NewTaskList().AddTask("db")
+			db.Start()
+			db.SetDetail("migrating")
+			clock.Advance(time.Second)
+			db.Fail(errors.New("connection refused"))
+
+			if buf.String() != expected[name] {
+				t.Errorf("Transcript = %q, want %q", buf.String(), expected[name])
+			}
+			if inner.ErrorCount() != 1 {
+				t.Errorf("Expected the failure to be counted once, got %d", inner.ErrorCount())
+			}
+		})
+	}
+}
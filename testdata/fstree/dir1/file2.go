@@ -0,0 +1 @@
+package dir1
@@ -0,0 +1,80 @@
+package palantir
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimedStage prints name as a stage, runs fn and reports how long it took: a success such
+// as "Downloaded artifacts (2.4s)", or an error such as "Downloaded artifacts failed: ...
+// (2.4s)". It returns fn's error.
+func (oh *outputHandler) TimedStage(name string, fn func() error) error {
+	return timedStage(name, fn, oh.PrintStage, oh.printStageResult)
+}
+
+// TimedStageContext is TimedStage for work that honors a context. When ctx is done before
+// fn returns, the timer stops at that moment and the stage reports ctx's error once fn
+// has returned, so fn should return promptly when ctx is done.
+func (oh *outputHandler) TimedStageContext(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return timedStageContext(ctx, name, fn, oh.PrintStage, oh.printStageResult)
+}
+
+// timedStage runs TimedStage, printing the stage and its result with the given functions
+func timedStage(name string, fn func() error, printStage func(name string), printResult func(name string, err error, d time.Duration)) error {
+	printStage(name)
+	start := nowFunc()
+	err := fn()
+	printResult(name, err, nowFunc().Sub(start))
+	return err
+}
+
+// timedStageContext runs TimedStageContext, printing the stage and its result with the
+// given functions
+func timedStageContext(ctx context.Context, name string, fn func(ctx context.Context) error,
+	printStage func(name string), printResult func(name string, err error, d time.Duration)) error {
+	printStage(name)
+	start := nowFunc()
+
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+		printResult(name, err, nowFunc().Sub(start))
+	case <-ctx.Done():
+		stopped := nowFunc()
+		if err = <-done; err == nil {
+			err = ctx.Err()
+		}
+		printResult(name, err, stopped.Sub(start))
+	}
+	return err
+}
+
+// printStageResult reports the outcome of a timed stage with its duration
+func (oh *outputHandler) printStageResult(name string, err error, d time.Duration) {
+	if err != nil {
+		oh.printIndented(LevelError, 0, fmt.Sprintf("%s failed: %v", name, err), oh.durationSuffix(d))
+		return
+	}
+	oh.PrintSuccessWithDuration(name, d)
+}
+
+// printStageResultTo reports the outcome of a timed stage through a handler other than
+// the built-in one
+func printStageResultTo(out OutputHandler, name string, err error, d time.Duration) {
+	if err != nil {
+		out.PrintError("%s failed: %v (%s)", name, err, formatDuration(d))
+		return
+	}
+	out.PrintSuccessWithDuration(name, d)
+}
+
+// stageResultPrinter returns printStageResultTo bound to out
+func stageResultPrinter(out OutputHandler) func(name string, err error, d time.Duration) {
+	return func(name string, err error, d time.Duration) {
+		printStageResultTo(out, name, err, d)
+	}
+}
@@ -0,0 +1,152 @@
+package palantir
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock for nowFunc that only moves when advanced, calling onNow with
+// the number of times it was read
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	reads int
+	onNow func(reads int)
+}
+
+func stubFakeClock(t *testing.T) *fakeClock {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	original := nowFunc
+	nowFunc = clock.Now
+	t.Cleanup(func() { nowFunc = original })
+	return clock
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	c.reads++
+	now, reads, onNow := c.now, c.reads, c.onNow
+	c.mu.Unlock()
+
+	if onNow != nil {
+		onNow(reads)
+	}
+	return now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTimedStageHandler(buf *bytes.Buffer) *outputHandler {
+	return NewOutputHandler(NewConfigBuilder().WithCIMode(CIModeNone).WithColors(false).WithWriter(buf).Build())
+}
+
+func TestTimedStage(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		err      error
+		expected string
+	}{
+		{"Success in milliseconds", 350 * time.Millisecond, nil, "[STAGE] Downloaded artifacts\n[SUCCESS] Downloaded artifacts (350ms)\n"},
+		{"Success in seconds", 2400 * time.Millisecond, nil, "[STAGE] Downloaded artifacts\n[SUCCESS] Downloaded artifacts (2.4s)\n"},
+		{"Error beyond a minute", 95 * time.Second, errors.New("checksum mismatch"), "[STAGE] Downloaded artifacts\n[ERROR] Downloaded artifacts failed: checksum mismatch (1m35s)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := stubFakeClock(t)
+			var buf bytes.Buffer
+			handler := newTimedStageHandler(&buf)
+
+			err := handler.TimedStage("Downloaded artifacts", func() error {
+				clock.Advance(tt.duration)
+				return tt.err
+			})
+
+			if err != tt.err {
+				t.Errorf("TimedStage() error = %v, want %v", err, tt.err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Output = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestTimedStageContextStopsOnCancellation(t *testing.T) {
+	clock := stubFakeClock(t)
+	stopped := make(chan struct{})
+	clock.onNow = func(reads int) {
+		if reads == 2 { // The start, then the moment of cancellation
+			close(stopped)
+		}
+	}
+
+	var buf bytes.Buffer
+	handler := newTimedStageHandler(&buf)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := handler.TimedStageContext(ctx, "deploy", func(ctx context.Context) error {
+		clock.Advance(time.Second)
+		cancel()
+		<-stopped
+		clock.Advance(5 * time.Second) // Cleanup after cancellation is not timed
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("TimedStageContext() error = %v, want %v", err, context.Canceled)
+	}
+	expected := "[STAGE] deploy\n[ERROR] deploy failed: context canceled (1.0s)\n"
+	if buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestTimedStageContextCompletes(t *testing.T) {
+	clock := stubFakeClock(t)
+	var buf bytes.Buffer
+	handler := newTimedStageHandler(&buf)
+
+	err := handler.TimedStageContext(context.Background(), "build", func(ctx context.Context) error {
+		clock.Advance(1500 * time.Millisecond)
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("TimedStageContext() error = %v", err)
+	}
+	if expected := "[STAGE] build\n[SUCCESS] build (1.5s)\n"; buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestTimedStageThroughWrappers(t *testing.T) {
+	clock := stubFakeClock(t)
+	var buf bytes.Buffer
+	inner := newTimedStageHandler(&buf)
+
+	var handler OutputHandler = NewPrefixedHandler(inner, "api").WithWidth(0)
+	handler.TimedStage("Migrate", func() error {
+		clock.Advance(2 * time.Second)
+		return nil
+	})
+	handler = NewLevelFilterHandler(inner, LevelError)
+	handler.TimedStageContext(context.Background(), "Seed", func(ctx context.Context) error {
+		return errors.New("duplicate key")
+	})
+
+	expected := "[STAGE] [api] Migrate\n[SUCCESS] [api] Migrate (2.0s)\n[ERROR] Seed failed: duplicate key (0ms)\n"
+	if buf.String() != expected {
+		t.Errorf("Output = %q, want %q", buf.String(), expected)
+	}
+}
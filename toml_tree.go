@@ -0,0 +1,174 @@
+package palantir
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ParseTOMLToTree converts TOML content to a TreeNode structure built from YAMLNodes, so
+// TOML trees share the YAML styling. Tables become object nodes, arrays of tables become
+// arrays of objects and dotted keys expand into nested nodes. Keys keep their document order.
+func ParseTOMLToTree(tomlContent []byte, opts ...BuildOption) (*TreeNode, error) {
+	var data map[string]interface{}
+	metadata, err := toml.Decode(string(tomlContent), &data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+
+	base, err := newYAMLTreeBuilder(newBuildOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	builder := &tomlTreeBuilder{yamlTreeBuilder: base, keyOrder: tomlKeyOrder(metadata.Keys())}
+
+	root := &TreeNode{
+		Name:     "root",
+		Data:     YAMLNode{Name: "root", IsDir: true, NodeType: "object"},
+		Children: nil,
+	}
+
+	value, err := builder.build(root, data, "", 0, false)
+	if err != nil {
+		return nil, err
+	}
+	setYAMLContainerValue(root, value)
+	return root, nil
+}
+
+// tomlTreeBuilder builds TreeNodes from decoded TOML data
+type tomlTreeBuilder struct {
+	*yamlTreeBuilder                           // Shares options, the depth limit and redaction with YAML trees
+	keyOrder         map[string]map[string]int // Document position of each key, by table path
+}
+
+// tomlKeyOrder records the position of every key within its table in document order.
+// Keys in arrays of tables share their array's path.
+func tomlKeyOrder(keys []toml.Key) map[string]map[string]int {
+	order := make(map[string]map[string]int)
+	for _, key := range keys {
+		for i := range key {
+			table := strings.Join(key[:i], ".")
+			if order[table] == nil {
+				order[table] = make(map[string]int)
+			}
+			if _, seen := order[table][key[i]]; !seen {
+				order[table][key[i]] = len(order[table])
+			}
+		}
+	}
+	return order
+}
+
+// build adds the members of a table or array to node and returns the plain Go value,
+// converting arrays of tables to []interface{}
+func (b *tomlTreeBuilder) build(node *TreeNode, value interface{}, path string, depth int, redacted bool) (interface{}, error) {
+	if depth > b.maxDepth {
+		return nil, fmt.Errorf("TOML nesting exceeds maximum depth of %d", b.maxDepth)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// Handle tables
+		for _, key := range b.sortedKeys(v, path) {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			childRedacted := b.isRedacted(key, redacted)
+			child := &TreeNode{Name: key, Children: nil}
+			childValue, err := b.build(child, v[key], childPath, depth+1, childRedacted)
+			if err != nil {
+				return nil, err
+			}
+
+			child.Data = newValueYAMLNode(key, childValue, childRedacted)
+			node.Children = append(node.Children, child)
+			v[key] = childValue
+		}
+		return v, nil
+	case []map[string]interface{}:
+		// Handle arrays of tables
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return b.build(node, items, path, depth, redacted)
+	case []interface{}:
+		// Handle arrays
+		for i, item := range v {
+			child := &TreeNode{Children: nil}
+			itemValue, err := b.build(child, item, path, depth+1, redacted)
+			if err != nil {
+				return nil, err
+			}
+
+			itemName := yamlItemName(itemValue, i)
+			if redacted && !isJSONContainer(itemValue) {
+				itemName = RedactionMask // Scalar items are named after their value
+			}
+			itemData := newValueYAMLNode(itemName, itemValue, redacted)
+			itemData.Item, itemData.Index = true, i
+			child.Name = itemName
+			child.Data = itemData
+			node.Children = append(node.Children, child)
+			v[i] = itemValue
+		}
+		return v, nil
+	default:
+		// Handle scalar values: string, int64, float64, bool or time.Time
+		return v, nil
+	}
+}
+
+// sortedKeys returns the keys of a table in document order
+func (b *tomlTreeBuilder) sortedKeys(table map[string]interface{}, path string) []string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+
+	order := b.keyOrder[path]
+	sort.Slice(keys, func(i, j int) bool {
+		iPos, iKnown := order[keys[i]]
+		jPos, jKnown := order[keys[j]]
+		if iKnown != jKnown {
+			return iKnown
+		}
+		if iKnown {
+			return iPos < jPos
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// ShowTOMLHierarchy displays TOML content as a tree structure. Keys keep their
+// document order unless WithKeyOrder(KeyOrderAlphabetical) is given.
+func ShowTOMLHierarchy(tomlContent []byte, opts ...BuildOption) error {
+	root, err := ParseTOMLToTree(tomlContent, opts...)
+	if err != nil {
+		return err
+	}
+
+	options := newBuildOptions(opts)
+	if options.KeyOrder == KeyOrderAlphabetical {
+		sortTree(root)
+	}
+	renderLimitedTree(root, options)
+	return nil
+}
+
+// ShowTOMLHierarchyFromFile reads and displays a TOML file as a tree structure, up to the WithMaxReadSize limit.
+// A path of "-" reads standard input.
+func ShowTOMLHierarchyFromFile(filePath string, opts ...BuildOption) error {
+	file, err := openInput(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read TOML file: %w", err)
+	}
+	defer file.Close()
+	return ShowTOMLHierarchyFromReader(file, opts...)
+}
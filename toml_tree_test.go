@@ -0,0 +1,99 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+var cargoTOML = []byte(`
+[package]
+name = "palantir"
+version = "0.1.0"
+released = 2025-10-05T09:30:00Z
+build-date = 2025-10-05
+metadata.docs.rs.all-features = true
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+
+[[bin]]
+name = "server"
+path = "src/server.rs"
+
+[[bin]]
+name = "cli"
+path = "src/cli.rs"
+
+[registry]
+token = "s3cr3t"
+`)
+
+func TestParseTOMLToTree(t *testing.T) {
+	root, err := ParseTOMLToTree(cargoTOML)
+	if err != nil {
+		t.Fatalf("ParseTOMLToTree() error = %v", err)
+	}
+
+	if names := childNames(root); strings.Join(names, ",") != "package,dependencies,bin,registry" {
+		t.Errorf("Expected document key order, got %v", names)
+	}
+
+	pkg := findChild(root, "package")
+	if names := childNames(pkg); strings.Join(names, ",") != "name,version,released,build-date,metadata" {
+		t.Errorf("Expected package keys in document order, got %v", names)
+	}
+
+	// Dotted keys expand into nested nodes
+	allFeatures := findChild(findChild(findChild(findChild(pkg, "metadata"), "docs"), "rs"), "all-features")
+	if allFeatures == nil || allFeatures.Data.(YAMLNode).Value != true {
+		t.Fatalf("Expected metadata.docs.rs.all-features to be expanded, got %+v", allFeatures)
+	}
+
+	// Arrays of tables become arrays of objects
+	bin := findChild(root, "bin")
+	if names := childNames(bin); strings.Join(names, ",") != "[0],[1]" {
+		t.Fatalf("Expected two [[bin]] entries, got %v", names)
+	}
+	if !isSequenceNode(bin.Data) {
+		t.Errorf("Expected [[bin]] to hold a sequence, got %+v", bin.Data)
+	}
+	if names := childNames(bin.Children[1]); strings.Join(names, ",") != "name,path" {
+		t.Errorf("Expected table keys inside [[bin]], got %v", names)
+	}
+}
+
+func TestShowTOMLHierarchyValues(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		err := ShowTOMLHierarchy(cargoTOML, WithShowValues(), WithRedactKeys(DefaultSecretPatterns...))
+		if err != nil {
+			t.Errorf("ShowTOMLHierarchy() error = %v", err)
+		}
+	})
+
+	assertGolden(t, "toml_values", output)
+}
+
+func TestShowTOMLHierarchyMaxDepth(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowTOMLHierarchy(cargoTOML, WithMaxDepth(2))
+	})
+
+	if strings.Contains(output, "docs") || !strings.Contains(output, "… (3 nested items)") {
+		t.Errorf("Expected metadata to be collapsed, got:\n%s", output)
+	}
+}
+
+func TestParseTOMLToTreeMalformed(t *testing.T) {
+	_, err := ParseTOMLToTree([]byte("[package\nname = "))
+	if err == nil || !strings.Contains(err.Error(), "failed to parse TOML") {
+		t.Errorf("Expected a parse error, got %v", err)
+	}
+
+	if err := ShowTOMLHierarchyFromFile("testdata/missing.toml"); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
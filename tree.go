@@ -1,13 +1,14 @@
 package palantir
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 // Tree display constants
@@ -18,84 +19,502 @@ const (
 	Space    = "    "
 )
 
+// TreeConnectorStyle selects the characters used to draw tree connectors
+type TreeConnectorStyle int
+
+const (
+	// TreeStyleSharp draws connectors with square corners, such as "├──" and "└──"
+	TreeStyleSharp TreeConnectorStyle = iota
+	// TreeStyleRounded draws the last connector with a rounded corner, "╰──"
+	TreeStyleRounded
+	// TreeStyleDouble draws connectors with double lines, such as "╠══" and "╚══"
+	TreeStyleDouble
+)
+
+// treeConnectors holds the four strings that make up a tree's structure
+type treeConnectors struct {
+	branch   string
+	last     string
+	vertical string
+	space    string
+}
+
+// treeStyles maps each connector style to its connectors
+var treeStyles = map[TreeConnectorStyle]treeConnectors{
+	TreeStyleSharp:   {branch: Branch, last: Last, vertical: Vertical, space: Space},
+	TreeStyleRounded: {branch: "├── ", last: "╰── ", vertical: "│   ", space: Space},
+	TreeStyleDouble:  {branch: "╠══ ", last: "╚══ ", vertical: "║   ", space: Space},
+}
+
+// asciiTreeConnectors are used instead of any style when OutputConfig.ASCIIOnly is set
+var asciiTreeConnectors = treeConnectors{branch: "|-- ", last: "`-- ", vertical: "|   ", space: Space}
+
+// treeConnectorsFor returns the connectors selected by an OutputConfig
+func treeConnectorsFor(config *OutputConfig) treeConnectors {
+	if config.ASCIIOnly {
+		return asciiTreeConnectors
+	}
+	if connectors, ok := treeStyles[config.TreeStyle]; ok {
+		return connectors
+	}
+	return treeStyles[TreeStyleSharp]
+}
+
 // TreeNode represents a simple tree node for display purposes only
 type TreeNode struct {
 	Name     string
 	Data     interface{} // Can be FileNode or YAMLNode
 	Children []*TreeNode
+
+	childIndex      map[string]int // Position of the first child with each name, built by ChildNamed
+	indexedChildren int            // Number of children when childIndex was built
 }
 
 // FileNode represents a file or directory in the filesystem tree
 type FileNode struct {
-	Name    string
-	Path    string
-	IsDir   bool
-	Size    int64
-	ModTime int64
+	Name      string
+	Path      string
+	IsDir     bool
+	Size      int64
+	ModTime   int64
+	GitStatus GitStatus // Only populated when the tree is built with WithGitStatus
+	Comment   string    // Description rendered, dimmed, after the name, such as "# entry points"
+
+	LinkTarget string // Target of a symbolic link as stored in the link, only read from the local filesystem
+	LinkBroken bool   // Set on symbolic links whose target does not exist
+}
+
+// BuildOptions controls how trees are built and rendered
+type BuildOptions struct {
+	ShowRoot    bool // Print the root node's name before its children
+	GitStatus   bool // Mark entries with their git working tree status
+	GitColors   bool // With GitStatus, color names by status and follow them with the porcelain code, such as "new.txt ??"
+	DirSlash    bool // Append a trailing "/" to directory names
+	LinkTargets bool // Follow symbolic links with their target, such as "current -> releases/v2"
+	Summary     bool // Follow filesystem trees with a report such as "3 directories, 9 files (1.5 KB)"
+	Sort        bool // Sort filesystem trees directories first, then by name; set by default, YAML order follows KeyOrder
+
+	ReportTiming bool // Follow filesystem trees with how long the walk took, such as "scanned 12 nodes in 3ms"
+
+	Comments      map[string]string // Descriptions for nodes keyed by slash-separated path relative to the root, such as "cmd"
+	AlignComments bool              // Start comments in one column instead of right after each name
+
+	IncludeDirs bool // Include directories in path listings such as RenderHierarchyPaths
+	SkipErrors  bool // Keep walking after an error in WalkHierarchy instead of stopping
+
+	ShowValues       bool     // Render YAML leaves as "key: value"
+	AlignValues      bool     // Line up the values of sibling leaves in a column
+	ShowArrayIndices bool     // Prefix array items with their dimmed index, such as "[0] users"
+	ShowTypes        bool     // Annotate scalars with their kind, such as "port: 5432 (int)"
+	ShowLineNumbers  bool     // Follow YAML keys with their dimmed source line, such as "port:412"
+	ShowComments     bool     // Render YAML comments, dimmed, above and after the nodes they belong to
+	MaxValueLength   int      // Truncate displayed values longer than this many characters (DefaultMaxValueLength by default), 0 disables truncation
+	KeyOrder         KeyOrder // Order of YAML mapping keys
+	ExpandAliases    bool     // Expand YAML aliases into copies of the anchored content
+	ShowMergeOrigins bool     // Mark keys merged in by a YAML "<<" merge key with a dimmed "(inherited)"
+	NestEmbedded     bool     // Show embedded struct fields below a node for the embedded type instead of flattening them
+	RedactKeys       []string // Mask the values below keys matching these glob or /regex/ patterns
+	AllowKeys        []string // Never redact keys matching these patterns, overriding RedactKeys
+
+	ChangedOnly     bool // Hide unchanged nodes in YAML diffs
+	UnorderedArrays bool // Compare arrays in YAML diffs as sets of values instead of by position
+
+	PathFilter    string // Only show nodes matching this path expression, such as "jobs[*].steps", with their ancestors
+	MaxDepth      int    // Collapse content nested deeper than this many levels into a marker, 0 disables the limit
+	MaxNodes      int    // Stop rendering after this many nodes and print a warning, 0 disables the limit
+	MaxLines      int    // Stop printing a tree after this many lines and say it was truncated, 0 disables the limit
+	MaxParseDepth int    // Reject YAML documents nested deeper than this, 0 uses DefaultMaxParseDepth
+	MaxReadSize   int64  // Most bytes read by the reader and file variants, 0 uses DefaultMaxReadSize
+
+	commentColumn int         // Column aligned comments start at, computed by renderTree
+	lineBudget    *lineBudget // Lines left to print under MaxLines, set by renderTree
+	output        io.Writer   // Where trees are printed, standard output when nil
+
+	nodeStyler func(root *TreeNode) func(node *TreeNode) (string, bool) // Prepares the custom styling of a tree's nodes, set by withNodeStyler
+	styleNode  func(node *TreeNode) (string, bool)                      // Custom styling of a node, reporting whether it applies, set by renderTree
+}
+
+// KeyOrder controls how the keys of YAML mappings are ordered in the tree
+type KeyOrder int
+
+const (
+	// KeyOrderOriginal keeps keys in the order they appear in the document
+	KeyOrderOriginal KeyOrder = iota
+	// KeyOrderAlphabetical sorts keys alphabetically, containers first
+	KeyOrderAlphabetical
+)
+
+// BuildOption configures a BuildOptions value
+type BuildOption func(*BuildOptions)
+
+// WithShowRoot labels the tree with the root node's name, like `tree .` does
+func WithShowRoot() BuildOption {
+	return func(o *BuildOptions) {
+		o.ShowRoot = true
+	}
+}
+
+// WithoutSorting keeps filesystem trees in the order they were walked, which is lexical
+// order with directories and files mixed, instead of listing directories first
+func WithoutSorting() BuildOption {
+	return func(o *BuildOptions) {
+		o.Sort = false
+	}
+}
+
+// WithGitStatus marks modified, untracked and staged entries when the root is inside a git repository
+func WithGitStatus() BuildOption {
+	return func(o *BuildOptions) {
+		o.GitStatus = true
+	}
+}
+
+// WithSummary follows filesystem trees with a count of their directories and files and
+// their total size, like the report printed by `tree`
+func WithSummary() BuildOption {
+	return func(o *BuildOptions) {
+		o.Summary = true
+	}
+}
+
+// WithGitColors colors entries by their git status and follows them with the porcelain
+// status code instead of leading them with a marker. It implies WithGitStatus.
+func WithGitColors() BuildOption {
+	return func(o *BuildOptions) {
+		o.GitStatus = true
+		o.GitColors = true
+	}
+}
+
+// WithReportTiming follows filesystem trees with the number of nodes walked and how long
+// the walk took, to help diagnose slow renders of large directories
+func WithReportTiming() BuildOption {
+	return func(o *BuildOptions) {
+		o.ReportTiming = true
+	}
+}
+
+// WithDirSlash appends a trailing "/" to directories so they stand out without colors
+func WithDirSlash() BuildOption {
+	return func(o *BuildOptions) {
+		o.DirSlash = true
+	}
+}
+
+// WithLinkTargets follows symbolic links with where they point, noting broken links
+func WithLinkTargets() BuildOption {
+	return func(o *BuildOptions) {
+		o.LinkTargets = true
+	}
+}
+
+// WithComments attaches descriptions to the nodes at the given relative paths, such as
+// {"cmd": "entry points"}
+func WithComments(comments map[string]string) BuildOption {
+	return func(o *BuildOptions) {
+		o.Comments = comments
+	}
+}
+
+// WithAlignComments lines node comments up in a single column
+func WithAlignComments() BuildOption {
+	return func(o *BuildOptions) {
+		o.AlignComments = true
+	}
+}
+
+// WithIncludeDirs lists directories alongside files in path listings
+func WithIncludeDirs() BuildOption {
+	return func(o *BuildOptions) {
+		o.IncludeDirs = true
+	}
+}
+
+// WithSkipErrors keeps WalkHierarchy going after unreadable entries, which are still reported as events
+func WithSkipErrors() BuildOption {
+	return func(o *BuildOptions) {
+		o.SkipErrors = true
+	}
+}
+
+// WithShowValues renders YAML leaves as "key: value" with the value colored by its type
+func WithShowValues() BuildOption {
+	return func(o *BuildOptions) {
+		o.ShowValues = true
+	}
+}
+
+// WithAlignValues lines up the values shown by WithShowValues in a column within each
+// group of siblings
+func WithAlignValues() BuildOption {
+	return func(o *BuildOptions) {
+		o.AlignValues = true
+	}
+}
+
+// WithShowArrayIndices prefixes array items with their index, so items keep their
+// position even when they are named after their value
+func WithShowArrayIndices() BuildOption {
+	return func(o *BuildOptions) {
+		o.ShowArrayIndices = true
+	}
+}
+
+// WithShowTypes annotates scalars with a dimmed kind such as "(int)" or "(string)",
+// after their value when values are shown
+func WithShowTypes() BuildOption {
+	return func(o *BuildOptions) {
+		o.ShowTypes = true
+	}
+}
+
+// WithShowLineNumbers follows YAML keys and items with the line they appear on in the
+// source document, such as "port:412"
+func WithShowLineNumbers() BuildOption {
+	return func(o *BuildOptions) {
+		o.ShowLineNumbers = true
+	}
+}
+
+// WithShowComments renders the comments of YAML documents: comments above a key as
+// dimmed lines above its node, and comments at the end of a line after the node
+func WithShowComments() BuildOption {
+	return func(o *BuildOptions) {
+		o.ShowComments = true
+	}
+}
+
+// WithMaxValueLength truncates displayed values longer than n characters, or disables truncation when n is 0
+func WithMaxValueLength(n int) BuildOption {
+	return func(o *BuildOptions) {
+		o.MaxValueLength = n
+	}
+}
+
+// WithKeyOrder selects how YAML mapping keys are ordered (document order by default)
+func WithKeyOrder(order KeyOrder) BuildOption {
+	return func(o *BuildOptions) {
+		o.KeyOrder = order
+	}
+}
+
+// WithExpandAliases expands YAML aliases into copies of the anchored content
+// instead of rendering them as references. Documents made mostly of such copies,
+// such as the "billion laughs" attack, are rejected with an error.
+func WithExpandAliases() BuildOption {
+	return func(o *BuildOptions) {
+		o.ExpandAliases = true
+	}
+}
+
+// WithShowMergeOrigins marks keys a YAML mapping inherits through "<<" merge keys
+func WithShowMergeOrigins() BuildOption {
+	return func(o *BuildOptions) {
+		o.ShowMergeOrigins = true
+	}
+}
+
+// WithNestEmbedded shows the fields of embedded structs below a node named after the
+// embedded type instead of flattening them into the outer struct
+func WithNestEmbedded() BuildOption {
+	return func(o *BuildOptions) {
+		o.NestEmbedded = true
+	}
+}
+
+// WithChangedOnly hides unchanged nodes when showing YAML diffs
+func WithChangedOnly() BuildOption {
+	return func(o *BuildOptions) {
+		o.ChangedOnly = true
+	}
+}
+
+// WithUnorderedArrays compares arrays in YAML diffs as sets of values, so reordered
+// items are not reported as changes
+func WithUnorderedArrays() BuildOption {
+	return func(o *BuildOptions) {
+		o.UnorderedArrays = true
+	}
+}
+
+// WithRedactKeys masks the values below mapping keys matching any of the patterns, such
+// as DefaultSecretPatterns. Patterns are case-insensitive globs, or regular expressions
+// when wrapped in slashes.
+func WithRedactKeys(patterns ...string) BuildOption {
+	return func(o *BuildOptions) {
+		o.RedactKeys = append(o.RedactKeys, patterns...)
+	}
+}
+
+// WithAllowKeys exempts keys matching any of the patterns from redaction
+func WithAllowKeys(patterns ...string) BuildOption {
+	return func(o *BuildOptions) {
+		o.AllowKeys = append(o.AllowKeys, patterns...)
+	}
+}
+
+// WithPathFilter shows only the nodes matching a path expression, such as
+// "database.credentials", "jobs[*].steps" or "**.image", with their ancestors and
+// everything below them
+func WithPathFilter(expr string) BuildOption {
+	return func(o *BuildOptions) {
+		o.PathFilter = expr
+	}
+}
+
+// WithMaxDepth collapses content nested deeper than depth levels into a "… (N nested items)" marker
+func WithMaxDepth(depth int) BuildOption {
+	return func(o *BuildOptions) {
+		o.MaxDepth = depth
+	}
+}
+
+// WithMaxNodes stops rendering after n nodes and prints a warning about the omitted remainder
+func WithMaxNodes(n int) BuildOption {
+	return func(o *BuildOptions) {
+		o.MaxNodes = n
+	}
+}
+
+// WithMaxLines stops printing a tree after n lines, protecting the terminal from huge trees
+func WithMaxLines(n int) BuildOption {
+	return func(o *BuildOptions) {
+		o.MaxLines = n
+	}
+}
+
+// WithMaxParseDepth rejects YAML documents nested deeper than depth levels
+func WithMaxParseDepth(depth int) BuildOption {
+	return func(o *BuildOptions) {
+		o.MaxParseDepth = depth
+	}
+}
+
+// WithMaxReadSize limits how many bytes the reader and file variants read before failing
+func WithMaxReadSize(n int64) BuildOption {
+	return func(o *BuildOptions) {
+		o.MaxReadSize = n
+	}
+}
+
+// writer returns the writer trees are printed to
+func (o BuildOptions) writer() io.Writer {
+	if o.output != nil {
+		return o.output
+	}
+	return os.Stdout
+}
+
+// newBuildOptions applies the given options on top of the defaults
+func newBuildOptions(opts []BuildOption) BuildOptions {
+	options := BuildOptions{Sort: true, MaxValueLength: DefaultMaxValueLength}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
 }
 
 // ShowHierarchy displays a tree structure of files/directories
-func ShowHierarchy(basePath, targetDir string) (error, bool) {
-	// Get root directory info
-	rootInfo, err := os.Stat(basePath)
+func ShowHierarchy(basePath, targetDir string, opts ...BuildOption) (error, bool) {
+	options := newBuildOptions(opts)
+
+	root, stats, err := NewOSTreeBuilder().BuildWithStats(basePath)
 	if err != nil {
-		return fmt.Errorf("failed to stat path: %w", err), false
+		return err, false
 	}
 
-	root := &TreeNode{
-		Name: rootInfo.Name(),
-		Data: FileNode{
-			Name:    rootInfo.Name(),
-			Path:    basePath,
-			IsDir:   rootInfo.IsDir(),
-			Size:    rootInfo.Size(),
-			ModTime: rootInfo.ModTime().Unix(),
-		},
-		Children: nil,
+	if options.GitStatus {
+		applyGitStatus(root, basePath)
 	}
 
-	// Build tree structure by walking filesystem
-	err = buildTree(root, basePath)
-	if err != nil {
-		return fmt.Errorf("failed to build tree: %w", err), false
+	return showTimedFileTree(root, stats, options)
+}
+
+// showTimedFileTree is showFileTree, following the tree with its timing report under ReportTiming
+func showTimedFileTree(root *TreeNode, stats TreeStats, options BuildOptions) (error, bool) {
+	err, shown := showFileTree(root, options)
+	if shown && options.ReportTiming {
+		fmt.Fprintf(options.writer(), "\n%s\n", timingReport(stats))
 	}
+	return err, shown
+}
 
+// showFileTree sorts, unless sorting is disabled, and renders a built filesystem tree, reporting whether a hierarchy was shown
+func showFileTree(root *TreeNode, options BuildOptions) (error, bool) {
 	// Check if tree has only one node and it's not a directory
 	if len(root.Children) == 1 && !getIsDir(root.Children[0].Data) {
 		return nil, false // No hierarchy needed
 	}
 
 	// Directories first, then alphabetically
-	sortTree(root)
-	printTree(root, "", true, true)
+	if options.Sort {
+		sortTree(root)
+	}
+	applyComments(root, options.Comments)
+
+	// Summarize before limits drop nodes from the tree
+	var summary string
+	if options.Summary {
+		summary = hierarchySummary(root)
+	}
+	renderLimitedTree(root, options)
+	if options.Summary {
+		fmt.Fprintf(options.writer(), "\n%s\n", summary)
+	}
 
 	return nil, true
 }
 
 // buildTree recursively builds a tree structure from the filesystem
 func buildTree(node *TreeNode, dirPath string) error {
-	return filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+	info, err := os.Lstat(dirPath)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return nil // A single file has no children
+	}
+
+	pathFor := func(relPath string) string {
+		return filepath.Join(dirPath, filepath.FromSlash(relPath))
+	}
+	return walkFS(node, os.DirFS(dirPath), ".", pathFor, func(relPath string) (string, bool) {
+		return readOSLink(pathFor(relPath))
+	})
+}
+
+// walkFS adds every non-hidden entry below root in fsys to node. pathFor converts
+// a slash-separated path relative to root into the path stored on each FileNode, and
+// readLink, when not nil, returns the target of the symbolic link at a relative path
+// and whether it is broken.
+func walkFS(node *TreeNode, fsys fs.FS, root string, pathFor func(relPath string) string, readLink func(relPath string) (string, bool)) error {
+	return fs.WalkDir(fsys, root, func(fsPath string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if path == dirPath {
+		if fsPath == root {
 			return nil // Skip root directory itself
 		}
 
 		// Skip hidden files
-		if strings.HasPrefix(filepath.Base(path), ".") {
-			if info.IsDir() {
-				return filepath.SkipDir
+		if strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return fs.SkipDir
 			}
 			return nil
 		}
 
-		// Get relative path and split into components
-		relPath, err := filepath.Rel(dirPath, path)
+		info, err := entry.Info()
 		if err != nil {
 			return err
 		}
-		parts := strings.Split(relPath, string(filepath.Separator))
+
+		// Get relative path and split into components
+		relPath := fsPath
+		if root != "." {
+			relPath = strings.TrimPrefix(fsPath, root+"/")
+		}
+		parts := strings.Split(relPath, "/")
 
 		// Find or create the parent node
 		current := node
@@ -116,7 +535,7 @@ func buildTree(node *TreeNode, dirPath string) error {
 					Name: part,
 					Data: FileNode{
 						Name:  part,
-						Path:  filepath.Join(dirPath, strings.Join(parts[:i+1], string(filepath.Separator))),
+						Path:  pathFor(strings.Join(parts[:i+1], "/")),
 						IsDir: true,
 					},
 					Children: nil,
@@ -127,15 +546,19 @@ func buildTree(node *TreeNode, dirPath string) error {
 		}
 
 		// Add the final node
+		fileNode := FileNode{
+			Name:    info.Name(),
+			Path:    pathFor(relPath),
+			IsDir:   info.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime().Unix(),
+		}
+		if entry.Type()&fs.ModeSymlink != 0 && readLink != nil {
+			fileNode.LinkTarget, fileNode.LinkBroken = readLink(relPath)
+		}
 		finalNode := &TreeNode{
-			Name: parts[len(parts)-1],
-			Data: FileNode{
-				Name:    info.Name(),
-				Path:    path,
-				IsDir:   info.IsDir(),
-				Size:    info.Size(),
-				ModTime: info.ModTime().Unix(),
-			},
+			Name:     parts[len(parts)-1],
+			Data:     fileNode,
 			Children: nil,
 		}
 		current.Children = append(current.Children, finalNode)
@@ -144,13 +567,25 @@ func buildTree(node *TreeNode, dirPath string) error {
 	})
 }
 
-// sortTree recursively sorts all children in the tree (directories first, then files, both alphabetically)
+// sortTree recursively sorts all children in the tree (directories first, then files, both alphabetically).
+// The items of YAML sequences keep their document order since it is semantically meaningful.
 func sortTree(node *TreeNode) {
 	if len(node.Children) == 0 {
 		return
 	}
 
-	// Sort children: directories first, then files, both alphabetically
+	if !isSequenceNode(node.Data) {
+		sortChildren(node)
+	}
+
+	// Recursively sort children
+	for _, child := range node.Children {
+		sortTree(child)
+	}
+}
+
+// sortChildren sorts the direct children of a node: directories first, then files, both alphabetically
+func sortChildren(node *TreeNode) {
 	sort.Slice(node.Children, func(i, j int) bool {
 		// Get IsDir from the appropriate data type
 		iIsDir := getIsDir(node.Children[i].Data)
@@ -161,11 +596,31 @@ func sortTree(node *TreeNode) {
 		}
 		return node.Children[i].Name < node.Children[j].Name
 	})
+}
 
-	// Recursively sort children
-	for _, child := range node.Children {
-		sortTree(child)
+// Prune recursively removes filesystem directory nodes that have no files below them, such
+// as directories left empty after filtering. Other nodes, such as empty YAML mappings and
+// sequences, are kept, and the node it is called on is never removed.
+func (n *TreeNode) Prune() {
+	kept := n.Children[:0]
+	for _, child := range n.Children {
+		child.Prune()
+		if fileNode, ok := child.Data.(FileNode); ok && fileNode.IsDir && len(child.Children) == 0 {
+			continue
+		}
+		kept = append(kept, child)
 	}
+	n.Children = kept
+}
+
+// isSequenceNode reports whether a node holds a YAML sequence, whose items must not be reordered
+func isSequenceNode(data interface{}) bool {
+	yamlNode, ok := data.(YAMLNode)
+	if !ok {
+		return false
+	}
+	_, isSequence := yamlNode.Value.([]interface{})
+	return isSequence
 }
 
 // getIsDir extracts IsDir from either FileNode or YAMLNode
@@ -176,29 +631,88 @@ func getIsDir(data interface{}) bool {
 	if yamlNode, ok := data.(YAMLNode); ok {
 		return yamlNode.IsDir
 	}
+	if diffNode, ok := data.(DiffNode); ok {
+		return diffNode.IsDir
+	}
 	return false
 }
 
-// printTree recursively prints a tree node with ASCII art and colors
-func printTree(node *TreeNode, prefix string, isLast bool, isRoot bool) {
+// renderTree prints a whole tree, optionally labelled with the root node's name
+func renderTree(root *TreeNode, options BuildOptions) {
+	defer recoverPanic(treeOutputConfig())
+	flushGlobalOutput()
+
+	if options.AlignComments {
+		options.commentColumn = commentColumn(root, options)
+	}
+	if options.MaxLines > 0 {
+		options.lineBudget = &lineBudget{max: options.MaxLines}
+	}
+	if options.nodeStyler != nil {
+		options.styleNode = options.nodeStyler(root)
+	}
+
+	if treeOutputConfig().accessible() {
+		printAccessibleTree(root, 1, options)
+	} else if !options.ShowRoot || printTreeLine(withNodeComment(styleTreeNode(root, options), root, options), options) {
+		printTree(root, "", true, true, 0, options)
+	}
+
+	printTruncationNotice(options)
+}
+
+// renderTreeString renders a whole tree, labelled with the root node's name, into a string
+// without its final newline
+func renderTreeString(root *TreeNode, opts []BuildOption) string {
+	options := newBuildOptions(append(opts, WithShowRoot()))
+	var buf bytes.Buffer
+	options.output = &buf
+	renderLimitedTree(root, options)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// printTree recursively prints a tree node with ASCII art and colors. keyWidth is the
+// width its value is aligned to when AlignValues is set.
+func printTree(node *TreeNode, prefix string, isLast bool, isRoot bool, keyWidth int, options BuildOptions) {
+	connectors := treeConnectorsFor(treeOutputConfig())
+
 	if !isRoot {
 		// Choose the appropriate tree character
 		var treeChar string
 		if isLast {
-			treeChar = Last
+			treeChar = connectors.last
 		} else {
-			treeChar = Branch
+			treeChar = connectors.branch
 		}
 
-		styledName := styleFileNode(node)
+		if options.ShowComments {
+			for _, line := range headCommentLines(node) {
+				if !printTreeLine(prefix+connectors.vertical+styleDim(line), options) {
+					return
+				}
+			}
+		}
+
+		styledName := styleAlignedTreeNode(node, keyWidth, options)
 
 		// Print the current node
-		fmt.Printf("%s%s%s\n", prefix, treeChar, styledName)
+		if !printTreeLine(withNodeComment(prefix+treeChar+styledName, node, options), options) {
+			return
+		}
 	}
 
 	// Print children
 	if len(node.Children) > 0 {
+		childKeyWidth := 0
+		if options.AlignValues {
+			childKeyWidth = valueKeyWidth(node.Children, options)
+		}
+
 		for i, child := range node.Children {
+			if options.lineBudget.exhausted() {
+				options.lineBudget.truncated = true // The remaining children are withheld
+				return
+			}
 			isChildLast := i == len(node.Children)-1
 
 			// Calculate prefix for child
@@ -207,61 +721,270 @@ func printTree(node *TreeNode, prefix string, isLast bool, isRoot bool) {
 				childPrefix = ""
 			} else {
 				if isLast {
-					childPrefix = prefix + Space
+					childPrefix = prefix + connectors.space
 				} else {
-					childPrefix = prefix + Vertical
+					childPrefix = prefix + connectors.vertical
 				}
 			}
 
-			printTree(child, childPrefix, isChildLast, false)
+			printTree(child, childPrefix, isChildLast, false, childKeyWidth, options)
+		}
+	}
+}
+
+// styleTreeNode styles a node and adds the markers enabled in options
+func styleTreeNode(node *TreeNode, options BuildOptions) string {
+	return styleAlignedTreeNode(node, 0, options)
+}
+
+// styleAlignedTreeNode styles a node, padding the key of a "key: value" line to keyWidth
+// columns so the values of siblings line up
+func styleAlignedTreeNode(node *TreeNode, keyWidth int, options BuildOptions) string {
+	if options.styleNode != nil {
+		if styled, ok := options.styleNode(node); ok {
+			return styled
+		}
+	}
+	if diffNode, ok := node.Data.(DiffNode); ok {
+		return styleDiffNode(diffNode, options)
+	}
+
+	key := styleTreeNodeKey(node, options)
+	value := styleTreeNodeValue(node, options)
+	suffix := styleTypeHint(node, options) + styleMergeOrigin(node, options) + styleLineComment(node, options)
+	if value == "" {
+		return key + suffix
+	}
+	padding := strings.Repeat(" ", max(0, keyWidth-displayWidth(key)))
+	return key + ":" + padding + " " + value + suffix
+}
+
+// styleMergeOrigin returns a dimmed " (inherited)" for keys merged in by a YAML merge key
+// when ShowMergeOrigins is set
+func styleMergeOrigin(node *TreeNode, options BuildOptions) string {
+	yamlNode, ok := node.Data.(YAMLNode)
+	if !ok || !options.ShowMergeOrigins || !yamlNode.Inherited {
+		return ""
+	}
+	return " " + styleDim("(inherited)")
+}
+
+// styleLineComment returns the dimmed comment ending a YAML node's line when ShowComments is set
+func styleLineComment(node *TreeNode, options BuildOptions) string {
+	yamlNode, ok := node.Data.(YAMLNode)
+	if !ok || !options.ShowComments || yamlNode.LineComment == "" {
+		return ""
+	}
+	return " " + styleDim(stripControlChars(yamlNode.LineComment))
+}
+
+// headCommentLines returns the lines of the comment above a YAML node, without blank lines
+func headCommentLines(node *TreeNode) []string {
+	yamlNode, ok := node.Data.(YAMLNode)
+	if !ok || yamlNode.HeadComment == "" {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(stripControlChars(yamlNode.HeadComment), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// styleTypeHint returns the dimmed kind of a scalar, such as " (int)", when ShowTypes is set
+func styleTypeHint(node *TreeNode, options BuildOptions) string {
+	yamlNode, ok := node.Data.(YAMLNode)
+	if !ok || !options.ShowTypes || yamlNode.NodeType != "scalar" || yamlNode.ScalarKind == "" {
+		return ""
+	}
+	return " " + styleDim("("+yamlNode.ScalarKind+")")
+}
+
+// styleTreeNodeKey styles the name of a node with its markers, without its value
+func styleTreeNodeKey(node *TreeNode, options BuildOptions) string {
+	styledName := styleFileNode(node)
+	if options.GitColors {
+		if colored := styleGitColoredName(node); colored != "" {
+			styledName = colored
+		}
+	}
+
+	if options.DirSlash && getIsDir(node.Data) {
+		styledName += "/"
+	}
+	if options.LinkTargets {
+		styledName += styleLinkTarget(node)
+	}
+
+	if yamlNode, ok := node.Data.(YAMLNode); ok {
+		if options.ShowLineNumbers && yamlNode.Line > 0 {
+			styledName += styleDim(fmt.Sprintf(":%d", yamlNode.Line))
+		}
+		if yamlNode.Anchor != "" && !options.ExpandAliases {
+			styledName += " " + styleDim("&"+yamlNode.Anchor)
+		}
+		if yamlNode.NodeType == "alias" {
+			styledName += styleDim(" → (ref)")
+		}
+		if yamlNode.NodeType == "cycle" {
+			styledName += styleDim(" ↩ cycle")
+		}
+		if yamlNode.Item {
+			styledName = styleArrayItem(yamlNode, styledName, options)
+		}
+	}
+
+	if options.GitColors {
+		if code := styleGitStatusCode(node); code != "" {
+			styledName += " " + code
+		}
+	} else if options.GitStatus {
+		if marker := styleGitStatus(node); marker != "" {
+			styledName = marker + " " + styledName
 		}
 	}
+
+	return styledName
+}
+
+// styleArrayItem adds the index of an array item when ShowArrayIndices is set, and describes
+// objects and arrays, which are named after their index, with a type hint such as
+// "(object, 4 keys)"
+func styleArrayItem(yamlNode YAMLNode, styledName string, options BuildOptions) string {
+	index := fmt.Sprintf("[%d]", yamlNode.Index)
+	namedByIndex := yamlNode.Name == index
+	if options.ShowArrayIndices {
+		if namedByIndex {
+			styledName = styleDim(index)
+		} else {
+			styledName = styleDim(index) + " " + styledName
+		}
+	}
+
+	switch value := yamlNode.Value.(type) {
+	case map[string]interface{}:
+		if namedByIndex {
+			styledName += " " + styleDim("(object, "+pluralize(len(value), "key", "keys")+")")
+		}
+	case []interface{}:
+		if namedByIndex {
+			styledName += " " + styleDim("(array, "+pluralize(len(value), "item", "items")+")")
+		}
+	}
+	return styledName
+}
+
+// pluralize formats a count followed by the singular or plural noun
+func pluralize(count int, singular, plural string) string {
+	if count == 1 {
+		return "1 " + singular
+	}
+	return formatCount(count) + " " + plural
+}
+
+// styleTreeNodeValue styles the value shown after a scalar's key, or returns an empty
+// string when values are hidden
+func styleTreeNodeValue(node *TreeNode, options BuildOptions) string {
+	yamlNode, ok := node.Data.(YAMLNode)
+	if !ok || !options.ShowValues || yamlNode.NodeType != "scalar" || yamlNode.Item {
+		return "" // Scalar array items already show their value as their name
+	}
+	if yamlNode.Redacted {
+		return styleRedactedValue(yamlNode.Value)
+	}
+	return styleYAMLValue(yamlNode.Value, options)
+}
+
+// valueKeyWidth returns the widest key among the siblings that show a value, measured
+// in terminal columns. Siblings share their connector prefix, so aligning within a
+// group lines the values up on screen.
+func valueKeyWidth(siblings []*TreeNode, options BuildOptions) int {
+	width := 0
+	for _, sibling := range siblings {
+		if styleTreeNodeValue(sibling, options) == "" {
+			continue
+		}
+		width = max(width, displayWidth(styleTreeNodeKey(sibling, options)))
+	}
+	return width
+}
+
+// treeOutputConfig returns the configuration of the global handler, which trees use for styling.
+// Handlers without a configuration get plain output.
+func treeOutputConfig() *OutputConfig {
+	if config := GetGlobalOutputHandler().Config(); config != nil {
+		return config
+	}
+	return &OutputConfig{}
+}
+
+// styleDim dims text when colors are enabled
+func styleDim(text string) string {
+	outputConfig := treeOutputConfig()
+	if !outputConfig.UseColors {
+		return text
+	}
+	return fmt.Sprintf("%s%s%s", ColorDim, text, ColorReset)
+}
+
+// styleTreeText colors a name or value in a tree, in bold when bold is set and formatting
+// is enabled. Like messages under ColorizeLevelOnly, text stays plain then, leaving color
+// to markers such as git status codes and diff signs.
+func styleTreeText(text, color string, bold bool) string {
+	outputConfig := treeOutputConfig()
+	if !outputConfig.UseColors || outputConfig.ColorizeLevelOnly || color == "" {
+		return text
+	}
+	if bold && outputConfig.UseFormatting {
+		color = ColorBold + color
+	}
+	return fmt.Sprintf("%s%s%s", color, text, ColorReset)
 }
 
 // styleFileNode styles a filesystem node based on OutputConfig
 func styleFileNode(node *TreeNode) string {
-	outputConfig := GetGlobalOutputHandler().(*outputHandler).config
+	outputConfig := treeOutputConfig()
 
 	if !outputConfig.UseColors {
 		return node.Name
 	}
 
+	if _, ok := node.Data.(truncationMarker); ok {
+		return styleDim(node.Name)
+	}
+
 	// Handle FileNode
 	if fileNode, ok := node.Data.(FileNode); ok {
 		if fileNode.IsDir {
-			return fmt.Sprintf("%s%s%s%s", ColorBold, ColorBlue, fileNode.Name, ColorReset)
+			return styleTreeText(fileNode.Name, ColorBlue, true)
 		}
 
 		// Color customized based on extension
-		ext := strings.ToLower(filepath.Ext(fileNode.Name))
-		switch ext {
-		case ".json", ".yaml", ".yml", ".toml":
-			return fmt.Sprintf("%s%s%s", ColorGreen, fileNode.Name, ColorReset)
-		case ".md", ".txt", ".log":
-			return fmt.Sprintf("%s%s%s", ColorCyan, fileNode.Name, ColorReset)
-		case ".sh", ".zsh", ".bash":
-			return fmt.Sprintf("%s%s%s", ColorYellow, fileNode.Name, ColorReset)
-		case ".go":
-			return fmt.Sprintf("%s%s%s", ColorPurple, fileNode.Name, ColorReset)
-		default:
-			return fileNode.Name
+		if category, ok := fileCategoryFor(fileNode.Name); ok {
+			return styleTreeText(fileNode.Name, category.color, false)
 		}
+		return fileNode.Name
 	}
 
 	// Handle YAMLNode
 	if yamlNode, ok := node.Data.(YAMLNode); ok {
 		if yamlNode.IsDir {
-			return fmt.Sprintf("%s%s%s%s", ColorBold, ColorBlue, yamlNode.Name, ColorReset)
+			return styleTreeText(yamlNode.Name, ColorBlue, true)
 		}
 
 		// Color based on node type
 		switch yamlNode.NodeType {
 		case "object":
-			return fmt.Sprintf("%s%s%s%s", ColorBold, ColorBlue, yamlNode.Name, ColorReset)
+			return styleTreeText(yamlNode.Name, ColorBlue, true)
 		case "array":
-			return fmt.Sprintf("%s%s%s", ColorYellow, yamlNode.Name, ColorReset)
+			return styleTreeText(yamlNode.Name, ColorYellow, false)
 		case "scalar":
-			return fmt.Sprintf("%s%s%s", ColorGreen, yamlNode.Name, ColorReset)
+			return styleTreeText(yamlNode.Name, ColorGreen, false)
+		case "alias", "cycle":
+			return styleDim(yamlNode.Name)
 		default:
 			return yamlNode.Name
 		}
@@ -270,97 +993,3 @@ func styleFileNode(node *TreeNode) string {
 	// Fallback
 	return node.Name
 }
-
-// YAMLNode represents a YAML data node for tree visualization
-type YAMLNode struct {
-	Name     string
-	Value    interface{}
-	IsDir    bool
-	NodeType string // "object", "array", "scalar"
-}
-
-// ParseYAMLToTree converts YAML content to TreeNode structure
-func ParseYAMLToTree(yamlContent []byte) (*TreeNode, error) {
-	var data interface{}
-	if err := yaml.Unmarshal(yamlContent, &data); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
-	}
-
-	root := &TreeNode{
-		Name:     "root",
-		Data:     YAMLNode{Name: "root", Value: data, IsDir: true, NodeType: "object"},
-		Children: nil,
-	}
-
-	return buildYAMLTree(root, data), nil
-}
-
-// buildYAMLTree recursively builds a tree structure from YAML data
-func buildYAMLTree(node *TreeNode, data interface{}) *TreeNode {
-	switch v := data.(type) {
-	case map[string]interface{}:
-		// Handle objects
-		for key, value := range v {
-			child := &TreeNode{
-				Name:     key,
-				Data:     YAMLNode{Name: key, Value: value, IsDir: true, NodeType: "object"},
-				Children: nil,
-			}
-			node.Children = append(node.Children, buildYAMLTree(child, value))
-		}
-	case []interface{}:
-		// Handle arrays
-		for i, item := range v {
-			// Create a name with just the value for array items
-			var itemName string
-			switch itemValue := item.(type) {
-			case string:
-				itemName = itemValue
-			case int, int64, float64:
-				itemName = fmt.Sprintf("%v", itemValue)
-			case bool:
-				itemName = fmt.Sprintf("%t", itemValue)
-			default:
-				itemName = fmt.Sprintf("[%d]", i)
-			}
-
-			child := &TreeNode{
-				Name:     itemName,
-				Data:     YAMLNode{Name: itemName, Value: item, IsDir: false, NodeType: "array"},
-				Children: nil,
-			}
-			// Only recursively build if the item is a complex type (map or slice)
-			switch item.(type) {
-			case map[string]interface{}, []interface{}:
-				node.Children = append(node.Children, buildYAMLTree(child, item))
-			default:
-				// For scalar values, just add the child as-is
-				node.Children = append(node.Children, child)
-			}
-		}
-	default:
-		// Handle scalar values
-		node.Data = YAMLNode{Name: node.Name, Value: v, IsDir: false, NodeType: "scalar"}
-	}
-	return node
-}
-
-// ShowYAMLHierarchy displays YAML content as a tree structure
-func ShowYAMLHierarchy(yamlContent []byte) error {
-	root, err := ParseYAMLToTree(yamlContent)
-	if err != nil {
-		return fmt.Errorf("failed to parse YAML: %w", err)
-	}
-	sortTree(root)
-	printTree(root, "", true, true)
-	return nil
-}
-
-// ShowYAMLHierarchyFromFile reads and displays a YAML file as a tree structure
-func ShowYAMLHierarchyFromFile(filePath string) error {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read YAML file: %w", err)
-	}
-	return ShowYAMLHierarchy(content)
-}
@@ -0,0 +1,87 @@
+package palantir
+
+import (
+	"path"
+	"strings"
+)
+
+// commentGap is the number of spaces between the widest commented line and aligned comments
+const commentGap = 2
+
+// applyComments copies BuildOptions.Comments onto the FileNodes at the matching paths,
+// which are slash-separated and relative to the root, such as "cmd" or "docs/README.md"
+func applyComments(root *TreeNode, comments map[string]string) {
+	if len(comments) == 0 {
+		return
+	}
+
+	normalized := make(map[string]string, len(comments))
+	for relPath, comment := range comments {
+		normalized[path.Clean(strings.TrimSuffix(relPath, "/"))] = comment
+	}
+
+	var annotate func(node *TreeNode, relPath string)
+	annotate = func(node *TreeNode, relPath string) {
+		if fileNode, ok := node.Data.(FileNode); ok {
+			if comment, found := normalized[relPath]; found {
+				fileNode.Comment = comment
+				node.Data = fileNode
+			}
+		}
+		for _, child := range node.Children {
+			annotate(child, path.Join(relPath, child.Name))
+		}
+	}
+	annotate(root, ".")
+}
+
+// nodeComment returns the description attached to a filesystem node, if any
+func nodeComment(node *TreeNode) string {
+	if fileNode, ok := node.Data.(FileNode); ok {
+		return stripControlChars(strings.ReplaceAll(fileNode.Comment, "\n", " "))
+	}
+	return ""
+}
+
+// withNodeComment appends a node's dimmed comment to its rendered line. Comments follow
+// the name after a space, or start in the column computed by renderTree when AlignComments is set.
+func withNodeComment(line string, node *TreeNode, options BuildOptions) string {
+	comment := nodeComment(node)
+	if comment == "" {
+		return line
+	}
+
+	padding := " "
+	if options.AlignComments {
+		padding = strings.Repeat(" ", max(1, options.commentColumn-displayWidth(line)))
+	}
+	return line + padding + styleDim("# "+comment)
+}
+
+// commentColumn returns the column aligned comments start at: just past the widest line
+// that carries a comment
+func commentColumn(root *TreeNode, options BuildOptions) int {
+	connectors := treeConnectorsFor(treeOutputConfig())
+	indent := displayWidth(connectors.branch)
+
+	width := 0
+	if options.ShowRoot && nodeComment(root) != "" {
+		width = displayWidth(styleTreeNode(root, options))
+	}
+
+	var measure func(node *TreeNode, depth int)
+	measure = func(node *TreeNode, depth int) {
+		for _, child := range node.Children {
+			if nodeComment(child) != "" {
+				width = max(width, depth*indent+displayWidth(styleTreeNode(child, options)))
+			}
+			measure(child, depth+1)
+		}
+	}
+	measure(root, 1)
+
+	if width == 0 {
+		return 0
+	}
+	return width + commentGap
+}
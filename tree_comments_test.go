@@ -0,0 +1,78 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var commentsFixture = fstest.MapFS{
+	"cmd/demo/main.go":  {Data: []byte("package main")},
+	"docs/guide.md":     {Data: []byte("# guide")},
+	"go.mod":            {Data: []byte("module demo")},
+	"internal/store.go": {Data: []byte("package internal")},
+}
+
+var fixtureComments = map[string]string{
+	"cmd/":   "entry points",
+	"go.mod": "module definition",
+}
+
+func TestShowHierarchyComments(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowHierarchyFS(commentsFixture, ".", WithDirSlash(), WithComments(fixtureComments))
+	})
+
+	expected := "├── cmd/ # entry points\n" +
+		"│   └── demo/\n" +
+		"│       └── main.go\n" +
+		"├── docs/\n" +
+		"│   └── guide.md\n" +
+		"├── internal/\n" +
+		"│   └── store.go\n" +
+		"└── go.mod # module definition\n"
+	if output != expected {
+		t.Errorf("ShowHierarchyFS() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowHierarchyAlignComments(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	comments := map[string]string{
+		"cmd":              "entry points",
+		"cmd/demo/main.go": "demo binary",
+	}
+	output := captureOutput(func() {
+		ShowHierarchyFS(commentsFixture, ".", WithDirSlash(), WithComments(comments), WithAlignComments())
+	})
+
+	expected := "├── cmd/             # entry points\n" +
+		"│   └── demo/\n" +
+		"│       └── main.go  # demo binary\n" +
+		"├── docs/\n" +
+		"│   └── guide.md\n" +
+		"├── internal/\n" +
+		"│   └── store.go\n" +
+		"└── go.mod\n"
+	if output != expected {
+		t.Errorf("ShowHierarchyFS() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowHierarchyCommentsDimmed(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	output := captureOutput(func() {
+		ShowHierarchyFS(commentsFixture, ".", WithComments(fixtureComments))
+	})
+
+	if !strings.Contains(output, ColorDim+"# module definition"+ColorReset) {
+		t.Errorf("Expected a dimmed comment, got %q", output)
+	}
+}
@@ -0,0 +1,47 @@
+package palantir
+
+// Filter returns a copy of the tree rooted at n holding only the nodes keep accepts and
+// the ancestors needed to reach them, such as the directories leading to .go files.
+// Branches without accepted nodes are dropped, while Data and the order of children are
+// preserved. It returns nil when no node is accepted. n is not modified.
+func (n *TreeNode) Filter(keep func(*TreeNode) bool) *TreeNode {
+	var children []*TreeNode
+	for _, child := range n.Children {
+		if filtered := child.Filter(keep); filtered != nil {
+			children = append(children, filtered)
+		}
+	}
+
+	if len(children) == 0 && !keep(n) {
+		return nil
+	}
+	return &TreeNode{Name: n.Name, Data: n.Data, Children: children}
+}
+
+// FilterSubtrees returns copies of the topmost nodes keep accepts, each with all of its
+// descendants, in display order. Unlike Filter, ancestors are not kept, so the result is
+// a forest of the matching subtrees. n is not modified.
+func (n *TreeNode) FilterSubtrees(keep func(*TreeNode) bool) []*TreeNode {
+	if keep(n) {
+		return []*TreeNode{n.Copy()}
+	}
+
+	var subtrees []*TreeNode
+	for _, child := range n.Children {
+		subtrees = append(subtrees, child.FilterSubtrees(keep)...)
+	}
+	return subtrees
+}
+
+// Copy returns a deep copy of the tree rooted at n. Data values are copied as they are,
+// so the plain values held by FileNode and YAMLNode are shared with n.
+func (n *TreeNode) Copy() *TreeNode {
+	copied := &TreeNode{Name: n.Name, Data: n.Data}
+	if n.Children != nil {
+		copied.Children = make([]*TreeNode, len(n.Children))
+		for i, child := range n.Children {
+			copied.Children[i] = child.Copy()
+		}
+	}
+	return copied
+}
@@ -0,0 +1,107 @@
+package palantir
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// treeShape returns the slash-joined path of every node below root, in display order
+func treeShape(root *TreeNode) []string {
+	var paths []string
+	root.Walk(func(node *TreeNode, depth int, path []string) error {
+		if depth > 0 {
+			paths = append(paths, strings.Join(path, "/"))
+		}
+		return nil
+	})
+	return paths
+}
+
+func TestTreeNodeFilterFilesystem(t *testing.T) {
+	tempDir := createFileFixture(t, multiFileFixture)
+	root, err := NewOSTreeBuilder().Build(tempDir)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	sortTree(root)
+	before := treeShape(root)
+
+	goFiles := func(node *TreeNode) bool {
+		return filepath.Ext(node.Name) == ".go"
+	}
+
+	filtered := root.Filter(goFiles)
+	if shape := treeShape(filtered); !reflect.DeepEqual(shape, []string{"dir1", "dir1/file2.go"}) {
+		t.Errorf("Filter() kept %q", shape)
+	}
+	if filtered.Data.(FileNode).Path != tempDir {
+		t.Errorf("Expected the copy to keep the root's data, got %+v", filtered.Data)
+	}
+	if after := treeShape(root); !reflect.DeepEqual(after, before) {
+		t.Errorf("Expected the source tree to be unchanged, got %q, want %q", after, before)
+	}
+
+	if result := root.Filter(func(*TreeNode) bool { return false }); result != nil {
+		t.Errorf("Expected nil when nothing matches, got %+v", result)
+	}
+}
+
+func TestTreeNodeFilterYAML(t *testing.T) {
+	root, err := ParseYAMLToTree(limitsYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	nonDefault := func(node *TreeNode) bool {
+		yamlNode := node.Data.(YAMLNode)
+		return yamlNode.NodeType == "scalar" && yamlNode.Value != "info" && yamlNode.Value != 5432
+	}
+
+	filtered := root.Filter(nonDefault)
+	expected := []string{
+		"app", "app/name",
+		"app/database", "app/database/primary", "app/database/primary/host",
+		"app/database/replicas", "app/database/replicas/db2", "app/database/replicas/db3",
+	}
+	if shape := treeShape(filtered); !reflect.DeepEqual(shape, expected) {
+		t.Errorf("Filter() kept %q, want %q", shape, expected)
+	}
+
+	// Copies are independent of the source
+	filtered.FindByPath("app").Children = nil
+	if root.FindByPath("app", "name") == nil {
+		t.Error("Expected modifying the copy to leave the source intact")
+	}
+}
+
+func TestTreeNodeFilterSubtrees(t *testing.T) {
+	root, err := ParseYAMLToTree(limitsYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	containers := func(node *TreeNode) bool {
+		return node.Name == "primary" || node.Name == "replicas" || node.Name == "logging"
+	}
+
+	subtrees := root.FilterSubtrees(containers)
+	var names []string
+	for _, subtree := range subtrees {
+		names = append(names, subtree.Name+":"+strings.Join(childNames(subtree), ","))
+	}
+	expected := []string{"primary:host,port", "replicas:db2,db3", "logging:level"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("FilterSubtrees() = %q, want %q", names, expected)
+	}
+
+	subtrees[0].Children = nil
+	if len(root.FindByPath("app", "database", "primary").Children) != 2 {
+		t.Error("Expected the source tree to be unchanged")
+	}
+
+	if subtrees := root.FilterSubtrees(func(*TreeNode) bool { return false }); len(subtrees) != 0 {
+		t.Errorf("Expected no subtrees, got %d", len(subtrees))
+	}
+}
@@ -0,0 +1,79 @@
+package palantir
+
+import (
+	"encoding/csv"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// hierarchyCSVHeader lists the columns written by ExportHierarchyCSV
+var hierarchyCSVHeader = []string{"path", "name", "is_dir", "size", "modtime", "depth"}
+
+// ExportHierarchyCSV writes one CSV row per file and directory under basePath with the
+// columns path, name, is_dir, size, modtime (RFC 3339, UTC) and depth. Paths are relative
+// to basePath and rows follow the tree's display order. Hidden entries are skipped and
+// WithMaxDepth omits deeper entries.
+func ExportHierarchyCSV(basePath string, w io.Writer, opts ...BuildOption) error {
+	return exportHierarchy(basePath, w, ',', newBuildOptions(opts))
+}
+
+// ExportHierarchyTSV writes the rows of ExportHierarchyCSV separated by tabs
+func ExportHierarchyTSV(basePath string, w io.Writer, opts ...BuildOption) error {
+	return exportHierarchy(basePath, w, '\t', newBuildOptions(opts))
+}
+
+// exportHierarchy writes the tree's metadata with the given field delimiter
+func exportHierarchy(basePath string, w io.Writer, comma rune, options BuildOptions) error {
+	root, err := NewOSTreeBuilder().Build(basePath)
+	if err != nil {
+		return err
+	}
+	if options.Sort {
+		sortTree(root)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	if err := writer.Write(hierarchyCSVHeader); err != nil {
+		return err
+	}
+	if err := writeHierarchyRows(writer, root, "", 1, options); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeHierarchyRows writes a row for every descendant of node
+func writeHierarchyRows(writer *csv.Writer, node *TreeNode, prefix string, depth int, options BuildOptions) error {
+	if options.MaxDepth > 0 && depth > options.MaxDepth {
+		return nil
+	}
+
+	for _, child := range node.Children {
+		fileNode, ok := child.Data.(FileNode)
+		if !ok {
+			continue
+		}
+		childPath := filepath.Join(prefix, child.Name)
+
+		row := []string{
+			childPath,
+			fileNode.Name,
+			strconv.FormatBool(fileNode.IsDir),
+			strconv.FormatInt(fileNode.Size, 10),
+			time.Unix(fileNode.ModTime, 0).UTC().Format(time.RFC3339),
+			strconv.Itoa(depth),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+
+		if err := writeHierarchyRows(writer, child, childPath, depth+1, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package palantir
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportHierarchyCSV(t *testing.T) {
+	basePath := createFileFixture(t, []string{
+		"README.md",
+		"src/main.go",
+		"src/util/strings.go",
+		".git/config",
+	})
+
+	modTime := time.Date(2025, 10, 5, 9, 30, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(basePath, "src", "main.go"), modTime, modTime); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportHierarchyCSV(basePath, &buf); err != nil {
+		t.Fatalf("ExportHierarchyCSV() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	// Header, src, src/util, src/util/strings.go, src/main.go and README.md
+	if len(rows) != 6 {
+		t.Fatalf("Expected 6 rows, got %d: %v", len(rows), rows)
+	}
+	if header := rows[0]; len(header) != 6 || header[0] != "path" || header[5] != "depth" {
+		t.Errorf("Unexpected header %v", header)
+	}
+
+	var mainRow []string
+	for _, row := range rows[1:] {
+		if row[0] == filepath.Join("src", "main.go") {
+			mainRow = row
+		}
+	}
+	expected := []string{filepath.Join("src", "main.go"), "main.go", "false", "4", "2025-10-05T09:30:00Z", "2"}
+	for i, field := range expected {
+		if mainRow == nil || mainRow[i] != field {
+			t.Fatalf("Expected row %v, got %v", expected, mainRow)
+		}
+	}
+}
+
+func TestExportHierarchyTSVMaxDepth(t *testing.T) {
+	basePath := createFileFixture(t, []string{"a/b/c.txt", "d.txt"})
+
+	var buf bytes.Buffer
+	if err := ExportHierarchyTSV(basePath, &buf, WithMaxDepth(1)); err != nil {
+		t.Fatalf("ExportHierarchyTSV() error = %v", err)
+	}
+
+	reader := csv.NewReader(&buf)
+	reader.Comma = '\t'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse TSV: %v", err)
+	}
+	if len(rows) != 3 || rows[1][0] != "a" || rows[2][0] != "d.txt" {
+		t.Errorf("Expected only top-level entries, got %v", rows)
+	}
+}
+
+func TestExportHierarchyCSVInvalidPath(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportHierarchyCSV(filepath.Join(t.TempDir(), "missing"), &buf); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+}
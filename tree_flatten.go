@@ -0,0 +1,40 @@
+package palantir
+
+import "strings"
+
+// FlatEntry is a node of a flattened tree: its path and the node's Data
+type FlatEntry struct {
+	Path string
+	Data interface{}
+}
+
+// Flatten returns the paths of the nodes below n in display order, each joined by sep,
+// such as "dir1/subdir/file3.md" with "/" or "database.credentials.username" with ".".
+// Paths are relative to n, so neither the base directory of a filesystem tree nor the
+// synthetic "root" of a YAML tree appears in them. A single file, whose tree is just n,
+// flattens to its own name. With leavesOnly, directories and YAML containers are left out.
+func (n *TreeNode) Flatten(sep string, leavesOnly bool) []string {
+	entries := n.FlattenWithData(sep, leavesOnly)
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry.Path
+	}
+	return paths
+}
+
+// FlattenWithData is like Flatten, but pairs every path with the Data of its node
+func (n *TreeNode) FlattenWithData(sep string, leavesOnly bool) []FlatEntry {
+	if len(n.Children) == 0 && !getIsDir(n.Data) {
+		return []FlatEntry{{Path: n.Name, Data: n.Data}}
+	}
+
+	var entries []FlatEntry
+	n.Walk(func(node *TreeNode, depth int, path []string) error {
+		if depth == 0 || (leavesOnly && (getIsDir(node.Data) || len(node.Children) > 0)) {
+			return nil
+		}
+		entries = append(entries, FlatEntry{Path: strings.Join(path, sep), Data: node.Data})
+		return nil
+	})
+	return entries
+}
@@ -0,0 +1,81 @@
+package palantir
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTreeNodeFlattenFilesystem(t *testing.T) {
+	tempDir := createFileFixture(t, multiFileFixture)
+	root, err := NewOSTreeBuilder().Build(tempDir)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	sortTree(root)
+
+	all := []string{"dir1", "dir1/subdir", "dir1/subdir/file3.md", "dir1/file2.go", "dir2", "dir2/file4.json", "file1.txt"}
+	if paths := root.Flatten("/", false); !reflect.DeepEqual(paths, all) {
+		t.Errorf("Flatten() = %q, want %q", paths, all)
+	}
+
+	leaves := []string{`dir1\subdir\file3.md`, `dir1\file2.go`, `dir2\file4.json`, "file1.txt"}
+	if paths := root.Flatten(`\`, true); !reflect.DeepEqual(paths, leaves) {
+		t.Errorf("Flatten() leaves = %q, want %q", paths, leaves)
+	}
+
+	// A single file has no base directory to leave out
+	file, err := NewOSTreeBuilder().Build(filepath.Join(tempDir, "file1.txt"))
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if paths := file.Flatten("/", true); !reflect.DeepEqual(paths, []string{"file1.txt"}) {
+		t.Errorf("Flatten() of a single file = %q", paths)
+	}
+}
+
+func TestTreeNodeFlattenYAML(t *testing.T) {
+	root, err := ParseYAMLToTree(lookupYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	leaves := []string{
+		"database.credentials.username",
+		"database.credentials.password",
+		"database.port",
+		"hosts.alpha",
+		"hosts.[1].name",
+	}
+	if paths := root.Flatten(".", true); !reflect.DeepEqual(paths, leaves) {
+		t.Errorf("Flatten() leaves = %q, want %q", paths, leaves)
+	}
+
+	if paths := root.Flatten(".", false); len(paths) != 9 || paths[0] != "database" {
+		t.Errorf("Flatten() = %q, want 9 paths starting with database", paths)
+	}
+
+	// The synthetic root of an empty document is left out like any other root
+	empty, err := ParseYAMLToTree([]byte(""))
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+	if paths := empty.Flatten(".", false); len(paths) != 0 {
+		t.Errorf("Flatten() of an empty document = %q", paths)
+	}
+}
+
+func TestTreeNodeFlattenWithData(t *testing.T) {
+	root, err := ParseYAMLToTree(lookupYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	entries := root.FlattenWithData("/", true)
+	if len(entries) != 5 {
+		t.Fatalf("Expected 5 leaves, got %d", len(entries))
+	}
+	if entries[2].Path != "database/port" || entries[2].Data.(YAMLNode).Value != 5432 {
+		t.Errorf("Expected database/port holding 5432, got %+v", entries[2])
+	}
+}
@@ -0,0 +1,68 @@
+package palantir
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// fileCategory groups the file extensions that share a color in filesystem trees
+type fileCategory struct {
+	label      string
+	color      string
+	extensions []string
+}
+
+// fileCategories lists the colored file categories in the order PrintTreeLegend shows them
+var fileCategories = []fileCategory{
+	{label: "code", color: ColorPurple, extensions: []string{".go"}},
+	{label: "docs", color: ColorCyan, extensions: []string{".md", ".txt", ".log"}},
+	{label: "config", color: ColorGreen, extensions: []string{".json", ".yaml", ".yml", ".toml"}},
+	{label: "script", color: ColorYellow, extensions: []string{".sh", ".zsh", ".bash"}},
+	{label: "archive", color: ColorRed, extensions: []string{".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".7z"}},
+}
+
+// fileCategoryFor returns the category of a file name based on its extension
+func fileCategoryFor(name string) (fileCategory, bool) {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, category := range fileCategories {
+		for _, categoryExt := range category.extensions {
+			if ext == categoryExt {
+				return category, true
+			}
+		}
+	}
+	return fileCategory{}, false
+}
+
+// PrintTreeLegend prints a key explaining the colors of filesystem trees: a colored
+// sample of directories and each file category followed by its extensions. When
+// trees are not colored it lists which extensions belong to each category.
+func PrintTreeLegend() {
+	printTreeLegend(newBuildOptions(nil))
+}
+
+// printTreeLegend prints the legend to the options' writer, styling each sample as
+// filesystem trees style the names it stands for
+func printTreeLegend(options BuildOptions) {
+	outputConfig := treeOutputConfig()
+	if outputConfig.DisableOutput {
+		return
+	}
+	flushGlobalOutput()
+
+	w := options.writer()
+	fmt.Fprintln(w, "Legend:")
+	if !outputConfig.UseColors || outputConfig.ColorizeLevelOnly {
+		fmt.Fprintln(w, "  directory: folders")
+		for _, category := range fileCategories {
+			fmt.Fprintf(w, "  %s: %s\n", category.label, strings.Join(category.extensions, ", "))
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "  %s\n", styleTreeText("directory", ColorBlue, true))
+	for _, category := range fileCategories {
+		fmt.Fprintf(w, "  %s %s\n", styleTreeText(category.label, category.color, false), styleDim(strings.Join(category.extensions, " ")))
+	}
+}
@@ -0,0 +1,81 @@
+package palantir
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintTreeLegend(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	output := captureOutput(PrintTreeLegend)
+
+	for _, label := range []string{"directory", "code", "docs", "config", "script", "archive"} {
+		if !strings.Contains(output, label) {
+			t.Errorf("Expected legend to contain %q, got %q", label, output)
+		}
+	}
+	if !strings.Contains(output, ColorPurple+"code"+ColorReset) {
+		t.Errorf("Expected a colored sample for each category, got %q", output)
+	}
+}
+
+func TestPrintTreeLegendWithoutColors(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(PrintTreeLegend)
+
+	expected := []string{"code: .go", "docs: .md, .txt, .log", "config: .json, .yaml, .yml, .toml", "script: .sh, .zsh, .bash", "archive: .zip"}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected legend to contain %q, got %q", line, output)
+		}
+	}
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected no escape codes, got %q", output)
+	}
+}
+
+func TestPrintTreeLegendMatchesTreeStyling(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true, UseFormatting: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	var buf bytes.Buffer
+	options := newBuildOptions(nil)
+	options.output = &buf
+	printTreeLegend(options)
+
+	dir := &TreeNode{Name: "directory", Data: FileNode{Name: "directory", IsDir: true}}
+	if sample := "  " + styleFileNode(dir) + "\n"; !strings.Contains(buf.String(), sample) {
+		t.Errorf("Expected the directory sample to be styled like a directory %q, got %q", sample, buf.String())
+	}
+
+	// Trees are not colored under ColorizeLevelOnly, so neither is the legend
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true, ColorizeLevelOnly: true}))
+	buf.Reset()
+	printTreeLegend(options)
+	if strings.Contains(buf.String(), "\x1b[") || !strings.Contains(buf.String(), "code: .go") {
+		t.Errorf("Expected the plain legend, got %q", buf.String())
+	}
+}
+
+func TestStyleFileNodeMatchesLegend(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	for _, category := range fileCategories {
+		name := "sample" + category.extensions[0]
+		node := &TreeNode{Name: name, Data: FileNode{Name: name}}
+		if styled := styleFileNode(node); styled != category.color+name+ColorReset {
+			t.Errorf("Expected %s to use the %s color, got %q", name, category.label, styled)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package palantir
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DefaultMaxParseDepth is the deepest YAML nesting ParseYAMLToTree accepts unless
+// WithMaxParseDepth says otherwise, protecting against maliciously deep documents
+const DefaultMaxParseDepth = 1000
+
+// truncationMarker is the Data of nodes standing in for content hidden by MaxDepth
+type truncationMarker struct{}
+
+// renderLimitedTree applies PathFilter, MaxDepth and MaxNodes to a tree, renders it and
+// warns when nodes were dropped to respect MaxNodes
+func renderLimitedTree(root *TreeNode, options BuildOptions) {
+	renderLimited(root, options, renderTree)
+}
+
+// renderLimited applies PathFilter, MaxDepth and MaxNodes to a tree, renders it with render
+// and warns when nodes were dropped to respect MaxNodes
+func renderLimited(root *TreeNode, options BuildOptions, render func(root *TreeNode, options BuildOptions)) {
+	if options.PathFilter != "" {
+		found, err := filterTreeByPath(root, options.PathFilter)
+		if err != nil {
+			GetGlobalOutputHandler().PrintWarning("Invalid path filter: %v", err)
+			return
+		}
+		if !found {
+			GetGlobalOutputHandler().PrintWarning("No keys match path %q", options.PathFilter)
+			return
+		}
+	}
+
+	dropped := limitTree(root, options)
+	render(root, options)
+	if dropped > 0 {
+		GetGlobalOutputHandler().PrintWarning("Tree truncated at %s nodes, %s more not shown",
+			formatCount(options.MaxNodes), formatCount(dropped))
+	}
+}
+
+// lineBudget counts the lines a tree has printed to respect BuildOptions.MaxLines
+type lineBudget struct {
+	max       int
+	printed   int
+	truncated bool // Set once a line was withheld
+}
+
+// exhausted reports whether no more lines may be printed. A nil budget is unlimited.
+func (b *lineBudget) exhausted() bool {
+	return b != nil && b.printed >= b.max
+}
+
+// printTreeLine prints a line of a tree to the options' writer unless the line budget is exhausted, reporting
+// whether it was printed
+func printTreeLine(line string, options BuildOptions) bool {
+	if budget := options.lineBudget; budget != nil {
+		if budget.exhausted() {
+			budget.truncated = true
+			return false
+		}
+		budget.printed++
+	}
+	fmt.Fprintln(options.writer(), line)
+	return true
+}
+
+// printTruncationNotice says a tree was cut short when MaxLines withheld some of its lines
+func printTruncationNotice(options BuildOptions) {
+	if budget := options.lineBudget; budget != nil && budget.truncated {
+		fmt.Fprintln(options.writer(), styleDim(fmt.Sprintf("... output truncated (%s lines shown)", formatCount(budget.printed))))
+	}
+}
+
+// limitTree collapses nodes deeper than MaxDepth and drops nodes beyond MaxNodes,
+// returning how many nodes were dropped
+func limitTree(root *TreeNode, options BuildOptions) int {
+	if options.MaxDepth > 0 {
+		collapseDeepNodes(root, 0, options.MaxDepth)
+	}
+	if options.MaxNodes > 0 {
+		remaining := options.MaxNodes
+		return capNodes(root, &remaining)
+	}
+	return 0
+}
+
+// collapseDeepNodes replaces the children of nodes at maxDepth with a single marker
+// counting the nested items it hides
+func collapseDeepNodes(node *TreeNode, depth, maxDepth int) {
+	if len(node.Children) == 0 {
+		return
+	}
+
+	if depth >= maxDepth {
+		nested := countNodes(node) - 1
+		label := "… (" + formatCount(nested) + " nested items)"
+		if nested == 1 {
+			label = "… (1 nested item)"
+		}
+		node.Children = []*TreeNode{{Name: label, Data: truncationMarker{}}}
+		return
+	}
+
+	for _, child := range node.Children {
+		collapseDeepNodes(child, depth+1, maxDepth)
+	}
+}
+
+// capNodes keeps the first *remaining nodes below node in display order and drops
+// the rest, returning how many nodes were dropped
+func capNodes(node *TreeNode, remaining *int) int {
+	dropped := 0
+	for i, child := range node.Children {
+		if *remaining == 0 {
+			for _, rest := range node.Children[i:] {
+				dropped += countNodes(rest)
+			}
+			node.Children = node.Children[:i]
+			break
+		}
+		*remaining--
+		dropped += capNodes(child, remaining)
+	}
+	return dropped
+}
+
+// countNodes counts a node and all of its descendants
+func countNodes(node *TreeNode) int {
+	count := 1
+	for _, child := range node.Children {
+		count += countNodes(child)
+	}
+	return count
+}
+
+// formatCount formats n with thousands separators, such as 1,234
+func formatCount(n int) string {
+	digits := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+
+	var formatted []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			formatted = append(formatted, ',')
+		}
+		formatted = append(formatted, digits[i])
+	}
+	return string(formatted)
+}
@@ -0,0 +1,150 @@
+package palantir
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var limitsYAML = []byte(`
+app:
+  name: palantir
+  database:
+    primary:
+      host: db1
+      port: 5432
+    replicas:
+      - db2
+      - db3
+logging:
+  level: info
+`)
+
+func TestShowYAMLHierarchyMaxDepth(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(limitsYAML, WithMaxDepth(2)); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := []string{"app", "name", "database", "… (6 nested items)", "logging", "level"}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+	if strings.Contains(output, "primary") || strings.Contains(output, "db2") {
+		t.Errorf("Expected content below depth 2 to be collapsed, got:\n%s", output)
+	}
+}
+
+func TestShowYAMLHierarchyMaxNodes(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false, UseFormatting: true}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(limitsYAML, WithMaxNodes(3)); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "database") {
+		t.Errorf("Expected the first 3 nodes to be shown, got:\n%s", output)
+	}
+	if strings.Contains(output, "primary") || strings.Contains(output, "logging") {
+		t.Errorf("Expected nodes beyond the cap to be dropped, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Tree truncated at 3 nodes, 8 more not shown") {
+		t.Errorf("Expected a truncation warning, got:\n%s", output)
+	}
+}
+
+func TestShowYAMLHierarchyLimitsDefaultUnchanged(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	limited := captureOutput(func() {
+		ShowYAMLHierarchy(limitsYAML, WithMaxDepth(10), WithMaxNodes(100))
+	})
+	unlimited := captureOutput(func() {
+		ShowYAMLHierarchy(limitsYAML)
+	})
+
+	if limited != unlimited {
+		t.Errorf("Expected limits larger than the document to keep output unchanged\ngot:\n%s\nwant:\n%s", limited, unlimited)
+	}
+	if strings.Contains(unlimited, "…") || strings.Contains(unlimited, "truncated") {
+		t.Errorf("Expected no truncation by default, got:\n%s", unlimited)
+	}
+}
+
+func TestParseYAMLToTreeMaxParseDepth(t *testing.T) {
+	deep := strings.Repeat("[", 50) + strings.Repeat("]", 50)
+
+	if _, err := ParseYAMLToTree([]byte(deep)); err != nil {
+		t.Errorf("Expected default depth to accept 50 levels, got %v", err)
+	}
+
+	_, err := ParseYAMLToTree([]byte(deep), WithMaxParseDepth(10))
+	if err == nil || !strings.Contains(err.Error(), "maximum depth of 10") {
+		t.Errorf("Expected a maximum depth error, got %v", err)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{1234567, "1,234,567"},
+		{-1234, "-1,234"},
+	}
+
+	for _, tt := range tests {
+		if result := formatCount(tt.n); result != tt.expected {
+			t.Errorf("formatCount(%d) = %q, want %q", tt.n, result, tt.expected)
+		}
+	}
+}
+
+func TestShowHierarchyMaxLines(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	var files []string
+	for i := 0; i < 50; i++ {
+		files = append(files, fmt.Sprintf("dir%02d/file%02d.txt", i, i))
+	}
+	tempDir := createFileFixture(t, files)
+
+	output := captureOutput(func() {
+		if err, _ := ShowHierarchy(tempDir, "", WithMaxLines(10)); err != nil {
+			t.Errorf("ShowHierarchy() error = %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("Expected 10 tree lines and a notice, got %d lines:\n%s", len(lines), output)
+	}
+	if lines[9] != "│   └── file04.txt" {
+		t.Errorf("Expected the tree to stop after 10 lines, last line %q", lines[9])
+	}
+	if lines[10] != "... output truncated (10 lines shown)" {
+		t.Errorf("Expected a truncation notice, got %q", lines[10])
+	}
+}
+
+func TestShowHierarchyMaxLinesNotReached(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowYAMLHierarchy(limitsYAML, WithShowRoot(), WithMaxLines(12))
+	})
+
+	if strings.Contains(output, "truncated") || strings.Count(output, "\n") != 12 {
+		t.Errorf("Expected the whole tree without a notice, got:\n%s", output)
+	}
+}
@@ -0,0 +1,91 @@
+package palantir
+
+import "strings"
+
+// childIndexThreshold is the number of children above which ChildNamed indexes a node's
+// children by name instead of scanning them
+const childIndexThreshold = 32
+
+// ChildNamed returns the first direct child of n with the given name, or nil. Nodes with
+// many children keep an index of them by name, so repeated lookups take constant time.
+// The index is rebuilt when the number of children changes, or when an indexed child was
+// renamed; a child renamed to name without changing the number is not noticed. Like the
+// rest of TreeNode, it is not safe for concurrent use.
+func (n *TreeNode) ChildNamed(name string) *TreeNode {
+	if len(n.Children) < childIndexThreshold {
+		return n.scanChildren(name)
+	}
+
+	if n.childIndex == nil || n.indexedChildren != len(n.Children) {
+		n.indexChildren()
+	}
+	i, ok := n.childIndex[name]
+	if !ok {
+		return nil
+	}
+	if n.Children[i].Name != name {
+		// The child was renamed or replaced since it was indexed
+		n.indexChildren()
+		if i, ok = n.childIndex[name]; !ok {
+			return nil
+		}
+	}
+	return n.Children[i]
+}
+
+// scanChildren returns the first direct child of n with the given name, or nil
+func (n *TreeNode) scanChildren(name string) *TreeNode {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// indexChildren maps the name of each child to the position of its first occurrence
+func (n *TreeNode) indexChildren() {
+	n.childIndex = make(map[string]int, len(n.Children))
+	for i := len(n.Children) - 1; i >= 0; i-- {
+		n.childIndex[n.Children[i].Name] = i
+	}
+	n.indexedChildren = len(n.Children)
+}
+
+// FindByPath returns the node reached by following the named children of n, such as
+// FindByPath("database", "credentials", "username"), or nil when a name is absent.
+// When siblings share a name the first one is followed. No parts returns n itself.
+func (n *TreeNode) FindByPath(parts ...string) *TreeNode {
+	node := n
+	for _, part := range parts {
+		if node = node.ChildNamed(part); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// FindByPathString is FindByPath with the path given as names joined by sep, such as
+// FindByPathString("database.credentials.username", ".")
+func (n *TreeNode) FindByPathString(path, sep string) *TreeNode {
+	if path == "" {
+		return n
+	}
+	return n.FindByPath(strings.Split(path, sep)...)
+}
+
+// GetValue returns the value of the YAML scalar at the path below n, reporting whether
+// the path leads to a scalar. Array items are named after their scalar value or index,
+// such as "[0]".
+func (n *TreeNode) GetValue(parts ...string) (interface{}, bool) {
+	node := n.FindByPath(parts...)
+	if node == nil {
+		return nil, false
+	}
+
+	yamlNode, ok := node.Data.(YAMLNode)
+	if !ok || yamlNode.NodeType != "scalar" {
+		return nil, false
+	}
+	return yamlNode.Value, true
+}
@@ -0,0 +1,129 @@
+package palantir
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var lookupYAML = []byte(`
+database:
+  credentials:
+    username: admin
+    password: secret
+  port: 5432
+hosts:
+  - alpha
+  - name: beta
+`)
+
+func TestTreeNodeFindByPath(t *testing.T) {
+	root, err := ParseYAMLToTree(lookupYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		node     *TreeNode
+		expected string
+	}{
+		{"Nested key", root.FindByPath("database", "credentials", "username"), "username"},
+		{"Array item", root.FindByPath("hosts", "[1]", "name"), "name"},
+		{"Separated path", root.FindByPathString("database.credentials.password", "."), "password"},
+		{"Custom separator", root.FindByPathString("database/port", "/"), "port"},
+		{"Empty path", root.FindByPath(), "root"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.node == nil || tt.node.Name != tt.expected {
+				t.Errorf("Expected to find %q, got %+v", tt.expected, tt.node)
+			}
+		})
+	}
+
+	for _, path := range [][]string{{"database", "user"}, {"missing"}, {"database", "port", "deeper"}} {
+		if node := root.FindByPath(path...); node != nil {
+			t.Errorf("FindByPath(%q) = %q, want nil", path, node.Name)
+		}
+	}
+}
+
+func TestTreeNodeChildNamedDuplicates(t *testing.T) {
+	first := &TreeNode{Name: "dup", Data: FileNode{Name: "dup", Path: "first"}}
+	second := &TreeNode{Name: "dup", Data: FileNode{Name: "dup", Path: "second"}}
+
+	for _, size := range []int{0, childIndexThreshold} {
+		t.Run(fmt.Sprintf("%d other children", size), func(t *testing.T) {
+			node := &TreeNode{Name: "root"}
+			for i := 0; i < size; i++ {
+				node.Children = append(node.Children, &TreeNode{Name: fmt.Sprintf("child%d", i)})
+			}
+			node.Children = append(node.Children, first, second)
+
+			// The first of several children with the same name is returned
+			if child := node.ChildNamed("dup"); child != first {
+				t.Errorf("ChildNamed() = %+v, want the first duplicate", child)
+			}
+			if child := node.ChildNamed("absent"); child != nil {
+				t.Errorf("ChildNamed() = %+v, want nil", child)
+			}
+
+			// Children added after a lookup are still found
+			added := &TreeNode{Name: "added"}
+			node.Children = append(node.Children, added)
+			if child := node.ChildNamed("added"); child != added {
+				t.Errorf("ChildNamed() = %+v, want the added child", child)
+			}
+		})
+	}
+}
+
+func TestTreeNodeChildNamedKeepsIndex(t *testing.T) {
+	node := &TreeNode{Name: "root"}
+	for i := 0; i < childIndexThreshold; i++ {
+		node.Children = append(node.Children, &TreeNode{Name: fmt.Sprintf("child%d", i)})
+	}
+
+	node.ChildNamed("child0")
+	index := reflect.ValueOf(node.childIndex).Pointer()
+	for _, name := range []string{"absent", "child1", "absent"} {
+		node.ChildNamed(name)
+	}
+	if reflect.ValueOf(node.childIndex).Pointer() != index {
+		t.Error("Expected lookups to reuse the index while the children are unchanged")
+	}
+
+	// A renamed child is found under its new name only
+	node.Children[3].Name = "renamed"
+	if child := node.ChildNamed("child3"); child != nil {
+		t.Errorf("ChildNamed() = %+v, want nil for the old name", child)
+	}
+	node.Children = append(node.Children, &TreeNode{Name: "added"})
+	if child := node.ChildNamed("renamed"); child != node.Children[3] {
+		t.Errorf("ChildNamed() = %+v, want the renamed child", child)
+	}
+}
+
+func TestTreeNodeGetValue(t *testing.T) {
+	root, err := ParseYAMLToTree(lookupYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	if value, ok := root.GetValue("database", "credentials", "username"); !ok || value != "admin" {
+		t.Errorf("GetValue() = %v, %v, want admin, true", value, ok)
+	}
+	if value, ok := root.GetValue("database", "port"); !ok || value != 5432 {
+		t.Errorf("GetValue() = %v, %v, want 5432, true", value, ok)
+	}
+	if value, ok := root.GetValue("hosts", "alpha"); !ok || value != "alpha" {
+		t.Errorf("GetValue() = %v, %v, want alpha, true", value, ok)
+	}
+	if _, ok := root.GetValue("database", "credentials"); ok {
+		t.Error("Expected GetValue() to reject a mapping")
+	}
+	if _, ok := root.GetValue("database", "missing"); ok {
+		t.Error("Expected GetValue() to report a missing path")
+	}
+}
@@ -0,0 +1,125 @@
+package palantir
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RenderHierarchyPaths returns the sorted paths of all files under basePath, relative
+// to basePath. Directories are included with WithIncludeDirs, and end in a separator
+// when combined with WithDirSlash.
+func RenderHierarchyPaths(basePath string, opts ...BuildOption) ([]string, error) {
+	root, err := NewOSTreeBuilder().Build(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := collectPaths(root, "", newBuildOptions(opts))
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// RenderHierarchyFlat returns the paths RenderHierarchyPaths lists as raw text for
+// scripts, each followed by sep. Use '\n' for one path per line, or 0 for NUL-delimited
+// output that `xargs -0` can consume even when names contain newlines.
+func RenderHierarchyFlat(basePath string, sep byte, opts ...BuildOption) (string, error) {
+	paths, err := RenderHierarchyPaths(basePath, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, path := range paths {
+		b.WriteString(path)
+		b.WriteByte(sep)
+	}
+	return b.String(), nil
+}
+
+// collectPaths returns the paths of the descendants of node, joined onto prefix
+func collectPaths(node *TreeNode, prefix string, options BuildOptions) []string {
+	var paths []string
+	for _, child := range node.Children {
+		childPath := filepath.Join(prefix, child.Name)
+
+		if !getIsDir(child.Data) {
+			paths = append(paths, childPath)
+			continue
+		}
+
+		if options.IncludeDirs {
+			if options.DirSlash {
+				paths = append(paths, childPath+string(filepath.Separator))
+			} else {
+				paths = append(paths, childPath)
+			}
+		}
+		paths = append(paths, collectPaths(child, childPath, options)...)
+	}
+	return paths
+}
+
+// TreeFromPaths builds a tree from paths separated by sep, such as S3 keys, archive
+// listings or the output of `git ls-files`, without touching the filesystem. Intermediate
+// segments become directories and the last one a file, unless the path ends in sep, which
+// marks an explicit directory. Duplicates are merged and children sorted, so the tree does
+// not depend on the order of paths. Empty and "." segments are skipped, so "./src/main.go"
+// is "src/main.go", and hidden entries such as ".github" are kept, as the paths were listed
+// on purpose. A path with a ".." segment is rejected, as is a path containing the other of
+// "/" and "\", which would otherwise be shown as a single name.
+func TreeFromPaths(paths []string, sep string) (*TreeNode, error) {
+	if sep == "" {
+		return nil, errors.New("path separator must not be empty")
+	}
+
+	root := &TreeNode{Name: ".", Data: FileNode{Name: ".", Path: ".", IsDir: true}}
+	other := otherSeparator(sep)
+	for _, entry := range paths {
+		if other != "" && strings.Contains(entry, other) {
+			return nil, fmt.Errorf("path %q mixes separators: expected %q, found %q", entry, sep, other)
+		}
+
+		var parts []string
+		for _, part := range strings.Split(entry, sep) {
+			switch part {
+			case "", ".": // Leading, doubled and "./" separators
+				continue
+			case "..":
+				return nil, fmt.Errorf("path %q must not contain %q", entry, "..")
+			}
+			parts = append(parts, part)
+		}
+		if len(parts) > 0 {
+			addPathEntry(root, parts, strings.HasSuffix(entry, sep), 0, 0)
+		}
+	}
+	sortTree(root)
+	return root, nil
+}
+
+// otherSeparator returns the path separator that is mistaken for sep, or an empty string
+// for separators other than "/" and "\"
+func otherSeparator(sep string) string {
+	switch sep {
+	case "/":
+		return `\`
+	case `\`:
+		return "/"
+	}
+	return ""
+}
+
+// ShowPathsHierarchy displays slash-separated paths as a tree built by TreeFromPaths, like
+// ShowHierarchy does for a directory, without touching the filesystem. Unlike in
+// filesystem trees, hidden entries are shown.
+func ShowPathsHierarchy(paths []string, opts ...BuildOption) error {
+	root, err := TreeFromPaths(paths, "/")
+	if err != nil {
+		return err
+	}
+	err, _ = showFileTree(root, newBuildOptions(opts))
+	return err
+}
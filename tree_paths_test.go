@@ -0,0 +1,244 @@
+package palantir
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// createFileFixture creates the given files (and their parent directories) in a temp dir
+func createFileFixture(t *testing.T, files []string) string {
+	t.Helper()
+	tempDir, err := os.MkdirTemp("", "palantir_fixture_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	for _, file := range files {
+		fullPath := filepath.Join(tempDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", fullPath, err)
+		}
+	}
+	return tempDir
+}
+
+var multiFileFixture = []string{
+	"file1.txt",
+	"dir1/file2.go",
+	"dir1/subdir/file3.md",
+	"dir2/file4.json",
+	".hidden/secret.txt",
+}
+
+func TestRenderHierarchyPaths(t *testing.T) {
+	tempDir := createFileFixture(t, multiFileFixture)
+
+	tests := []struct {
+		name     string
+		opts     []BuildOption
+		expected []string
+	}{
+		{
+			name: "Files only",
+			opts: nil,
+			expected: []string{
+				filepath.Join("dir1", "file2.go"),
+				filepath.Join("dir1", "subdir", "file3.md"),
+				filepath.Join("dir2", "file4.json"),
+				"file1.txt",
+			},
+		},
+		{
+			name: "Files and directories",
+			opts: []BuildOption{WithIncludeDirs()},
+			expected: []string{
+				"dir1",
+				filepath.Join("dir1", "file2.go"),
+				filepath.Join("dir1", "subdir"),
+				filepath.Join("dir1", "subdir", "file3.md"),
+				"dir2",
+				filepath.Join("dir2", "file4.json"),
+				"file1.txt",
+			},
+		},
+		{
+			name: "Directories with trailing slash",
+			opts: []BuildOption{WithIncludeDirs(), WithDirSlash()},
+			expected: []string{
+				"dir1" + string(filepath.Separator),
+				filepath.Join("dir1", "file2.go"),
+				filepath.Join("dir1", "subdir") + string(filepath.Separator),
+				filepath.Join("dir1", "subdir", "file3.md"),
+				"dir2" + string(filepath.Separator),
+				filepath.Join("dir2", "file4.json"),
+				"file1.txt",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := RenderHierarchyPaths(tempDir, tt.opts...)
+			if err != nil {
+				t.Fatalf("RenderHierarchyPaths() error = %v", err)
+			}
+			if !reflect.DeepEqual(paths, tt.expected) {
+				t.Errorf("RenderHierarchyPaths() = %v, want %v", paths, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderHierarchyPathsInvalidPath(t *testing.T) {
+	if _, err := RenderHierarchyPaths("/nonexistent/path"); err == nil {
+		t.Error("Expected error for non-existent path, got nil")
+	}
+}
+
+func TestRenderHierarchyFlat(t *testing.T) {
+	tempDir := createFileFixture(t, []string{"b.txt", "dir/a.go", ".hidden/secret.txt"})
+
+	tests := []struct {
+		name     string
+		sep      byte
+		opts     []BuildOption
+		expected string
+	}{
+		{"Newline", '\n', nil, "b.txt\n" + filepath.Join("dir", "a.go") + "\n"},
+		{"NUL", 0, nil, "b.txt\x00" + filepath.Join("dir", "a.go") + "\x00"},
+		{"With directories", '\n', []BuildOption{WithIncludeDirs()}, "b.txt\ndir\n" + filepath.Join("dir", "a.go") + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := RenderHierarchyFlat(tempDir, tt.sep, tt.opts...)
+			if err != nil {
+				t.Fatalf("RenderHierarchyFlat() error = %v", err)
+			}
+			if output != tt.expected {
+				t.Errorf("RenderHierarchyFlat() = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+
+	if _, err := RenderHierarchyFlat("/nonexistent/path", '\n'); err == nil {
+		t.Error("Expected error for non-existent path, got nil")
+	}
+}
+
+func TestTreeFromPaths(t *testing.T) {
+	listed := func(root *TreeNode) []string {
+		return collectPaths(root, "", BuildOptions{IncludeDirs: true, DirSlash: true})
+	}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		sep      string
+		expected []string
+	}{
+		{
+			"Unsorted with duplicates",
+			[]string{"src/main.go", "README.md", "src/util/strings.go", "README.md", "docs/guide.md", "src/main.go"},
+			"/",
+			[]string{"docs/", "docs/guide.md", "src/", "src/util/", "src/util/strings.go", "src/main.go", "README.md"},
+		},
+		{
+			"Explicit and implied directories",
+			[]string{"empty/", "/assets//logo.png", "bin", "bin/tool"},
+			"/",
+			[]string{"assets/", "assets/logo.png", "bin/", "bin/tool", "empty/"},
+		},
+		{
+			"Dot segments and hidden entries",
+			[]string{"./src/main.go", ".github/workflows/ci.yml", "README.md", "src/./util.go", "./"},
+			"/",
+			[]string{".github/", ".github/workflows/", ".github/workflows/ci.yml", "src/", "src/main.go", "src/util.go", "README.md"},
+		},
+		{
+			"Custom separator",
+			[]string{"app::db::host", "app::db::port", "app::name"},
+			"::",
+			[]string{"app/", "app/db/", "app/db/host", "app/db/port", "app/name"},
+		},
+		{
+			"Backslash separator",
+			[]string{`logs\2024\jan.log`, `logs\`},
+			`\`,
+			[]string{"logs/", "logs/2024/", "logs/2024/jan.log"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := TreeFromPaths(tt.paths, tt.sep)
+			if err != nil {
+				t.Fatalf("TreeFromPaths() error = %v", err)
+			}
+			if got := listed(root); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("TreeFromPaths() paths = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	// The order of the input does not matter
+	forward, _ := TreeFromPaths([]string{"a/b/c.txt", "a/d.txt", "e.txt"}, "/")
+	backward, _ := TreeFromPaths([]string{"e.txt", "a/d.txt", "a/b/c.txt"}, "/")
+	if !reflect.DeepEqual(listed(forward), listed(backward)) {
+		t.Errorf("Expected the same tree for reordered paths, got %q and %q", listed(forward), listed(backward))
+	}
+}
+
+func TestTreeFromPathsErrors(t *testing.T) {
+	if _, err := TreeFromPaths([]string{"src/main.go", `src\util.go`}, "/"); err == nil {
+		t.Error("Expected an error for a backslash in slash-separated paths")
+	}
+	if _, err := TreeFromPaths([]string{`src\main.go`, "src/util.go"}, `\`); err == nil {
+		t.Error("Expected an error for a slash in backslash-separated paths")
+	}
+	if _, err := TreeFromPaths([]string{"src/../../etc/passwd"}, "/"); err == nil {
+		t.Error("Expected an error for a path with a .. segment")
+	}
+	if _, err := TreeFromPaths([]string{"a"}, ""); err == nil {
+		t.Error("Expected an error for an empty separator")
+	}
+	if err := ShowPathsHierarchy([]string{`a\b`, "c/d"}); err == nil {
+		t.Error("Expected ShowPathsHierarchy to report mixed separators")
+	}
+}
+
+func TestShowPathsHierarchyMatchesFilesystem(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	// Hidden entries are left out, as filesystem trees skip them
+	var visible []string
+	for _, file := range multiFileFixture {
+		if !strings.HasPrefix(file, ".") {
+			visible = append(visible, file)
+		}
+	}
+	tempDir := createFileFixture(t, multiFileFixture)
+
+	onDisk := captureOutput(func() {
+		if err, _ := ShowHierarchy(tempDir, ""); err != nil {
+			t.Errorf("ShowHierarchy() error = %v", err)
+		}
+	})
+	fromPaths := captureOutput(func() {
+		if err := ShowPathsHierarchy(visible); err != nil {
+			t.Errorf("ShowPathsHierarchy() error = %v", err)
+		}
+	})
+
+	if fromPaths == "" || fromPaths != onDisk {
+		t.Errorf("ShowPathsHierarchy() output = %q, want the filesystem's %q", fromPaths, onDisk)
+	}
+}
@@ -0,0 +1,69 @@
+package palantir
+
+import (
+	"strings"
+)
+
+// sideBySideGutter separates the columns of RenderHierarchySideBySide
+const sideBySideGutter = "    "
+
+// RenderHierarchySideBySide renders the trees of leftPath and rightPath, each labelled
+// with its root, in two columns, such as to compare an expected and an actual layout.
+// Lines are padded by their display width, so colors and wide characters stay aligned,
+// and the shorter tree is padded with blank lines.
+func RenderHierarchySideBySide(leftPath, rightPath string, opts ...BuildOption) (string, error) {
+	left, err := renderHierarchyString(leftPath, opts)
+	if err != nil {
+		return "", err
+	}
+	right, err := renderHierarchyString(rightPath, opts)
+	if err != nil {
+		return "", err
+	}
+	return joinColumns(left, right, sideBySideGutter), nil
+}
+
+// renderHierarchyString builds, sorts and renders the tree of basePath with its root,
+// returning its lines
+func renderHierarchyString(basePath string, opts []BuildOption) ([]string, error) {
+	root, err := NewOSTreeBuilder().Build(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newBuildOptions(opts)
+	if options.GitStatus {
+		applyGitStatus(root, basePath)
+	}
+	if options.Sort {
+		sortTree(root)
+	}
+	applyComments(root, options.Comments)
+
+	return strings.Split(renderTreeString(root, opts), "\n"), nil
+}
+
+// joinColumns places the right lines next to the left ones, padding every left line
+// to the widest one
+func joinColumns(left, right []string, gutter string) string {
+	width := 0
+	for _, line := range left {
+		width = max(width, displayWidth(line))
+	}
+
+	var b strings.Builder
+	for i := 0; i < max(len(left), len(right)); i++ {
+		var leftLine, rightLine string
+		if i < len(left) {
+			leftLine = left[i]
+		}
+		if i < len(right) {
+			rightLine = right[i]
+		}
+
+		line := leftLine + strings.Repeat(" ", width-displayWidth(leftLine)) + gutter + rightLine
+		b.WriteString(strings.TrimRight(line, " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package palantir
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderHierarchySideBySide(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	expectedDir := createFileFixture(t, []string{"cmd/main.go", "go.mod"})
+	actualDir := createFileFixture(t, []string{"cmd/main.go", "cmd/tools/gen.go", "go.mod", "README.md"})
+
+	output, err := RenderHierarchySideBySide(expectedDir, actualDir)
+	if err != nil {
+		t.Fatalf("RenderHierarchySideBySide() error = %v", err)
+	}
+
+	leftRoot, rightRoot := filepath.Base(expectedDir), filepath.Base(actualDir)
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("Expected 7 lines padded to the taller tree, got %d:\n%s", len(lines), output)
+	}
+
+	// The right column starts at the same display column on every line
+	column := displayWidth(lines[0]) - displayWidth(rightRoot)
+	for i, want := range []string{rightRoot, "├── cmd", "│   ├── tools", "│   │   └── gen.go", "│   └── main.go", "├── README.md", "└── go.mod"} {
+		if !strings.HasSuffix(lines[i], want) || displayWidth(lines[i])-displayWidth(want) != column {
+			t.Errorf("Line %d = %q, want %q starting at column %d", i, lines[i], want, column)
+		}
+	}
+	for i, want := range []string{leftRoot, "├── cmd", "│   └── main.go", "└── go.mod"} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("Line %d = %q, want it to start with %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestRenderHierarchySideBySideInvalidPath(t *testing.T) {
+	tempDir := createFileFixture(t, []string{"a.txt"})
+	if _, err := RenderHierarchySideBySide(tempDir, "/nonexistent/path"); err == nil {
+		t.Error("Expected error for non-existent path, got nil")
+	}
+}
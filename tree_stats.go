@@ -0,0 +1,116 @@
+package palantir
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TreeStats summarizes the shape of a tree, as returned by TreeNode.Stats
+type TreeStats struct {
+	MaxDepth   int   // Levels below the root, 0 for a tree without children
+	TotalNodes int   // Nodes in the tree, including the root
+	DirCount   int   // Directories, objects and arrays, including the root
+	LeafCount  int   // Files and scalars, including a root without children
+	TotalSize  int64 // Sum of the sizes of the files in a filesystem tree
+
+	BuildDuration time.Duration // Time taken to build the tree, only set by BuildWithStats
+}
+
+// Stats computes the statistics of the tree rooted at n in a single traversal
+func (n *TreeNode) Stats() TreeStats {
+	var stats TreeStats
+	n.Walk(func(node *TreeNode, depth int, path []string) error {
+		stats.TotalNodes++
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+
+		if getIsDir(node.Data) || len(node.Children) > 0 {
+			stats.DirCount++
+			return nil
+		}
+		stats.LeafCount++
+		if fileNode, ok := node.Data.(FileNode); ok {
+			stats.TotalSize += fileNode.Size
+		}
+		return nil
+	})
+	return stats
+}
+
+// String summarizes the statistics, such as "12 nodes (3 directories, 9 files), depth 4, 1.5 KB".
+// The size is left out when it is zero, and the build duration is added when it is known.
+func (s TreeStats) String() string {
+	summary := fmt.Sprintf("%s (%s, %s), depth %d",
+		pluralize(s.TotalNodes, "node", "nodes"),
+		pluralize(s.DirCount, "directory", "directories"),
+		pluralize(s.LeafCount, "file", "files"),
+		s.MaxDepth)
+	if s.TotalSize > 0 {
+		summary += ", " + formatSize(s.TotalSize)
+	}
+	if s.BuildDuration > 0 {
+		summary += ", built in " + formatDuration(s.BuildDuration)
+	}
+	return summary
+}
+
+// BuildWithStats is Build, also returning the statistics of the tree and how long the walk took
+func (b *OSTreeBuilder) BuildWithStats(basePath string) (*TreeNode, TreeStats, error) {
+	return timedBuild(func() (*TreeNode, error) { return b.Build(basePath) })
+}
+
+// BuildWithStats is Build, also returning the statistics of the tree and how long the walk took
+func (b *FSTreeBuilder) BuildWithStats(root string) (*TreeNode, TreeStats, error) {
+	return timedBuild(func() (*TreeNode, error) { return b.Build(root) })
+}
+
+// timedBuild runs build and measures it with nowFunc
+func timedBuild(build func() (*TreeNode, error)) (*TreeNode, TreeStats, error) {
+	start := nowFunc()
+	root, err := build()
+	if err != nil {
+		return nil, TreeStats{}, err
+	}
+	duration := nowFunc().Sub(start)
+
+	stats := root.Stats()
+	stats.BuildDuration = duration
+	return root, stats, nil
+}
+
+// timingReport describes how long building a filesystem tree took, such as "scanned 12 nodes in 3ms"
+func timingReport(stats TreeStats) string {
+	return fmt.Sprintf("scanned %s in %s", pluralize(stats.TotalNodes, "node", "nodes"), formatDuration(stats.BuildDuration))
+}
+
+// formatSize formats a byte count with a binary unit, such as "1.5 KB"
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return pluralize(int(size), "byte", "bytes")
+	}
+
+	value, exponent := float64(size)/unit, 0
+	for value >= unit && exponent < 4 {
+		value /= unit
+		exponent++
+	}
+	text := strings.TrimSuffix(fmt.Sprintf("%.1f", value), ".0")
+	return text + " " + string("KMGTP"[exponent]) + "B"
+}
+
+// hierarchySummary describes the entries of a filesystem tree below its root, like the
+// report printed by `tree`, such as "3 directories, 9 files"
+func hierarchySummary(root *TreeNode) string {
+	stats := root.Stats()
+	dirs := stats.DirCount
+	if getIsDir(root.Data) {
+		dirs-- // The root is not reported
+	}
+
+	summary := pluralize(dirs, "directory", "directories") + ", " + pluralize(stats.LeafCount, "file", "files")
+	if stats.TotalSize > 0 {
+		summary += " (" + formatSize(stats.TotalSize) + ")"
+	}
+	return summary
+}
@@ -0,0 +1,173 @@
+package palantir
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestTreeNodeStatsFilesystem(t *testing.T) {
+	fsys := fstest.MapFS{
+		"file1.txt":            {Data: []byte("hello")},
+		"dir1/file2.go":        {Data: make([]byte, 2048)},
+		"dir1/subdir/file3.md": {Data: []byte("# title")},
+		"dir2/file4.json":      {Data: []byte("{}")},
+	}
+	root, err := NewFSTreeBuilder(fsys).Build(".")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	expected := TreeStats{MaxDepth: 3, TotalNodes: 8, DirCount: 4, LeafCount: 4, TotalSize: 2062}
+	if stats := root.Stats(); stats != expected {
+		t.Errorf("Stats() = %+v, want %+v", stats, expected)
+	}
+	if summary := root.Stats().String(); summary != "8 nodes (4 directories, 4 files), depth 3, 2 KB" {
+		t.Errorf("String() = %q", summary)
+	}
+}
+
+func TestTreeNodeStatsYAML(t *testing.T) {
+	root, err := ParseYAMLToTree(limitsYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	// root, app, database, primary, replicas and logging hold children
+	expected := TreeStats{MaxDepth: 4, TotalNodes: 12, DirCount: 6, LeafCount: 6}
+	if stats := root.Stats(); stats != expected {
+		t.Errorf("Stats() = %+v, want %+v", stats, expected)
+	}
+}
+
+func TestTreeNodeStatsEdgeCases(t *testing.T) {
+	empty := &TreeNode{Name: "empty", Data: FileNode{Name: "empty", IsDir: true}}
+	if stats := empty.Stats(); stats != (TreeStats{TotalNodes: 1, DirCount: 1}) {
+		t.Errorf("Stats() of an empty root = %+v", stats)
+	}
+
+	tempDir := createFileFixture(t, []string{"only.txt"})
+	root, err := NewOSTreeBuilder().Build(filepath.Join(tempDir, "only.txt"))
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	stats := root.Stats()
+	if stats != (TreeStats{TotalNodes: 1, LeafCount: 1, TotalSize: 4}) {
+		t.Errorf("Stats() of a single file = %+v", stats)
+	}
+	if summary := stats.String(); summary != "1 node (0 directories, 1 file), depth 0, 4 bytes" {
+		t.Errorf("String() = %q", summary)
+	}
+}
+
+func TestShowHierarchySummary(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	tempDir := createFileFixture(t, multiFileFixture)
+	if err := os.WriteFile(filepath.Join(tempDir, "big.bin"), make([]byte, 3*1024*1024/2), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	output := captureOutput(func() {
+		ShowHierarchy(tempDir, "", WithSummary(), WithMaxNodes(2))
+	})
+
+	// Hidden entries are not part of the tree, and limits do not change the report
+	expected := "\n3 directories, 5 files (1.5 MB)\n"
+	if !strings.HasSuffix(output, expected) {
+		t.Errorf("Expected the tree to end with %q, got:\n%s", expected, output)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:                  "0 bytes",
+		1:                  "1 byte",
+		1023:               "1,023 bytes",
+		1024:               "1 KB",
+		1536:               "1.5 KB",
+		5 * 1024 * 1024:    "5 MB",
+		3 << 40:            "3 TB",
+		1<<30 + 1<<29 + 10: "1.5 GB",
+	}
+	for size, expected := range tests {
+		if result := formatSize(size); result != expected {
+			t.Errorf("formatSize(%d) = %q, want %q", size, result, expected)
+		}
+	}
+}
+
+// stubTickingNow makes every call to nowFunc return a time step later than the last
+func stubTickingNow(t *testing.T, step time.Duration) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldNowFunc := nowFunc
+	nowFunc = func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+	t.Cleanup(func() {
+		nowFunc = oldNowFunc
+	})
+}
+
+func TestBuildWithStats(t *testing.T) {
+	stubTickingNow(t, 25*time.Millisecond)
+	fsys := fstest.MapFS{
+		"file1.txt":            {Data: []byte("hello")},
+		"dir1/file2.go":        {Data: make([]byte, 2048)},
+		"dir1/subdir/file3.md": {Data: []byte("# title")},
+		"dir2/file4.json":      {Data: []byte("{}")},
+	}
+
+	root, stats, err := NewFSTreeBuilder(fsys).BuildWithStats(".")
+	if err != nil {
+		t.Fatalf("BuildWithStats() error = %v", err)
+	}
+	expected := TreeStats{MaxDepth: 3, TotalNodes: 8, DirCount: 4, LeafCount: 4, TotalSize: 2062, BuildDuration: 25 * time.Millisecond}
+	if stats != expected {
+		t.Errorf("BuildWithStats() stats = %+v, want %+v", stats, expected)
+	}
+	if len(root.Children) != 3 {
+		t.Errorf("Expected the built tree to be returned, got %d children", len(root.Children))
+	}
+	if summary := stats.String(); summary != "8 nodes (4 directories, 4 files), depth 3, 2 KB, built in 25ms" {
+		t.Errorf("String() = %q", summary)
+	}
+
+	tempDir := createFileFixture(t, multiFileFixture)
+	root, stats, err = NewOSTreeBuilder().BuildWithStats(tempDir)
+	if err != nil {
+		t.Fatalf("BuildWithStats() error = %v", err)
+	}
+	// Hidden entries are not walked
+	if stats.TotalNodes != 8 || stats.TotalNodes != root.Stats().TotalNodes || stats.BuildDuration != 25*time.Millisecond {
+		t.Errorf("Expected the stats of the built tree, got %+v", stats)
+	}
+
+	if _, _, err := NewOSTreeBuilder().BuildWithStats("/nonexistent/path"); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+}
+
+func TestShowHierarchyReportTiming(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	stubTickingNow(t, 3*time.Millisecond)
+	tempDir := createFileFixture(t, multiFileFixture)
+
+	output := captureOutput(func() {
+		ShowHierarchy(tempDir, "", WithSummary(), WithReportTiming())
+	})
+	expected := "\n3 directories, 4 files (16 bytes)\n\nscanned 8 nodes in 3ms\n"
+	if !strings.HasSuffix(output, expected) {
+		t.Errorf("Expected the tree to end with %q, got:\n%s", expected, output)
+	}
+
+	output = captureOutput(func() {
+		ShowHierarchy(tempDir, "")
+	})
+	if strings.Contains(output, "scanned") {
+		t.Errorf("Expected no timing report by default, got:\n%s", output)
+	}
+}
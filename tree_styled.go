@@ -0,0 +1,67 @@
+package palantir
+
+// FileStyler displays the nodes of a filesystem tree like ShowHierarchy does, coloring
+// directories and files by type and adding the markers enabled by its options. Custom
+// stylers can delegate to it for the nodes they don't restyle.
+type FileStyler struct {
+	options BuildOptions
+}
+
+// NewFileStyler creates a NodeStyler for filesystem nodes styled with the given options
+func NewFileStyler(opts ...BuildOption) *FileStyler {
+	return &FileStyler{options: newBuildOptions(opts)}
+}
+
+// StyleNode returns the styled line for node, without its tree connectors
+func (s *FileStyler) StyleNode(node *Node[FileNode]) string {
+	return styleTreeNode(&TreeNode{Name: node.Name, Data: node.Data}, s.options)
+}
+
+// ShowHierarchyStyled displays a tree structure of files/directories like ShowHierarchy,
+// with each entry displayed as styled by styler. Options that add to an entry's line,
+// such as WithGitStatus or WithDirSlash, are left to the styler, which can delegate to
+// a FileStyler created with them.
+func ShowHierarchyStyled(basePath string, styler NodeStyler[FileNode], opts ...BuildOption) (error, bool) {
+	return ShowHierarchy(basePath, "", append(opts, withNodeStyler(styler))...)
+}
+
+// ShowYAMLHierarchyStyled displays YAML content as a tree like ShowYAMLHierarchy, with each
+// node displayed as styled by styler, which can delegate to a YAMLStyler
+func ShowYAMLHierarchyStyled(yamlContent []byte, styler NodeStyler[YAMLNode], opts ...BuildOption) error {
+	return ShowYAMLHierarchy(yamlContent, append(opts, withNodeStyler(styler))...)
+}
+
+// withNodeStyler renders the nodes holding T data with styler instead of the built-in styling
+func withNodeStyler[T any](styler NodeStyler[T]) BuildOption {
+	return func(o *BuildOptions) {
+		o.nodeStyler = func(root *TreeNode) func(node *TreeNode) (string, bool) {
+			converted := make(map[*TreeNode]*Node[T])
+			genericNode(root, converted)
+			return func(node *TreeNode) (string, bool) {
+				target, ok := converted[node]
+				if !ok {
+					return "", false
+				}
+				return styler.StyleNode(target), true
+			}
+		}
+	}
+}
+
+// genericNode converts node and its descendants into Tree nodes, recording the ones holding
+// T data in converted when it is not nil. Other nodes, such as truncation markers, hold the
+// zero value of T.
+func genericNode[T any](node *TreeNode, converted map[*TreeNode]*Node[T]) *Node[T] {
+	result := &Node[T]{Name: node.Name}
+	if data, ok := node.Data.(T); ok {
+		result.Data = data
+		if converted != nil {
+			converted[node] = result
+		}
+	}
+
+	for _, child := range node.Children {
+		result.Children = append(result.Children, genericNode(child, converted))
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+// upperStyler uppercases node names, delegating the rest of the styling to next
+type upperStyler[T any] struct {
+	next NodeStyler[T]
+}
+
+func (s upperStyler[T]) StyleNode(node *Node[T]) string {
+	return strings.ToUpper(s.next.StyleNode(node))
+}
+
+func TestShowHierarchyStyled(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false, TreeStyle: TreeStyleRounded}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+	tempDir := createFileFixture(t, multiFileFixture)
+
+	output := captureOutput(func() {
+		err, shown := ShowHierarchyStyled(tempDir, upperStyler[FileNode]{NewFileStyler(WithDirSlash())}, WithMaxLines(5))
+		if err != nil || !shown {
+			t.Errorf("ShowHierarchyStyled() = %v, %v", err, shown)
+		}
+	})
+
+	expected := "├── DIR1/\n" +
+		"│   ├── SUBDIR/\n" +
+		"│   │   ╰── FILE3.MD\n" +
+		"│   ╰── FILE2.GO\n" +
+		"├── DIR2/\n" +
+		"... output truncated (5 lines shown)\n"
+	if output != expected {
+		t.Errorf("ShowHierarchyStyled() output = %q, want %q", output, expected)
+	}
+}
+
+func TestShowYAMLHierarchyStyled(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false, ASCIIOnly: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+	content := []byte("server:\n  host: localhost\n  port: 8080\n")
+
+	// Highlight values that differ from the defaults
+	defaults := map[string]interface{}{"host": "localhost", "port": 80}
+	base := NewYAMLStyler(WithShowValues())
+	styler := NodeStylerFunc[YAMLNode](func(node *Node[YAMLNode]) string {
+		if value, ok := defaults[node.Name]; ok && value != node.Data.Value {
+			return "* " + base.StyleNode(node)
+		}
+		return base.StyleNode(node)
+	})
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchyStyled(content, styler, WithShowRoot()); err != nil {
+			t.Errorf("ShowYAMLHierarchyStyled() error = %v", err)
+		}
+	})
+
+	expected := "root\n" +
+		"`-- server\n" +
+		"    |-- host: localhost\n" +
+		"    `-- * port: 8080\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchyStyled() output = %q, want %q", output, expected)
+	}
+
+	// The default styling is unaffected
+	output = captureOutput(func() {
+		ShowYAMLHierarchy(content)
+	})
+	if strings.Contains(output, "*") || strings.Contains(output, "8080") {
+		t.Errorf("Expected the built-in styling without a styler, got %q", output)
+	}
+}
+
+func TestGenericNodeConversion(t *testing.T) {
+	root := &TreeNode{Name: "root", Data: FileNode{Name: "root", IsDir: true}, Children: []*TreeNode{
+		{Name: "a.go", Data: FileNode{Name: "a.go", Size: 3}},
+		{Name: "... 2 more", Data: truncationMarker{}},
+	}}
+
+	converted := make(map[*TreeNode]*Node[FileNode])
+	node := genericNode(root, converted)
+	if len(node.Children) != 2 || node.Children[0].Data.Size != 3 || node.Children[1].Name != "... 2 more" {
+		t.Errorf("Unexpected conversion: %+v", node)
+	}
+	if len(converted) != 2 || converted[root.Children[1]] != nil {
+		t.Errorf("Expected only nodes holding FileNode data to be recorded, got %d", len(converted))
+	}
+}
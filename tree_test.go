@@ -1,11 +1,13 @@
 package palantir
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildTree(t *testing.T) {
@@ -587,6 +589,49 @@ func TestSortTreeEdgeCases(t *testing.T) {
 	}
 }
 
+func TestShowFileTreeWithoutSorting(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	root := &TreeNode{Name: "root", Data: FileNode{Name: "root", IsDir: true}, Children: []*TreeNode{
+		{Name: "zeta.txt", Data: FileNode{Name: "zeta.txt"}},
+		{Name: "docs", Data: FileNode{Name: "docs", IsDir: true}, Children: []*TreeNode{
+			{Name: "b.md", Data: FileNode{Name: "b.md"}},
+			{Name: "a.md", Data: FileNode{Name: "a.md"}},
+		}},
+		{Name: "alpha.txt", Data: FileNode{Name: "alpha.txt"}},
+	}}
+
+	output := captureOutput(func() {
+		showFileTree(root, newBuildOptions([]BuildOption{WithoutSorting()}))
+	})
+
+	expected := "├── zeta.txt\n├── docs\n│   ├── b.md\n│   └── a.md\n└── alpha.txt\n"
+	if output != expected {
+		t.Errorf("Unsorted output =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowHierarchyWithoutSortingKeepsWalkOrder(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+	tempDir := createFileFixture(t, []string{"a.txt", "b/c.txt"})
+
+	sorted := captureOutput(func() { ShowHierarchy(tempDir, "") })
+	unsorted := captureOutput(func() { ShowHierarchy(tempDir, "", WithoutSorting()) })
+
+	if expected := "├── b\n│   └── c.txt\n└── a.txt\n"; sorted != expected {
+		t.Errorf("Sorted output =\n%s\nwant:\n%s", sorted, expected)
+	}
+	if expected := "├── a.txt\n└── b\n    └── c.txt\n"; unsorted != expected {
+		t.Errorf("Unsorted output =\n%s\nwant:\n%s", unsorted, expected)
+	}
+}
+
 func TestShowHierarchyEmptyDirectory(t *testing.T) {
 	// Create empty directory
 	tempDir, err := os.MkdirTemp("", "palantir_empty_hierarchy_test")
@@ -1276,8 +1321,8 @@ server:
 		if !yamlNode.IsDir {
 			t.Error("Expected YAMLNode IsDir to be true for array")
 		}
-		if yamlNode.NodeType != "object" {
-			t.Errorf("Expected YAMLNode NodeType 'object', got %q", yamlNode.NodeType)
+		if yamlNode.NodeType != "array" {
+			t.Errorf("Expected YAMLNode NodeType 'array', got %q", yamlNode.NodeType)
 		}
 	} else {
 		t.Error("Expected YAMLNode data type for array")
@@ -1296,10 +1341,423 @@ server:
 		if yamlNode.IsDir {
 			t.Error("Expected YAMLNode IsDir to be false for array item")
 		}
-		if yamlNode.NodeType != "array" {
-			t.Errorf("Expected YAMLNode NodeType 'array', got %q", yamlNode.NodeType)
+		if yamlNode.NodeType != "scalar" || yamlNode.ScalarKind != "string" || !yamlNode.Item {
+			t.Errorf("Expected a string scalar array item, got %+v", yamlNode)
 		}
 	} else {
 		t.Error("Expected YAMLNode data type for array item")
 	}
 }
+
+func TestYAMLNodeTypesForArrays(t *testing.T) {
+	root, err := ParseYAMLToTree([]byte(`
+jobs:
+  - name: build
+    steps: [checkout, test]
+  - [1, 2]
+values: [text, 42, 1.5, true, null]
+`))
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	jobs := findChild(root, "jobs")
+	if data := jobs.Data.(YAMLNode); data.NodeType != "array" || !data.IsDir {
+		t.Errorf("Expected jobs to be an array container, got %+v", data)
+	}
+	if data := jobs.Children[0].Data.(YAMLNode); data.NodeType != "object" || !data.IsDir || !data.Item {
+		t.Errorf("Expected the first job to be an object item, got %+v", data)
+	}
+	if data := jobs.Children[1].Data.(YAMLNode); data.NodeType != "array" || !data.IsDir || !data.Item {
+		t.Errorf("Expected the nested sequence to be an array item, got %+v", data)
+	}
+	if data := findChild(jobs.Children[0], "steps").Data.(YAMLNode); data.NodeType != "array" || data.Item {
+		t.Errorf("Expected steps to be an array, got %+v", data)
+	}
+
+	expectedKinds := []string{"string", "int", "float", "bool", "null"}
+	values := findChild(root, "values")
+	for i, kind := range expectedKinds {
+		data := values.Children[i].Data.(YAMLNode)
+		if data.NodeType != "scalar" || data.ScalarKind != kind || !data.Item {
+			t.Errorf("Expected item %d to be a %s scalar, got %+v", i, kind, data)
+		}
+	}
+
+	if data := findChild(findChild(jobs.Children[0], "steps"), "test").Data.(YAMLNode); data.ScalarKind != "string" {
+		t.Errorf("Expected nested scalar items to record their kind, got %+v", data)
+	}
+	if data := findChild(jobs.Children[0], "name").Data.(YAMLNode); data.NodeType != "scalar" || data.ScalarKind != "string" || data.Item {
+		t.Errorf("Expected name to be a string scalar, got %+v", data)
+	}
+}
+
+func TestShowHierarchyShowRoot(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	tempDir, err := os.MkdirTemp("", "palantir_show_root_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, file := range []string{"file1.txt", "dir1/file2.go"} {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", fullPath, err)
+		}
+	}
+	rootName := filepath.Base(tempDir)
+
+	tests := []struct {
+		name     string
+		opts     []BuildOption
+		expected string
+	}{
+		{
+			name:     "Root hidden by default",
+			opts:     nil,
+			expected: "├── dir1\n│   └── file2.go\n└── file1.txt\n",
+		},
+		{
+			name:     "Root shown",
+			opts:     []BuildOption{WithShowRoot()},
+			expected: rootName + "\n├── dir1\n│   └── file2.go\n└── file1.txt\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				if err, _ := ShowHierarchy(tempDir, "", tt.opts...); err != nil {
+					t.Errorf("ShowHierarchy() error = %v", err)
+				}
+			})
+			if output != tt.expected {
+				t.Errorf("ShowHierarchy() output = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShowYAMLHierarchyShowRoot(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	yamlContent := []byte(`
+server:
+  port: 8080
+`)
+
+	tests := []struct {
+		name     string
+		opts     []BuildOption
+		expected string
+	}{
+		{
+			name:     "Root hidden by default",
+			opts:     nil,
+			expected: "└── server\n    └── port\n",
+		},
+		{
+			name:     "Root shown",
+			opts:     []BuildOption{WithShowRoot()},
+			expected: "root\n└── server\n    └── port\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				if err := ShowYAMLHierarchy(yamlContent, tt.opts...); err != nil {
+					t.Errorf("ShowYAMLHierarchy() error = %v", err)
+				}
+			})
+			if output != tt.expected {
+				t.Errorf("ShowYAMLHierarchy() output = %q, want %q", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStyleTreeNodeDirSlash(t *testing.T) {
+	dirNode := &TreeNode{Name: "dir1", Data: FileNode{Name: "dir1", IsDir: true}}
+	fileNode := &TreeNode{Name: "main.go", Data: FileNode{Name: "main.go", IsDir: false}}
+	yamlObject := &TreeNode{Name: "database", Data: YAMLNode{Name: "database", IsDir: true, NodeType: "object"}}
+
+	for _, useColors := range []bool{true, false} {
+		t.Run(fmt.Sprintf("UseColors_%t", useColors), func(t *testing.T) {
+			SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: useColors}))
+			t.Cleanup(func() {
+				SetGlobalOutputHandler(NewDefaultOutputHandler())
+			})
+			options := newBuildOptions([]BuildOption{WithDirSlash()})
+
+			if result := styleTreeNode(dirNode, options); !strings.HasSuffix(result, "dir1"+ColorReset+"/") && result != "dir1/" {
+				t.Errorf("Expected directory to end with a slash, got %q", result)
+			}
+			if result := styleTreeNode(yamlObject, options); !strings.HasSuffix(result, "/") {
+				t.Errorf("Expected YAML object to end with a slash, got %q", result)
+			}
+			if result := styleTreeNode(fileNode, options); strings.HasSuffix(result, "/") {
+				t.Errorf("Expected file not to end with a slash, got %q", result)
+			}
+			if result := styleTreeNode(dirNode, BuildOptions{}); strings.HasSuffix(result, "/") {
+				t.Errorf("Expected no slash without the option, got %q", result)
+			}
+		})
+	}
+}
+
+// customOutputHandler is a minimal OutputHandler implementation that is not an *outputHandler
+type customOutputHandler struct {
+	config *OutputConfig
+}
+
+func (h *customOutputHandler) PrintHeader(message string)                                     {}
+func (h *customOutputHandler) PrintHeaderWithSubtitle(title, subtitle string)                 {}
+func (h *customOutputHandler) PrintStage(message string)                                      {}
+func (h *customOutputHandler) PrintSuccess(message string)                                    {}
+func (h *customOutputHandler) PrintSuccessWithDuration(message string, d time.Duration)       {}
+func (h *customOutputHandler) PrintError(format string, args ...interface{})                  {}
+func (h *customOutputHandler) PrintErrors(errs ...error)                                      {}
+func (h *customOutputHandler) PrintCommandOutput(label, output string)                        {}
+func (h *customOutputHandler) LogTree(level OutputLevel, tree *TreeNode, opts ...BuildOption) {}
+func (h *customOutputHandler) PrintWarning(format string, args ...interface{})                {}
+func (h *customOutputHandler) PrintInfo(format string, args ...interface{})                   {}
+func (h *customOutputHandler) PrintAlreadyAvailable(format string, args ...interface{})       {}
+func (h *customOutputHandler) PrintColored(color string, format string, args ...interface{})  {}
+func (h *customOutputHandler) PrintProgress(current, total int, message string)               {}
+func (h *customOutputHandler) Confirm(message string) bool                                    { return false }
+func (h *customOutputHandler) ConfirmE(message string) (bool, error)                          { return false, nil }
+func (h *customOutputHandler) IsSupported() bool                                              { return true }
+func (h *customOutputHandler) Disable()                                                       {}
+func (h *customOutputHandler) WithPrefix(prefix string) OutputHandler                         { return h }
+func (h *customOutputHandler) Config() *OutputConfig                                          { return h.config }
+func (h *customOutputHandler) Close() error                                                   { return nil }
+func (h *customOutputHandler) Flush() error                                                   { return nil }
+func (h *customOutputHandler) NewTaskList() *TaskList                                         { return newTaskList(h) }
+func (h *customOutputHandler) NewMultiProgress() *MultiProgress                               { return newMultiProgress(h) }
+func (h *customOutputHandler) RunStep(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return runStep(h, ctx, name, fn)
+}
+func (h *customOutputHandler) RunSteps(ctx context.Context, steps []Step) error {
+	return runSteps(ctx, steps, h.RunStep, printStepsSummaryTo(h))
+}
+func (h *customOutputHandler) PrintErrorWithStack(err error) {}
+func (h *customOutputHandler) RecoverAndPrint()              { recover() }
+func (h *customOutputHandler) SafeGo(fn func())              { go func() { defer h.RecoverAndPrint(); fn() }() }
+func (h *customOutputHandler) Bell()                         {}
+func (h *customOutputHandler) TimedStage(name string, fn func() error) error {
+	return timedStage(name, fn, h.PrintStage, stageResultPrinter(h))
+}
+func (h *customOutputHandler) TimedStageContext(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return timedStageContext(ctx, name, fn, h.PrintStage, stageResultPrinter(h))
+}
+
+func (h *customOutputHandler) BeginStage(name string) *StageScope {
+	return beginStage(h, nil, name)
+}
+
+func (h *customOutputHandler) CountLines(level OutputLevel, message string) int { return 0 }
+
+func TestTreeRenderingWithCustomGlobalHandler(t *testing.T) {
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	tests := []struct {
+		name        string
+		config      *OutputConfig
+		expectColor bool
+	}{
+		{"Custom handler with colors", &OutputConfig{UseColors: true}, true},
+		{"Custom handler without colors", &OutputConfig{UseColors: false}, false},
+		{"Custom handler without config", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetGlobalOutputHandler(&customOutputHandler{config: tt.config})
+
+			var err error
+			output := captureOutput(func() {
+				err = ShowYAMLHierarchy([]byte("server:\n  port: 8080\n"), WithShowValues())
+			})
+			if err != nil {
+				t.Fatalf("ShowYAMLHierarchy() error = %v", err)
+			}
+
+			if !strings.Contains(output, "server") || !strings.Contains(output, "8080") {
+				t.Errorf("Expected tree to render, got %q", output)
+			}
+			if hasColor := strings.Contains(output, ColorReset); hasColor != tt.expectColor {
+				t.Errorf("Expected colors=%v, got %q", tt.expectColor, output)
+			}
+		})
+	}
+}
+
+func TestTreeConnectorStyles(t *testing.T) {
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	yamlContent := []byte("app:\n  name: palantir\n  port: 8080\nlogging: info\n")
+
+	tests := []struct {
+		name     string
+		config   *OutputConfig
+		expected string
+	}{
+		{
+			"Sharp by default",
+			&OutputConfig{},
+			"├── app\n│   ├── name\n│   └── port\n└── logging\n",
+		},
+		{
+			"Rounded",
+			&OutputConfig{TreeStyle: TreeStyleRounded},
+			"├── app\n│   ├── name\n│   ╰── port\n╰── logging\n",
+		},
+		{
+			"Double",
+			&OutputConfig{TreeStyle: TreeStyleDouble},
+			"╠══ app\n║   ╠══ name\n║   ╚══ port\n╚══ logging\n",
+		},
+		{
+			"ASCII overrides the style",
+			&OutputConfig{TreeStyle: TreeStyleDouble, ASCIIOnly: true},
+			"|-- app\n|   |-- name\n|   `-- port\n`-- logging\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetGlobalOutputHandler(NewOutputHandler(tt.config))
+			output := captureOutput(func() {
+				ShowYAMLHierarchy(yamlContent)
+			})
+			if output != tt.expected {
+				t.Errorf("ShowYAMLHierarchy() =\n%s\nwant:\n%s", output, tt.expected)
+			}
+		})
+	}
+}
+
+// removeFiles drops the files below node whose extension differs from ext
+func removeFiles(node *TreeNode, ext string) {
+	kept := node.Children[:0]
+	for _, child := range node.Children {
+		if getIsDir(child.Data) {
+			removeFiles(child, ext)
+		} else if filepath.Ext(child.Name) != ext {
+			continue
+		}
+		kept = append(kept, child)
+	}
+	node.Children = kept
+}
+
+func TestTreeNodePrune(t *testing.T) {
+	tempDir := createFileFixture(t, []string{"cmd/main.go", "docs/guide.md", "docs/img/logo.png", "README.md"})
+
+	root, err := NewOSTreeBuilder().Build(tempDir)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	removeFiles(root, ".md")
+	root.Prune()
+	sortTree(root)
+	if names := childNames(root); strings.Join(names, ",") != "docs,README.md" {
+		t.Errorf("Expected only directories with markdown files, got %v", names)
+	}
+	if names := childNames(findChild(root, "docs")); strings.Join(names, ",") != "guide.md" {
+		t.Errorf("Expected docs/img to be pruned, got %v", names)
+	}
+
+	removeFiles(root, ".xyz")
+	root.Prune()
+	if len(root.Children) != 0 {
+		t.Errorf("Expected an empty tree, got %v", childNames(root))
+	}
+	if root.Name == "" || !getIsDir(root.Data) {
+		t.Error("Expected the root to be kept")
+	}
+}
+
+func TestTreeNodePruneKeepsEmptyYAMLContainers(t *testing.T) {
+	root, err := ParseYAMLToTree([]byte("labels: {}\nports: []\nserver:\n  env: {}\n  port: 8080\n"))
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	root.Prune()
+	if names := childNames(root); strings.Join(names, ",") != "labels,ports,server" {
+		t.Errorf("Expected empty mappings and sequences to be kept, got %v", names)
+	}
+	if names := childNames(findChild(root, "server")); strings.Join(names, ",") != "env,port" {
+		t.Errorf("Expected the nested empty mapping to be kept, got %v", names)
+	}
+}
+
+func TestTreeStylingHonorsOutputConfig(t *testing.T) {
+	root := &TreeNode{Name: "root", Data: FileNode{Name: "root", IsDir: true}, Children: []*TreeNode{
+		{Name: "cmd", Data: FileNode{Name: "cmd", IsDir: true}},
+		{Name: "main.go", Data: FileNode{Name: "main.go"}},
+		{Name: "new.txt", Data: FileNode{Name: "new.txt", GitStatus: GitUntracked}},
+	}}
+	yamlRoot, err := ParseYAMLToTree([]byte("server:\n  port: 8080\ntags: [a]\n"))
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	boldDir := ColorBold + ColorBlue + "cmd" + ColorReset
+	plainDir := ColorBlue + "cmd" + ColorReset
+	goFile := ColorPurple + "main.go" + ColorReset
+	gitCode := ColorGreen + "??" + ColorReset
+	port := ColorCyan + "8080" + ColorReset
+
+	tests := []struct {
+		name       string
+		config     *OutputConfig
+		present    []string
+		absent     []string
+		plainNames bool
+	}{
+		{"Colors off", &OutputConfig{UseFormatting: true}, []string{"cmd", "new.txt ??", "port: 8080"}, []string{"\033["}, true},
+		{"Colors and formatting", &OutputConfig{UseColors: true, UseFormatting: true}, []string{boldDir, goFile, gitCode, port}, nil, false},
+		{"Formatting off", &OutputConfig{UseColors: true}, []string{plainDir, goFile, gitCode, port}, []string{ColorBold}, false},
+		{"Colorize level only", &OutputConfig{UseColors: true, UseFormatting: true, ColorizeLevelOnly: true}, []string{"├── cmd\n", "└── new.txt " + gitCode, "port: 8080\n"}, []string{ColorBold, ColorBlue, goFile, port}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetGlobalOutputHandler(NewOutputHandler(tt.config))
+			t.Cleanup(func() {
+				SetGlobalOutputHandler(NewDefaultOutputHandler())
+			})
+
+			output := captureOutput(func() {
+				renderTree(root, BuildOptions{GitStatus: true, GitColors: true})
+				renderTree(yamlRoot, BuildOptions{ShowValues: true})
+			})
+			for _, text := range tt.present {
+				if !strings.Contains(output, text) {
+					t.Errorf("Expected %q in %q", text, output)
+				}
+			}
+			for _, text := range tt.absent {
+				if strings.Contains(output, text) {
+					t.Errorf("Expected no %q in %q", text, output)
+				}
+			}
+			if hasPlain := strings.Contains(output, "── server\n"); hasPlain != tt.plainNames {
+				t.Errorf("Expected plain YAML names to be %v, got %q", tt.plainNames, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package palantir
+
+import "errors"
+
+// ErrSkipChildren can be returned by a Walk callback to skip the children of the node
+// it was called for. It is never returned by Walk itself.
+var ErrSkipChildren = errors.New("skip children")
+
+// WalkFunc is called for every node visited by Walk and WalkBreadthFirst. depth is 0 for
+// the node the walk starts at, and path holds the names of the nodes below it leading
+// to node, so len(path) == depth. path is only valid during the call; copy it to keep it.
+type WalkFunc func(node *TreeNode, depth int, path []string) error
+
+// Walk visits n and its descendants depth-first, each node before its children. The
+// walk stops at the first error fn returns, which Walk returns, except ErrSkipChildren,
+// which prunes the node's children and continues.
+func (n *TreeNode) Walk(fn WalkFunc) error {
+	err := walkTreeNode(n, 0, nil, fn)
+	if err == ErrSkipChildren {
+		return nil
+	}
+	return err
+}
+
+// walkTreeNode visits node and then its children depth-first
+func walkTreeNode(node *TreeNode, depth int, path []string, fn WalkFunc) error {
+	if err := fn(node, depth, path); err != nil {
+		return err
+	}
+
+	for _, child := range node.Children {
+		err := walkTreeNode(child, depth+1, append(path, child.Name), fn)
+		if err != nil && err != ErrSkipChildren {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkBreadthFirst visits n and its descendants level by level, like Walk but visiting
+// every node at one depth before any node deeper down
+func (n *TreeNode) WalkBreadthFirst(fn WalkFunc) error {
+	type queued struct {
+		node *TreeNode
+		path []string
+	}
+
+	queue := []queued{{node: n}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		err := fn(current.node, len(current.path), current.path)
+		if err == ErrSkipChildren {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, child := range current.node.Children {
+			path := make([]string, len(current.path)+1)
+			copy(path, current.path)
+			path[len(current.path)] = child.Name
+			queue = append(queue, queued{node: child, path: path})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package palantir
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mixedTree returns a filesystem tree with a parsed YAML document attached below config.yaml
+func mixedTree(t *testing.T) *TreeNode {
+	t.Helper()
+	document, err := ParseYAMLToTree([]byte("server:\n  host: localhost\n  port: 8080\nname: app\n"))
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	return &TreeNode{
+		Name: "project",
+		Data: FileNode{Name: "project", IsDir: true},
+		Children: []*TreeNode{
+			{
+				Name:     "config.yaml",
+				Data:     FileNode{Name: "config.yaml"},
+				Children: document.Children,
+			},
+			{Name: "main.go", Data: FileNode{Name: "main.go"}},
+		},
+	}
+}
+
+// visit records the slash-joined path of every node a walk reaches
+func visit(visited *[]string) WalkFunc {
+	return func(node *TreeNode, depth int, path []string) error {
+		if len(path) != depth {
+			return errors.New("path length does not match depth")
+		}
+		*visited = append(*visited, strings.Join(path, "/"))
+		return nil
+	}
+}
+
+func TestTreeNodeWalk(t *testing.T) {
+	var visited []string
+	if err := mixedTree(t).Walk(visit(&visited)); err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	expected := []string{
+		"",
+		"config.yaml",
+		"config.yaml/server",
+		"config.yaml/server/host",
+		"config.yaml/server/port",
+		"config.yaml/name",
+		"main.go",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Walk() visited %q, want %q", visited, expected)
+	}
+}
+
+func TestTreeNodeWalkBreadthFirst(t *testing.T) {
+	var visited []string
+	if err := mixedTree(t).WalkBreadthFirst(visit(&visited)); err != nil {
+		t.Fatalf("WalkBreadthFirst() error = %v", err)
+	}
+
+	expected := []string{
+		"",
+		"config.yaml",
+		"main.go",
+		"config.yaml/server",
+		"config.yaml/name",
+		"config.yaml/server/host",
+		"config.yaml/server/port",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("WalkBreadthFirst() visited %q, want %q", visited, expected)
+	}
+}
+
+func TestTreeNodeWalkSkipChildren(t *testing.T) {
+	for name, walk := range map[string]func(*TreeNode, WalkFunc) error{
+		"Depth first":   (*TreeNode).Walk,
+		"Breadth first": (*TreeNode).WalkBreadthFirst,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var visited []string
+			err := walk(mixedTree(t), func(node *TreeNode, depth int, path []string) error {
+				visited = append(visited, node.Name)
+				if node.Name == "server" {
+					return ErrSkipChildren
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("walk error = %v", err)
+			}
+
+			for _, name := range visited {
+				if name == "host" || name == "port" {
+					t.Errorf("Expected the children of server to be skipped, visited %q", visited)
+				}
+			}
+			if len(visited) != 5 {
+				t.Errorf("Expected 5 nodes to be visited, got %q", visited)
+			}
+		})
+	}
+}
+
+func TestTreeNodeWalkError(t *testing.T) {
+	stop := errors.New("stop")
+
+	for name, walk := range map[string]func(*TreeNode, WalkFunc) error{
+		"Depth first":   (*TreeNode).Walk,
+		"Breadth first": (*TreeNode).WalkBreadthFirst,
+	} {
+		t.Run(name, func(t *testing.T) {
+			visits := 0
+			err := walk(mixedTree(t), func(node *TreeNode, depth int, path []string) error {
+				visits++
+				if node.Name == "config.yaml" {
+					return stop
+				}
+				return nil
+			})
+			if err != stop {
+				t.Errorf("Expected the callback's error, got %v", err)
+			}
+			if visits != 2 {
+				t.Errorf("Expected the walk to stop after 2 visits, got %d", visits)
+			}
+		})
+	}
+}
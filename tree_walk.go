@@ -0,0 +1,93 @@
+package palantir
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TreeEvent describes an entry reached while walking a hierarchy, or an error
+// encountered at Path when Err is set
+type TreeEvent struct {
+	Path  string // OS path of the entry, including the base path
+	IsDir bool
+	Depth int // 1 for entries directly inside the base path
+	Err   error
+}
+
+// WalkHierarchy walks basePath in the background and emits an event per non-hidden
+// entry as it is reached, so large trees can be rendered incrementally. The channel
+// is closed when the walk completes or ctx is done. Errors are delivered as events and
+// stop the walk unless WithSkipErrors is given. Consumers that stop reading early must
+// cancel ctx, and otherwise drain the channel, or the walking goroutine leaks.
+func WalkHierarchy(ctx context.Context, basePath string, opts ...BuildOption) (<-chan TreeEvent, error) {
+	info, err := os.Stat(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", basePath)
+	}
+
+	events := make(chan TreeEvent)
+	go func() {
+		defer close(events)
+		walkHierarchy(ctx, os.DirFS(basePath), events, newBuildOptions(opts), func(relPath string) string {
+			return filepath.Join(basePath, filepath.FromSlash(relPath))
+		})
+	}()
+	return events, nil
+}
+
+// walkHierarchy sends an event for every non-hidden entry of fsys, stopping once ctx is
+// done. pathFor converts a slash-separated path relative to the root into the path
+// reported on events.
+func walkHierarchy(ctx context.Context, fsys fs.FS, events chan<- TreeEvent, options BuildOptions, pathFor func(relPath string) string) {
+	send := func(event TreeEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	fs.WalkDir(fsys, ".", func(fsPath string, entry fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return fs.SkipAll
+		}
+		depth := strings.Count(fsPath, "/") + 1
+		if fsPath == "." {
+			depth = 0
+		}
+
+		if err != nil {
+			if !send(TreeEvent{Path: pathFor(fsPath), IsDir: entry != nil && entry.IsDir(), Depth: depth, Err: err}) || !options.SkipErrors {
+				return fs.SkipAll
+			}
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if fsPath == "." {
+			return nil // Skip root directory itself
+		}
+
+		// Skip hidden files
+		if strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !send(TreeEvent{Path: pathFor(fsPath), IsDir: entry.IsDir(), Depth: depth}) {
+			return fs.SkipAll
+		}
+		return nil
+	})
+}
@@ -0,0 +1,143 @@
+package palantir
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// drainEvents collects every event until the channel closes
+func drainEvents(events <-chan TreeEvent) []TreeEvent {
+	var collected []TreeEvent
+	for event := range events {
+		collected = append(collected, event)
+	}
+	return collected
+}
+
+func TestWalkHierarchy(t *testing.T) {
+	basePath := createFileFixture(t, []string{
+		"level1/level2/level3/level4/deep.txt",
+		"level1/shallow.txt",
+		"root.txt",
+		".hidden/secret.txt",
+	})
+
+	events, err := WalkHierarchy(context.Background(), basePath)
+	if err != nil {
+		t.Fatalf("WalkHierarchy() error = %v", err)
+	}
+
+	expected := []TreeEvent{
+		{Path: filepath.Join(basePath, "level1"), IsDir: true, Depth: 1},
+		{Path: filepath.Join(basePath, "level1", "level2"), IsDir: true, Depth: 2},
+		{Path: filepath.Join(basePath, "level1", "level2", "level3"), IsDir: true, Depth: 3},
+		{Path: filepath.Join(basePath, "level1", "level2", "level3", "level4"), IsDir: true, Depth: 4},
+		{Path: filepath.Join(basePath, "level1", "level2", "level3", "level4", "deep.txt"), IsDir: false, Depth: 5},
+		{Path: filepath.Join(basePath, "level1", "shallow.txt"), IsDir: false, Depth: 2},
+		{Path: filepath.Join(basePath, "root.txt"), IsDir: false, Depth: 1},
+	}
+	if collected := drainEvents(events); !reflect.DeepEqual(collected, expected) {
+		t.Errorf("WalkHierarchy() events = %+v, want %+v", collected, expected)
+	}
+}
+
+func TestWalkHierarchyCancel(t *testing.T) {
+	fsys := fstest.MapFS{"a.txt": {}, "b.txt": {}, "c.txt": {}}
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan TreeEvent)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		walkHierarchy(ctx, fsys, events, BuildOptions{}, func(relPath string) string { return relPath })
+	}()
+
+	<-events
+	cancel() // Stop reading without draining the remaining events
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the walk to stop once ctx is cancelled")
+	}
+}
+
+func TestWalkHierarchyInvalidPath(t *testing.T) {
+	if _, err := WalkHierarchy(context.Background(), filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+
+	file := createFileFixture(t, []string{"file.txt"})
+	if _, err := WalkHierarchy(context.Background(), filepath.Join(file, "file.txt")); err == nil {
+		t.Error("Expected an error for a file path")
+	}
+}
+
+// failingFS fails to read the named directory
+type failingFS struct {
+	fs.FS
+	failDir string
+}
+
+func (f failingFS) Open(name string) (fs.File, error) {
+	if name == f.failDir {
+		return nil, errors.New("permission denied")
+	}
+	return f.FS.Open(name)
+}
+
+func TestWalkHierarchyErrors(t *testing.T) {
+	fsys := failingFS{
+		FS: fstest.MapFS{
+			"a/locked/file.txt": {Data: []byte("test")},
+			"b/file.txt":        {Data: []byte("test")},
+		},
+		failDir: "a/locked",
+	}
+
+	tests := []struct {
+		name       string
+		options    BuildOptions
+		finalPath  string
+		eventCount int
+	}{
+		{"Stops at the first error", BuildOptions{}, "a/locked", 3},
+		{"Skips errors", BuildOptions{SkipErrors: true}, "b/file.txt", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make(chan TreeEvent)
+			go func() {
+				defer close(events)
+				walkHierarchy(context.Background(), fsys, events, tt.options, func(relPath string) string { return relPath })
+			}()
+
+			collected := drainEvents(events)
+			if len(collected) != tt.eventCount {
+				t.Fatalf("Expected %d events, got %+v", tt.eventCount, collected)
+			}
+			if last := collected[len(collected)-1]; last.Path != tt.finalPath {
+				t.Errorf("Expected the last event at %s, got %+v", tt.finalPath, last)
+			}
+
+			var errorEvents int
+			for _, event := range collected {
+				if event.Err != nil {
+					errorEvents++
+					if event.Path != "a/locked" || !event.IsDir {
+						t.Errorf("Expected the error at a/locked, got %+v", event)
+					}
+				}
+			}
+			if errorEvents != 1 {
+				t.Errorf("Expected exactly one error event, got %d", errorEvents)
+			}
+		})
+	}
+}
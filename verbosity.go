@@ -0,0 +1,107 @@
+package palantir
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Verbosity levels understood by ApplyVerbosity
+const (
+	VerbosityQuiet   = -1 // Only warnings and errors
+	VerbosityDefault = 0  // Regular output
+	VerbosityVerbose = 1  // Verbose output, enables VerboseMode
+	VerbosityTrace   = 2  // Most detailed output
+)
+
+// ApplyVerbosity configures cfg for a verbosity level, typically derived from -v/-q flags.
+// Levels below VerbosityQuiet or above VerbosityTrace are clamped.
+func ApplyVerbosity(cfg *OutputConfig, verbosity int) {
+	if verbosity < VerbosityQuiet {
+		verbosity = VerbosityQuiet
+	}
+	if verbosity > VerbosityTrace {
+		verbosity = VerbosityTrace
+	}
+
+	cfg.Verbosity = verbosity
+	cfg.QuietMode = verbosity == VerbosityQuiet
+	cfg.VerboseMode = verbosity >= VerbosityVerbose
+}
+
+// VerbosityFlag is a flag.Value counting verbosity for the standard flag package.
+// Every "-v" raises the level by one and "-v=2" sets it explicitly:
+//
+//	var verbosity palantir.VerbosityFlag
+//	flag.Var(&verbosity, "v", "increase verbosity")
+//	flag.Var(verbosity.Quiet(), "q", "only print warnings and errors")
+//	flag.Parse()
+//	verbosity.Apply(config)
+type VerbosityFlag int
+
+// String returns the current verbosity level
+func (v *VerbosityFlag) String() string {
+	if v == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*v))
+}
+
+// Set raises the verbosity for a bare flag or sets it to an explicit level
+func (v *VerbosityFlag) Set(value string) error {
+	if value == "true" {
+		*v++
+		return nil
+	}
+
+	level, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid verbosity %q: %w", value, err)
+	}
+	*v = VerbosityFlag(level)
+	return nil
+}
+
+// IsBoolFlag allows the flag to be passed without a value
+func (v *VerbosityFlag) IsBoolFlag() bool {
+	return true
+}
+
+// Quiet returns a boolean flag.Value that lowers the verbosity to VerbosityQuiet when set
+func (v *VerbosityFlag) Quiet() *QuietFlag {
+	return &QuietFlag{verbosity: v}
+}
+
+// Apply configures cfg for the parsed verbosity level
+func (v *VerbosityFlag) Apply(cfg *OutputConfig) {
+	ApplyVerbosity(cfg, int(*v))
+}
+
+// QuietFlag is the boolean counterpart of VerbosityFlag, usually bound to -q
+type QuietFlag struct {
+	verbosity *VerbosityFlag
+}
+
+// String reports whether quiet mode was requested
+func (q *QuietFlag) String() string {
+	if q == nil || q.verbosity == nil {
+		return "false"
+	}
+	return strconv.FormatBool(*q.verbosity == VerbosityQuiet)
+}
+
+// Set enables quiet mode when value is true
+func (q *QuietFlag) Set(value string) error {
+	quiet, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid quiet flag %q: %w", value, err)
+	}
+	if quiet {
+		*q.verbosity = VerbosityQuiet
+	}
+	return nil
+}
+
+// IsBoolFlag allows the flag to be passed without a value
+func (q *QuietFlag) IsBoolFlag() bool {
+	return true
+}
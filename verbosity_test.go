@@ -0,0 +1,110 @@
+package palantir
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestApplyVerbosity(t *testing.T) {
+	tests := []struct {
+		name          string
+		verbosity     int
+		wantVerbosity int
+		wantQuiet     bool
+		wantVerbose   bool
+	}{
+		{"Below quiet is clamped", -5, VerbosityQuiet, true, false},
+		{"Quiet", VerbosityQuiet, VerbosityQuiet, true, false},
+		{"Default", VerbosityDefault, VerbosityDefault, false, false},
+		{"Verbose", VerbosityVerbose, VerbosityVerbose, false, true},
+		{"Trace", VerbosityTrace, VerbosityTrace, false, true},
+		{"Above trace is clamped", 7, VerbosityTrace, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Start from the opposite state to make sure every field is overwritten
+			cfg := &OutputConfig{UseColors: true, QuietMode: !tt.wantQuiet, VerboseMode: !tt.wantVerbose}
+			ApplyVerbosity(cfg, tt.verbosity)
+
+			if cfg.Verbosity != tt.wantVerbosity {
+				t.Errorf("Verbosity = %d, want %d", cfg.Verbosity, tt.wantVerbosity)
+			}
+			if cfg.QuietMode != tt.wantQuiet {
+				t.Errorf("QuietMode = %v, want %v", cfg.QuietMode, tt.wantQuiet)
+			}
+			if cfg.VerboseMode != tt.wantVerbose {
+				t.Errorf("VerboseMode = %v, want %v", cfg.VerboseMode, tt.wantVerbose)
+			}
+			if !cfg.UseColors {
+				t.Error("ApplyVerbosity() should not touch unrelated fields")
+			}
+		})
+	}
+}
+
+func TestVerbosityFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{"No flags", []string{}, VerbosityDefault},
+		{"Single -v", []string{"-v"}, VerbosityVerbose},
+		{"Repeated -v", []string{"-v", "-v"}, VerbosityTrace},
+		{"Explicit level", []string{"-v=2"}, VerbosityTrace},
+		{"Quiet", []string{"-q"}, VerbosityQuiet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verbosity VerbosityFlag
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			fs.Var(&verbosity, "v", "increase verbosity")
+			fs.Var(verbosity.Quiet(), "q", "only print warnings and errors")
+
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			cfg := &OutputConfig{}
+			verbosity.Apply(cfg)
+			if cfg.Verbosity != tt.expected {
+				t.Errorf("Verbosity = %d, want %d", cfg.Verbosity, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVerbosityFlagInvalidValue(t *testing.T) {
+	var verbosity VerbosityFlag
+	if err := verbosity.Set("loud"); err == nil {
+		t.Error("Expected error for non-numeric verbosity, got nil")
+	}
+}
+
+func TestQuietModeOutput(t *testing.T) {
+	setupSupportedTerminal(t)
+
+	cfg := &OutputConfig{UseColors: false}
+	ApplyVerbosity(cfg, VerbosityQuiet)
+	handler := NewOutputHandler(cfg)
+
+	output := captureOutput(func() {
+		handler.PrintHeader("Header")
+		handler.PrintInfo("Info")
+		handler.PrintStage("Stage")
+		handler.PrintSuccess("Success")
+		handler.PrintAlreadyAvailable("Available")
+		handler.PrintProgress(1, 2, "Progress")
+		handler.PrintWarning("Warning")
+		handler.PrintError("Error")
+	})
+
+	expected := "[WARNING] Warning\n[ERROR] Error\n"
+	if output != expected {
+		t.Errorf("Quiet output = %q, want %q", output, expected)
+	}
+}
@@ -0,0 +1,198 @@
+package palantir
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseXMLToTree converts an XML document to a TreeNode structure built from YAMLNodes, so
+// XML trees share the YAML styling. Elements become object nodes named by their tag,
+// attributes become "@name" leaves and elements holding only text become scalar leaves.
+// Text mixed with child elements is concatenated into a "#text" leaf. Namespaced names
+// keep their prefix, such as "xsi:schemaLocation".
+func ParseXMLToTree(xmlContent []byte, opts ...BuildOption) (*TreeNode, error) {
+	base, err := newYAMLTreeBuilder(newBuildOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	builder := &xmlTreeBuilder{yamlTreeBuilder: base, decoder: xml.NewDecoder(bytes.NewReader(xmlContent))}
+
+	root := &TreeNode{
+		Name:     "root",
+		Data:     YAMLNode{Name: "root", IsDir: true, NodeType: "object"},
+		Children: nil,
+	}
+
+	value := make(map[string]interface{})
+	for {
+		token, err := builder.decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(root.Children) > 0 {
+				return nil, errors.New("failed to parse XML: multiple root elements")
+			}
+			element, elementValue, err := builder.build(t.Copy(), 0, false)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse XML: %w", err)
+			}
+			root.Children = append(root.Children, element)
+			value[element.Name] = elementValue
+		case xml.EndElement:
+			return nil, fmt.Errorf("failed to parse XML: unexpected end element </%s>", xmlName(t.Name))
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return nil, errors.New("failed to parse XML: text outside the root element")
+			}
+		}
+	}
+
+	if len(root.Children) == 0 {
+		return nil, errors.New("failed to parse XML: no root element")
+	}
+	setYAMLContainerValue(root, value)
+	return root, nil
+}
+
+// xmlTreeBuilder builds TreeNodes from a stream of XML tokens
+type xmlTreeBuilder struct {
+	*yamlTreeBuilder // Shares options, the depth limit and redaction with YAML trees
+	decoder          *xml.Decoder
+}
+
+// build consumes the content of an element up to its end tag and returns its node and
+// plain Go value. Repeated child elements collect into a []interface{} value.
+func (b *xmlTreeBuilder) build(start xml.StartElement, depth int, redacted bool) (*TreeNode, interface{}, error) {
+	if depth > b.maxDepth {
+		return nil, nil, fmt.Errorf("XML nesting exceeds maximum depth of %d", b.maxDepth)
+	}
+
+	name := xmlName(start.Name)
+	redacted = b.isRedacted(start.Name.Local, redacted)
+	node := &TreeNode{Name: name, Children: nil}
+	value := make(map[string]interface{})
+
+	// Handle attributes
+	for _, attr := range start.Attr {
+		attrName := "@" + xmlName(attr.Name)
+		attrRedacted := b.isRedacted(attr.Name.Local, redacted)
+		node.Children = append(node.Children, &TreeNode{
+			Name:     attrName,
+			Data:     YAMLNode{Name: attrName, Value: attr.Value, IsDir: false, NodeType: "scalar", ScalarKind: "string", Redacted: attrRedacted},
+			Children: nil,
+		})
+		addXMLValue(value, attrName, attr.Value)
+	}
+
+	// Handle child elements and text until the matching end tag
+	var text strings.Builder
+	for done := false; !done; {
+		token, err := b.decoder.RawToken()
+		if err == io.EOF {
+			return nil, nil, fmt.Errorf("unexpected end of input inside <%s>", name)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, childValue, err := b.build(t.Copy(), depth+1, redacted)
+			if err != nil {
+				return nil, nil, err
+			}
+			node.Children = append(node.Children, child)
+			addXMLValue(value, child.Name, childValue)
+		case xml.EndElement:
+			if endName := xmlName(t.Name); endName != name {
+				return nil, nil, fmt.Errorf("element <%s> closed by </%s>", name, endName)
+			}
+			done = true
+		case xml.CharData:
+			text.Write(t) // Includes CDATA sections
+		}
+	}
+
+	content := strings.TrimSpace(text.String())
+
+	// Elements holding only text are leaves
+	if len(node.Children) == 0 {
+		node.Data = YAMLNode{Name: name, Value: content, IsDir: false, NodeType: "scalar", ScalarKind: "string", Redacted: redacted}
+		return node, content, nil
+	}
+
+	if content != "" {
+		node.Children = append(node.Children, &TreeNode{
+			Name:     "#text",
+			Data:     YAMLNode{Name: "#text", Value: content, IsDir: false, NodeType: "scalar", ScalarKind: "string", Redacted: redacted},
+			Children: nil,
+		})
+		value["#text"] = content
+	}
+	node.Data = YAMLNode{Name: name, Value: value, IsDir: true, NodeType: "object", Redacted: redacted}
+	return node, value, nil
+}
+
+// xmlName formats an element or attribute name with its namespace prefix, if any
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// addXMLValue stores a member value, collecting repeated names into a slice
+func addXMLValue(value map[string]interface{}, name string, member interface{}) {
+	existing, found := value[name]
+	if !found {
+		value[name] = member
+		return
+	}
+	if items, ok := existing.([]interface{}); ok {
+		value[name] = append(items, member)
+		return
+	}
+	value[name] = []interface{}{existing, member}
+}
+
+// ShowXMLHierarchy displays an XML document as a tree structure. Elements keep their
+// document order unless WithKeyOrder(KeyOrderAlphabetical) is given.
+func ShowXMLHierarchy(xmlContent []byte, opts ...BuildOption) error {
+	root, err := ParseXMLToTree(xmlContent, opts...)
+	if err != nil {
+		return err
+	}
+
+	options := newBuildOptions(opts)
+	if options.KeyOrder == KeyOrderAlphabetical {
+		sortTree(root)
+	}
+	renderLimitedTree(root, options)
+	return nil
+}
+
+// ShowXMLHierarchyFromFile reads and displays an XML file as a tree structure, up to the WithMaxReadSize limit.
+// A path of "-" reads standard input.
+func ShowXMLHierarchyFromFile(filePath string, opts ...BuildOption) error {
+	file, err := openInput(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read XML file: %w", err)
+	}
+	defer file.Close()
+
+	content, err := readLimited(file, newBuildOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to read XML: %w", err)
+	}
+	return ShowXMLHierarchy(content, opts...)
+}
@@ -0,0 +1,113 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+var pomXML = []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!-- Maven project descriptor -->
+<project xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+  <groupId>com.example</groupId>
+  <artifactId>demo</artifactId>
+  <dependencies>
+    <dependency scope="test">
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+    </dependency>
+  </dependencies>
+  <description><![CDATA[Uses <angle> brackets]]></description>
+  <note>Built <em>quickly</em> with care</note>
+  <empty/>
+</project>
+`)
+
+func TestParseXMLToTree(t *testing.T) {
+	root, err := ParseXMLToTree(pomXML)
+	if err != nil {
+		t.Fatalf("ParseXMLToTree() error = %v", err)
+	}
+
+	project := findChild(root, "project")
+	if project == nil {
+		t.Fatalf("Expected the document element, got %v", childNames(root))
+	}
+
+	expected := "@xmlns:xsi,@xsi:schemaLocation,modelVersion,groupId,artifactId,dependencies,description,note,empty"
+	if names := childNames(project); strings.Join(names, ",") != expected {
+		t.Errorf("Expected elements and attributes in document order, got %v", names)
+	}
+
+	dependencies := findChild(project, "dependencies")
+	if names := childNames(dependencies); strings.Join(names, ",") != "dependency,dependency" {
+		t.Errorf("Expected repeated elements as siblings, got %v", names)
+	}
+	if items, ok := dependencies.Data.(YAMLNode).Value.(map[string]interface{})["dependency"].([]interface{}); !ok || len(items) != 2 {
+		t.Errorf("Expected repeated elements to collect into a slice, got %+v", dependencies.Data)
+	}
+
+	scope := findChild(dependencies.Children[0], "@scope")
+	if scope == nil || scope.Data.(YAMLNode).Value != "test" {
+		t.Errorf("Expected an @scope attribute leaf, got %+v", scope)
+	}
+
+	tests := []struct {
+		element  string
+		expected interface{}
+	}{
+		{"modelVersion", "4.0.0"},
+		{"description", "Uses <angle> brackets"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		data := findChild(project, tt.element).Data.(YAMLNode)
+		if data.NodeType != "scalar" || data.Value != tt.expected {
+			t.Errorf("Expected <%s> to be a scalar %q, got %+v", tt.element, tt.expected, data)
+		}
+	}
+
+	note := findChild(project, "note")
+	if text := findChild(note, "#text"); text == nil || text.Data.(YAMLNode).Value != "Built  with care" {
+		t.Errorf("Expected mixed content text to be concatenated, got %+v", text)
+	}
+}
+
+func TestShowXMLHierarchyValues(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowXMLHierarchy(pomXML, WithShowValues()); err != nil {
+			t.Errorf("ShowXMLHierarchy() error = %v", err)
+		}
+	})
+
+	assertGolden(t, "xml_values", output)
+}
+
+func TestParseXMLToTreeMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"Mismatched tags", `<a><b></a></b>`},
+		{"Unclosed element", `<a><b></b>`},
+		{"Multiple roots", `<a/><b/>`},
+		{"No root element", `<?xml version="1.0"?>`},
+		{"Invalid syntax", `<a attr=></a>`},
+		{"Text outside root", `<a/>trailing`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseXMLToTree([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), "failed to parse XML") {
+				t.Errorf("Expected a parse error, got %v", err)
+			}
+		})
+	}
+}
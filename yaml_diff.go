@@ -0,0 +1,295 @@
+package palantir
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DiffStatus describes how a node changed between two documents
+type DiffStatus rune
+
+// Diff markers shown before the names of nodes that differ
+const (
+	DiffUnchanged DiffStatus = 0
+	DiffAdded     DiffStatus = '+'
+	DiffRemoved   DiffStatus = '-'
+	DiffChanged   DiffStatus = '~'
+)
+
+// diffStatusColors maps each diff status to the color used for its node
+var diffStatusColors = map[DiffStatus]string{
+	DiffAdded:   ColorGreen,
+	DiffRemoved: ColorRed,
+	DiffChanged: ColorYellow,
+}
+
+// DiffNode represents a node of the union of two documents for tree visualization.
+// Scalars keep the value from each side, which is nil on the side missing the node.
+type DiffNode struct {
+	Name     string
+	IsDir    bool
+	Status   DiffStatus
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// DiffYAMLTrees compares two YAML documents and returns the union of their structure
+// built from DiffNodes. Added, removed and changed nodes are marked, and containers
+// holding changes are marked as changed. Arrays are compared item by item unless
+// WithUnorderedArrays is given, and WithChangedOnly drops unchanged nodes.
+func DiffYAMLTrees(a, b []byte, opts ...BuildOption) (*TreeNode, error) {
+	oldRoot, err := ParseYAMLToTree(a, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old YAML: %w", err)
+	}
+	newRoot, err := ParseYAMLToTree(b, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new YAML: %w", err)
+	}
+
+	options := newBuildOptions(opts)
+	root := &TreeNode{Name: "root", Data: DiffNode{Name: "root", IsDir: true, Status: DiffChanged}}
+	if nodes := diffTreeNodes("root", oldRoot, newRoot, options); len(nodes) == 1 {
+		root = nodes[0]
+	} else {
+		root.Children = nodes
+	}
+	if options.ChangedOnly {
+		pruneUnchanged(root)
+	}
+	return root, nil
+}
+
+// ShowYAMLDiff displays the differences between two YAML documents as a tree
+func ShowYAMLDiff(a, b []byte, opts ...BuildOption) error {
+	root, err := DiffYAMLTrees(a, b, opts...)
+	if err != nil {
+		return err
+	}
+
+	options := newBuildOptions(opts)
+	if options.ChangedOnly && len(root.Children) == 0 {
+		GetGlobalOutputHandler().PrintInfo("No differences")
+		return nil
+	}
+	renderLimitedTree(root, options)
+	return nil
+}
+
+// diffTreeNodes compares two YAML tree nodes found at the same place in both documents.
+// Nodes whose kind changed, such as a scalar replaced by a mapping, cannot be aligned and
+// are returned as the removed old node followed by the added new one.
+func diffTreeNodes(name string, oldNode, newNode *TreeNode, options BuildOptions) []*TreeNode {
+	kind := yamlDiffKind(oldNode)
+	if kind != yamlDiffKind(newNode) {
+		return []*TreeNode{markDiffTree(name, oldNode, DiffRemoved), markDiffTree(name, newNode, DiffAdded)}
+	}
+
+	node := &TreeNode{Name: name, Data: DiffNode{Name: name, IsDir: true}}
+	switch kind {
+	case "mapping":
+		node.Children = diffMappingChildren(oldNode, newNode, options)
+	case "sequence":
+		if options.UnorderedArrays {
+			node.Children = diffSequenceSets(oldNode, newNode)
+		} else {
+			node.Children = diffSequenceItems(oldNode, newNode, options)
+		}
+	default:
+		oldValue, newValue := oldNode.Data.(YAMLNode).Value, newNode.Data.(YAMLNode).Value
+		status := DiffUnchanged
+		if !reflect.DeepEqual(oldValue, newValue) {
+			status = DiffChanged
+		}
+		return []*TreeNode{newDiffLeaf(name, status, oldValue, newValue)}
+	}
+
+	for _, child := range node.Children {
+		if child.Data.(DiffNode).Status != DiffUnchanged {
+			node.Data = DiffNode{Name: name, IsDir: true, Status: DiffChanged}
+			break
+		}
+	}
+	return []*TreeNode{node}
+}
+
+// diffMappingChildren compares the keys of two mappings. Keys keep the order of the old
+// document, followed by keys only found in the new one.
+func diffMappingChildren(oldNode, newNode *TreeNode, options BuildOptions) []*TreeNode {
+	newChildren := make(map[string]*TreeNode, len(newNode.Children))
+	for _, child := range newNode.Children {
+		newChildren[child.Name] = child
+	}
+
+	var children []*TreeNode
+	oldNames := make(map[string]bool, len(oldNode.Children))
+	for _, oldChild := range oldNode.Children {
+		oldNames[oldChild.Name] = true
+		if newChild, ok := newChildren[oldChild.Name]; ok {
+			children = append(children, diffTreeNodes(oldChild.Name, oldChild, newChild, options)...)
+		} else {
+			children = append(children, markDiffTree(oldChild.Name, oldChild, DiffRemoved))
+		}
+	}
+	for _, newChild := range newNode.Children {
+		if !oldNames[newChild.Name] {
+			children = append(children, markDiffTree(newChild.Name, newChild, DiffAdded))
+		}
+	}
+	return children
+}
+
+// diffSequenceItems compares two sequences position by position. Items are named by
+// their index unless both sides have the same name, such as an unchanged scalar.
+func diffSequenceItems(oldNode, newNode *TreeNode, options BuildOptions) []*TreeNode {
+	var children []*TreeNode
+	for i := 0; i < len(oldNode.Children) || i < len(newNode.Children); i++ {
+		switch {
+		case i >= len(newNode.Children):
+			children = append(children, markDiffTree(oldNode.Children[i].Name, oldNode.Children[i], DiffRemoved))
+		case i >= len(oldNode.Children):
+			children = append(children, markDiffTree(newNode.Children[i].Name, newNode.Children[i], DiffAdded))
+		default:
+			oldItem, newItem := oldNode.Children[i], newNode.Children[i]
+			name := oldItem.Name
+			if name != newItem.Name {
+				name = fmt.Sprintf("[%d]", i)
+			}
+			children = append(children, diffTreeNodes(name, oldItem, newItem, options)...)
+		}
+	}
+	return children
+}
+
+// diffSequenceSets compares two sequences as multisets of values, ignoring their order.
+// Items of the old sequence come first, followed by items only found in the new one.
+func diffSequenceSets(oldNode, newNode *TreeNode) []*TreeNode {
+	oldCounts := make(map[string]int, len(oldNode.Children))
+	for _, item := range oldNode.Children {
+		oldCounts[fmt.Sprint(yamlDiffValue(item))]++
+	}
+	newCounts := make(map[string]int, len(newNode.Children))
+	for _, item := range newNode.Children {
+		newCounts[fmt.Sprint(yamlDiffValue(item))]++
+	}
+
+	var children []*TreeNode
+	for _, item := range oldNode.Children {
+		key := fmt.Sprint(yamlDiffValue(item))
+		status := DiffRemoved
+		if newCounts[key] > 0 {
+			newCounts[key]--
+			status = DiffUnchanged
+		}
+		children = append(children, markDiffTree(item.Name, item, status))
+	}
+	for _, item := range newNode.Children {
+		key := fmt.Sprint(yamlDiffValue(item))
+		if oldCounts[key] > 0 {
+			oldCounts[key]--
+			continue
+		}
+		children = append(children, markDiffTree(item.Name, item, DiffAdded))
+	}
+	return children
+}
+
+// markDiffTree copies a YAML subtree that only exists on one side, or is identical on
+// both, giving every node the same status
+func markDiffTree(name string, node *TreeNode, status DiffStatus) *TreeNode {
+	if yamlDiffKind(node) == "scalar" {
+		value := node.Data.(YAMLNode).Value
+		switch status {
+		case DiffAdded:
+			return newDiffLeaf(name, status, nil, value)
+		case DiffRemoved:
+			return newDiffLeaf(name, status, value, nil)
+		default:
+			return newDiffLeaf(name, status, value, value)
+		}
+	}
+
+	marked := &TreeNode{Name: name, Data: DiffNode{Name: name, IsDir: true, Status: status}}
+	for _, child := range node.Children {
+		marked.Children = append(marked.Children, markDiffTree(child.Name, child, status))
+	}
+	return marked
+}
+
+// newDiffLeaf creates a scalar node of a diff tree
+func newDiffLeaf(name string, status DiffStatus, oldValue, newValue interface{}) *TreeNode {
+	return &TreeNode{
+		Name:     name,
+		Data:     DiffNode{Name: name, IsDir: false, Status: status, OldValue: oldValue, NewValue: newValue},
+		Children: nil,
+	}
+}
+
+// yamlDiffKind classifies a YAML tree node as a "mapping", "sequence" or "scalar".
+// Aliases are compared by the value they reference.
+func yamlDiffKind(node *TreeNode) string {
+	yamlData := node.Data.(YAMLNode)
+	if yamlData.NodeType == "alias" {
+		return "scalar"
+	}
+	switch yamlData.Value.(type) {
+	case []interface{}:
+		return "sequence"
+	case map[string]interface{}:
+		return "mapping"
+	}
+	if yamlData.IsDir {
+		return "mapping" // Empty documents and mappings without entries
+	}
+	return "scalar"
+}
+
+// yamlDiffValue returns the plain Go value of a YAML tree node
+func yamlDiffValue(node *TreeNode) interface{} {
+	return node.Data.(YAMLNode).Value
+}
+
+// pruneUnchanged removes unchanged nodes from a diff tree
+func pruneUnchanged(node *TreeNode) {
+	var kept []*TreeNode
+	for _, child := range node.Children {
+		if child.Data.(DiffNode).Status == DiffUnchanged {
+			continue
+		}
+		pruneUnchanged(child)
+		kept = append(kept, child)
+	}
+	node.Children = kept
+}
+
+// styleDiffNode renders a diff node with its marker and values, colored by its status.
+// Changed scalars show "old → new", and array items named after their value show it once.
+func styleDiffNode(diffNode DiffNode, options BuildOptions) string {
+	text := diffNode.Name
+	if !diffNode.IsDir {
+		value := diffNode.OldValue
+		if diffNode.Status == DiffAdded {
+			value = diffNode.NewValue
+		}
+
+		switch formatted := formatYAMLValue(value, options.MaxValueLength); {
+		case diffNode.Status == DiffChanged:
+			text += ": " + formatted + " → " + formatYAMLValue(diffNode.NewValue, options.MaxValueLength)
+		case formatted != diffNode.Name:
+			text += ": " + formatted
+		}
+	}
+	if diffNode.Status == DiffUnchanged {
+		return text
+	}
+
+	sign := string(diffNode.Status)
+	outputConfig := treeOutputConfig()
+	if !outputConfig.UseColors {
+		return sign + " " + text
+	}
+	if outputConfig.ColorizeLevelOnly {
+		return fmt.Sprintf("%s%s%s %s", diffStatusColors[diffNode.Status], sign, ColorReset, text)
+	}
+	return fmt.Sprintf("%s%s %s%s", diffStatusColors[diffNode.Status], sign, text, ColorReset)
+}
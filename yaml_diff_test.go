@@ -0,0 +1,161 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+var stagingYAML = []byte(`
+app: palantir
+replicas: 2
+database:
+  host: staging.db
+  port: 5432
+  pool: 10
+tags:
+  - web
+  - api
+`)
+
+var productionYAML = []byte(`
+app: palantir
+replicas: 5
+database:
+  host: prod.db
+  port: 5432
+tags:
+  - api
+  - web
+  - public
+region: eu-west-1
+`)
+
+func TestShowYAMLDiff(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLDiff(stagingYAML, productionYAML); err != nil {
+			t.Errorf("ShowYAMLDiff() error = %v", err)
+		}
+	})
+
+	expected := "├── app: palantir\n" +
+		"├── ~ replicas: 2 → 5\n" +
+		"├── ~ database\n" +
+		"│   ├── ~ host: staging.db → prod.db\n" +
+		"│   ├── port: 5432\n" +
+		"│   └── - pool: 10\n" +
+		"├── ~ tags\n" +
+		"│   ├── ~ [0]: web → api\n" +
+		"│   ├── ~ [1]: api → web\n" +
+		"│   └── + public\n" +
+		"└── + region: eu-west-1\n"
+	if output != expected {
+		t.Errorf("ShowYAMLDiff() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowYAMLDiffChangedOnly(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowYAMLDiff(stagingYAML, productionYAML, WithChangedOnly(), WithUnorderedArrays())
+	})
+
+	expected := "├── ~ replicas: 2 → 5\n" +
+		"├── ~ database\n" +
+		"│   ├── ~ host: staging.db → prod.db\n" +
+		"│   └── - pool: 10\n" +
+		"├── ~ tags\n" +
+		"│   └── + public\n" +
+		"└── + region: eu-west-1\n"
+	if output != expected {
+		t.Errorf("ShowYAMLDiff() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowYAMLDiffNoDifferences(t *testing.T) {
+	setupSupportedTerminal(t)
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false, UseEmojis: false}))
+
+	output := captureOutput(func() {
+		ShowYAMLDiff(stagingYAML, stagingYAML, WithChangedOnly())
+	})
+
+	if !strings.Contains(output, "No differences") || strings.Contains(output, "database") {
+		t.Errorf("Expected only a note about no differences, got %q", output)
+	}
+}
+
+func TestDiffYAMLTreesNested(t *testing.T) {
+	a := []byte("services:\n  - name: web\n    ports: [80]\n  - name: worker\n")
+	b := []byte("services:\n  - name: web\n    ports: [80, 443]\n")
+
+	root, err := DiffYAMLTrees(a, b)
+	if err != nil {
+		t.Fatalf("DiffYAMLTrees() error = %v", err)
+	}
+
+	services := findChild(root, "services")
+	if status := services.Data.(DiffNode).Status; status != DiffChanged {
+		t.Errorf("Expected services to be changed, got %q", status)
+	}
+	if len(services.Children) != 2 {
+		t.Fatalf("Expected two service items, got %v", childNames(services))
+	}
+
+	ports := findChild(services.Children[0], "ports")
+	if names := childNames(ports); strings.Join(names, ",") != "80,443" {
+		t.Errorf("Expected both ports, got %v", names)
+	}
+	if status := ports.Children[1].Data.(DiffNode).Status; status != DiffAdded {
+		t.Errorf("Expected the new port to be added, got %q", status)
+	}
+
+	removed := services.Children[1]
+	if data := removed.Data.(DiffNode); data.Status != DiffRemoved || findChild(removed, "name").Data.(DiffNode).Status != DiffRemoved {
+		t.Errorf("Expected the worker service and its keys to be removed, got %+v", data)
+	}
+}
+
+func TestDiffYAMLTreesKindChange(t *testing.T) {
+	root, err := DiffYAMLTrees([]byte("cache: redis\n"), []byte("cache:\n  driver: redis\n"))
+	if err != nil {
+		t.Fatalf("DiffYAMLTrees() error = %v", err)
+	}
+
+	if len(root.Children) != 2 {
+		t.Fatalf("Expected the old and new cache nodes, got %v", childNames(root))
+	}
+	if status := root.Children[0].Data.(DiffNode).Status; status != DiffRemoved {
+		t.Errorf("Expected the scalar to be removed, got %q", status)
+	}
+	if added := root.Children[1]; added.Data.(DiffNode).Status != DiffAdded || findChild(added, "driver") == nil {
+		t.Errorf("Expected the mapping to be added with its keys, got %v", childNames(added))
+	}
+}
+
+func TestStyleDiffNodeColors(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	styled := styleDiffNode(DiffNode{Name: "port", Status: DiffAdded, NewValue: 8080}, BuildOptions{})
+	if expected := ColorGreen + "+ port: 8080" + ColorReset; styled != expected {
+		t.Errorf("styleDiffNode() = %q, want %q", styled, expected)
+	}
+}
+
+func TestStyleDiffNodeColorizeLevelOnly(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true, ColorizeLevelOnly: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	// Only the sign is colored, like the level of a message
+	styled := styleDiffNode(DiffNode{Name: "port", Status: DiffRemoved, OldValue: 8080}, BuildOptions{})
+	if expected := ColorRed + "-" + ColorReset + " port: 8080"; styled != expected {
+		t.Errorf("styleDiffNode() = %q, want %q", styled, expected)
+	}
+}
@@ -0,0 +1,64 @@
+package palantir
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ShowYAMLHierarchyFiltered displays only the parts of YAML content whose dotted key
+// path, such as "server.port", matches keyGlob. Each dot-separated segment of the glob
+// is matched against one key, so "server.*" shows everything under server. Matching
+// nodes are shown with their ancestors and descendants, and a glob matching nothing
+// prints a warning instead of a tree.
+func ShowYAMLHierarchyFiltered(content []byte, keyGlob string, opts ...BuildOption) error {
+	if _, err := path.Match(keyGlob, ""); err != nil {
+		return fmt.Errorf("invalid key pattern %q: %w", keyGlob, err)
+	}
+
+	root, err := ParseYAMLToTree(content, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if !filterTree(root, nil, strings.Split(keyGlob, ".")) {
+		GetGlobalOutputHandler().PrintWarning("No keys match %q", keyGlob)
+		return nil
+	}
+
+	options := newBuildOptions(opts)
+	if options.KeyOrder == KeyOrderAlphabetical {
+		sortTree(root)
+	}
+	renderLimitedTree(root, options)
+	return nil
+}
+
+// filterTree drops the children of node that neither match the glob segments nor lead
+// to a match, reporting whether anything below node was kept. Matching nodes keep all
+// of their descendants.
+func filterTree(node *TreeNode, keyPath []string, glob []string) bool {
+	var kept []*TreeNode
+	for _, child := range node.Children {
+		childPath := append(keyPath[:len(keyPath):len(keyPath)], child.Name)
+		if matchKeyPath(glob, childPath) || filterTree(child, childPath, glob) {
+			kept = append(kept, child)
+		}
+	}
+	node.Children = kept
+	return len(kept) > 0
+}
+
+// matchKeyPath reports whether each key of a dotted path matches the glob segment at
+// the same position
+func matchKeyPath(glob []string, keyPath []string) bool {
+	if len(glob) != len(keyPath) {
+		return false
+	}
+	for i, segment := range glob {
+		if matched, _ := path.Match(segment, keyPath[i]); !matched {
+			return false
+		}
+	}
+	return true
+}
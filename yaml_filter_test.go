@@ -0,0 +1,73 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+var filterYAML = []byte(`
+database:
+  host: localhost
+  port: 5432
+  credentials:
+    username: admin
+server:
+  host: 0.0.0.0
+  port: 8080
+`)
+
+func TestShowYAMLHierarchyFilteredSubtree(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchyFiltered(filterYAML, "database"); err != nil {
+			t.Errorf("ShowYAMLHierarchyFiltered() error = %v", err)
+		}
+	})
+
+	expected := "└── database\n" +
+		"    ├── host\n" +
+		"    ├── port\n" +
+		"    └── credentials\n" +
+		"        └── username\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchyFiltered() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowYAMLHierarchyFilteredNestedGlob(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowYAMLHierarchyFiltered(filterYAML, "*.port", WithShowValues())
+	})
+
+	expected := "├── database\n" +
+		"│   └── port: 5432\n" +
+		"└── server\n" +
+		"    └── port: 8080\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchyFiltered() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowYAMLHierarchyFilteredNoMatch(t *testing.T) {
+	setupSupportedTerminal(t)
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false, UseEmojis: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchyFiltered(filterYAML, "cache.*"); err != nil {
+			t.Errorf("ShowYAMLHierarchyFiltered() error = %v", err)
+		}
+	})
+
+	if !strings.Contains(output, `No keys match "cache.*"`) || strings.Contains(output, "database") {
+		t.Errorf("Expected only a warning, got %q", output)
+	}
+}
+
+func TestShowYAMLHierarchyFilteredInvalidGlob(t *testing.T) {
+	if err := ShowYAMLHierarchyFiltered(filterYAML, "[database"); err == nil {
+		t.Error("Expected an error for a malformed glob")
+	}
+}
@@ -0,0 +1,167 @@
+package palantir
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a path expression such as "jobs[*].steps"
+type pathSegment struct {
+	key       string // Glob matched against mapping keys, unless index or recursive is set
+	index     int    // Array index to match, or -1 for any index
+	isIndex   bool
+	recursive bool // "**" matches any number of levels, including none
+}
+
+// parsePathExpression splits a path expression into segments. Keys are separated by
+// dots and may be globs such as "*", array items are selected with "[n]" or "[*]",
+// and "**" descends through any number of levels.
+func parsePathExpression(expr string) ([]pathSegment, error) {
+	if expr == "" {
+		return nil, fmt.Errorf("empty path expression")
+	}
+
+	var segments []pathSegment
+	for _, part := range strings.Split(expr, ".") {
+		key, brackets, _ := strings.Cut(part, "[")
+		if brackets != "" || strings.Contains(part, "[") {
+			brackets = "[" + brackets
+		}
+
+		switch {
+		case key == "**":
+			segments = append(segments, pathSegment{recursive: true})
+		case key != "":
+			if _, err := path.Match(key, ""); err != nil {
+				return nil, fmt.Errorf("invalid key %q in path expression %q: %w", key, expr, err)
+			}
+			segments = append(segments, pathSegment{key: key})
+		case brackets == "":
+			return nil, fmt.Errorf("empty key in path expression %q", expr)
+		}
+
+		for brackets != "" {
+			end := strings.Index(brackets, "]")
+			if !strings.HasPrefix(brackets, "[") || end < 0 {
+				return nil, fmt.Errorf("malformed array index in path expression %q", expr)
+			}
+
+			segment := pathSegment{index: -1, isIndex: true}
+			if inner := brackets[1:end]; inner != "*" {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid array index %q in path expression %q", inner, expr)
+				}
+				segment.index = index
+			}
+			segments = append(segments, segment)
+			brackets = brackets[end+1:]
+		}
+	}
+	return segments, nil
+}
+
+// FindYAMLPaths returns the nodes of a parsed tree matching a path expression such as
+// "database.credentials", "jobs[*].steps" or "**.image", in document order. Keys may be
+// globs, "[n]" and "[*]" select array items, and "**" matches any number of levels.
+// An invalid expression matches nothing.
+func FindYAMLPaths(root *TreeNode, expr string) []*TreeNode {
+	segments, err := parsePathExpression(expr)
+	if err != nil {
+		return nil
+	}
+
+	var matches []*TreeNode
+	seen := make(map[*TreeNode]bool)
+	var match func(node *TreeNode, segments []pathSegment)
+	match = func(node *TreeNode, segments []pathSegment) {
+		if len(segments) == 0 {
+			if !seen[node] {
+				seen[node] = true
+				matches = append(matches, node)
+			}
+			return
+		}
+
+		segment := segments[0]
+		if segment.recursive {
+			match(node, segments[1:])
+			for _, child := range node.Children {
+				match(child, segments)
+			}
+			return
+		}
+		for _, child := range node.Children {
+			if segment.matches(child) {
+				match(child, segments[1:])
+			}
+		}
+	}
+	match(root, segments)
+	return sortByTreeOrder(root, matches, seen)
+}
+
+// matches reports whether a child node is selected by the segment
+func (s pathSegment) matches(node *TreeNode) bool {
+	yamlNode, isYAML := node.Data.(YAMLNode)
+	isItem := isYAML && yamlNode.Item
+	if s.isIndex {
+		return isItem && (s.index < 0 || s.index == yamlNode.Index)
+	}
+	if isItem {
+		return false
+	}
+	matched, _ := path.Match(s.key, node.Name)
+	return matched
+}
+
+// sortByTreeOrder returns the matched nodes in the order a depth-first walk of the tree visits them
+func sortByTreeOrder(root *TreeNode, matches []*TreeNode, matched map[*TreeNode]bool) []*TreeNode {
+	if len(matches) < 2 {
+		return matches
+	}
+
+	ordered := make([]*TreeNode, 0, len(matches))
+	var visit func(node *TreeNode)
+	visit = func(node *TreeNode) {
+		if matched[node] {
+			ordered = append(ordered, node)
+		}
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	visit(root)
+	return ordered
+}
+
+// filterTreeByPath prunes a tree to the nodes matching a path expression, keeping
+// their ancestors and everything below them. It reports whether anything matched.
+func filterTreeByPath(root *TreeNode, expr string) (bool, error) {
+	if _, err := parsePathExpression(expr); err != nil {
+		return false, err
+	}
+
+	matched := make(map[*TreeNode]bool)
+	for _, node := range FindYAMLPaths(root, expr) {
+		matched[node] = true
+	}
+	if matched[root] {
+		return true, nil
+	}
+
+	var keep func(node *TreeNode) bool
+	keep = func(node *TreeNode) bool {
+		var kept []*TreeNode
+		for _, child := range node.Children {
+			if matched[child] || keep(child) {
+				kept = append(kept, child)
+			}
+		}
+		node.Children = kept
+		return len(kept) > 0
+	}
+	return keep(root), nil
+}
@@ -0,0 +1,107 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+var pathsYAML = []byte(`
+database:
+  host: localhost
+  credentials:
+    username: admin
+    password: secret
+jobs:
+  - name: build
+    image: golang
+    steps: [checkout, compile]
+  - name: test
+    steps: [checkout, test]
+services:
+  cache:
+    image: redis
+`)
+
+func TestFindYAMLPaths(t *testing.T) {
+	root, err := ParseYAMLToTree(pathsYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected []string
+	}{
+		{"Exact path", "database.credentials", []string{"credentials"}},
+		{"Single-level wildcard", "database.*", []string{"host", "credentials"}},
+		{"Recursive wildcard", "**.image", []string{"image", "image"}},
+		{"Any array item", "jobs[*].steps", []string{"steps", "steps"}},
+		{"Array index", "jobs[1].name", []string{"name"}},
+		{"Nested array index", "jobs[0].steps[1]", []string{"compile"}},
+		{"No match", "cache.*", nil},
+		{"Invalid expression", "jobs[x]", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, node := range FindYAMLPaths(root, tt.expr) {
+				names = append(names, node.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("FindYAMLPaths(%q) = %v, want %v", tt.expr, names, tt.expected)
+			}
+		})
+	}
+
+	// Matches keep document order
+	images := FindYAMLPaths(root, "**.image")
+	if len(images) != 2 || images[0].Data.(YAMLNode).Value != "golang" || images[1].Data.(YAMLNode).Value != "redis" {
+		t.Errorf("Expected images in document order, got %v", images)
+	}
+}
+
+func TestShowYAMLHierarchyPathFilter(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(pathsYAML, WithPathFilter("jobs[*].steps")); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "└── jobs\n" +
+		"    ├── [0] (object, 3 keys)\n" +
+		"    │   └── steps\n" +
+		"    │       ├── checkout\n" +
+		"    │       └── compile\n" +
+		"    └── [1] (object, 2 keys)\n" +
+		"        └── steps\n" +
+		"            ├── checkout\n" +
+		"            └── test\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchy() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowJSONHierarchyPathFilterNoMatch(t *testing.T) {
+	setupSupportedTerminal(t)
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false, UseEmojis: false}))
+
+	output := captureOutput(func() {
+		ShowJSONHierarchy([]byte(`{"database": {"host": "localhost"}}`), WithPathFilter("server.port"))
+	})
+
+	if !strings.Contains(output, `No keys match path "server.port"`) || strings.Contains(output, "database") {
+		t.Errorf("Expected only an empty-result warning, got %q", output)
+	}
+}
+
+func TestParsePathExpressionErrors(t *testing.T) {
+	for _, expr := range []string{"", "a..b", "jobs[", "jobs[-1]", "[abc"} {
+		if _, err := parsePathExpression(expr); err == nil {
+			t.Errorf("parsePathExpression(%q) expected an error", expr)
+		}
+	}
+}
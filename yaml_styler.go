@@ -0,0 +1,33 @@
+package palantir
+
+// YAMLStyler displays the nodes of a Tree[YAMLNode] like ShowYAMLHierarchy does,
+// coloring objects, arrays and scalars by type and adding the markers enabled by its
+// options, such as WithShowValues or WithShowTypes
+type YAMLStyler struct {
+	options BuildOptions
+}
+
+// NewYAMLStyler creates a NodeStyler for YAML nodes styled with the given options
+func NewYAMLStyler(opts ...BuildOption) *YAMLStyler {
+	return &YAMLStyler{options: newBuildOptions(opts)}
+}
+
+// StyleNode returns the styled line for node, without its tree connectors
+func (s *YAMLStyler) StyleNode(node *Node[YAMLNode]) string {
+	return styleTreeNode(&TreeNode{Name: node.Name, Data: node.Data}, s.options)
+}
+
+// ParseYAMLToGenericTree parses YAML content like ParseYAMLToTree, returning a Tree
+// holding YAMLNode data that can be rendered with a YAMLStyler or a custom NodeStyler
+func ParseYAMLToGenericTree(yamlContent []byte, opts ...BuildOption) (*Tree[YAMLNode], error) {
+	root, err := ParseYAMLToTree(yamlContent, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	options := newBuildOptions(opts)
+	if options.KeyOrder == KeyOrderAlphabetical {
+		sortTree(root)
+	}
+	return &Tree[YAMLNode]{root: genericNode[YAMLNode](root, nil)}, nil
+}
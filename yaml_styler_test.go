@@ -0,0 +1,62 @@
+package palantir
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestYAMLStylerMatchesShowYAMLHierarchy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []BuildOption
+	}{
+		{"keys", []BuildOption{WithShowRoot()}},
+		{"values", []BuildOption{WithShowValues(), WithShowTypes()}},
+		{"sorted", []BuildOption{WithShowValues(), WithKeyOrder(KeyOrderAlphabetical)}},
+		{"anchors", []BuildOption{WithShowArrayIndices()}},
+	}
+
+	for _, colors := range []bool{false, true} {
+		SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: colors}))
+		for _, tt := range tests {
+			for _, content := range [][]byte{workflowYAML, anchorsYAML} {
+				expected := captureOutput(func() {
+					if err := ShowYAMLHierarchy(content, tt.opts...); err != nil {
+						t.Fatalf("ShowYAMLHierarchy() error = %v", err)
+					}
+				})
+
+				tree, err := ParseYAMLToGenericTree(content, tt.opts...)
+				if err != nil {
+					t.Fatalf("ParseYAMLToGenericTree() error = %v", err)
+				}
+				output := captureOutput(func() {
+					tree.Render(NewYAMLStyler(tt.opts...), tt.opts...)
+				})
+
+				if output != expected {
+					t.Errorf("%s (colors %v): Render() =\n%s\nwant:\n%s", tt.name, colors, output, expected)
+				}
+			}
+		}
+	}
+	SetGlobalOutputHandler(NewDefaultOutputHandler())
+}
+
+func TestYAMLStylerCustomTree(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	tree := NewTree(YAMLNode{Name: "config", NodeType: "object"}, "config")
+	tree.Insert([]string{"server"}, YAMLNode{Name: "server", NodeType: "object", IsDir: true})
+	tree.Insert([]string{"server", "port"}, YAMLNode{Name: "port", NodeType: "scalar", Value: 8080, ScalarKind: "int"})
+
+	output := captureOutput(func() {
+		tree.Render(NewYAMLStyler(WithShowValues(), WithShowTypes()))
+	})
+	if !strings.Contains(output, "port: 8080 (int)") {
+		t.Errorf("Expected the styled scalar, got:\n%s", output)
+	}
+}
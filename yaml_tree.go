@@ -0,0 +1,556 @@
+package palantir
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLNode represents a YAML data node for tree visualization
+type YAMLNode struct {
+	Name       string
+	Value      interface{}
+	IsDir      bool
+	NodeType   string // "object", "array", "scalar", "alias", "cycle"
+	ScalarKind string // Kind of a scalar's value: "string", "int", "float", "bool", "null" or "timestamp"
+	Item       bool   // Set on the items of an array, which are named after their scalar value or index
+	Index      int    // Position of an array item in its array
+	Line       int    // Line of the key or item in the source document, starting at 1, or 0 when unknown
+	Column     int    // Column of the key or item in the source document, starting at 1, or 0 when unknown
+
+	HeadComment string // Comment lines above the key or item, including their "#"
+	LineComment string // Comment at the end of the key's or item's line, including its "#"
+	Anchor      string // Anchor name defined on this node, such as "defaults" for &defaults
+	Alias       string // Anchor name referenced by an alias node
+	Redacted    bool   // Set when the node sits below a key matching BuildOptions.RedactKeys
+	Inherited   bool   // Set on keys merged into their mapping by a "<<" merge key
+}
+
+// ParseYAMLToTree converts YAML content to TreeNode structure, with a root named "root"
+func ParseYAMLToTree(yamlContent []byte, opts ...BuildOption) (*TreeNode, error) {
+	return ParseYAMLToTreeNamed(yamlContent, "root", opts...)
+}
+
+// ParseYAMLToTreeNamed is ParseYAMLToTree with the root named rootName, such as the name of
+// the file the content was read from. The documents of a multi-document stream become the
+// items of the root, numbered from 0, with positions relative to the whole stream.
+func ParseYAMLToTreeNamed(yamlContent []byte, rootName string, opts ...BuildOption) (*TreeNode, error) {
+	documents, err := decodeYAMLDocuments(yamlContent)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &TreeNode{
+		Name:     rootName,
+		Data:     YAMLNode{Name: rootName, IsDir: true, NodeType: "object"},
+		Children: nil,
+	}
+
+	var content *yaml.Node
+	switch len(documents) {
+	case 0:
+		return root, nil
+	case 1:
+		content = documents[0]
+	default:
+		content = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: documents}
+	}
+
+	builder, err := newYAMLTreeBuilder(newBuildOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	value, err := builder.build(root, content, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	setYAMLContainerValue(root, value)
+	return root, nil
+}
+
+// decodeYAMLDocuments returns the content node of every document in a YAML stream, whose
+// positions are relative to the whole stream. Empty documents, such as the one after a
+// trailing "---", are skipped.
+func decodeYAMLDocuments(yamlContent []byte) ([]*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(yamlContent))
+	var documents []*yaml.Node
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if len(document.Content) == 0 {
+			continue
+		}
+		if content := document.Content[0]; content.Kind != yaml.ScalarNode || content.Tag != "!!null" || content.Value != "" {
+			documents = append(documents, content)
+		}
+	}
+}
+
+// yamlTreeBuilder builds TreeNodes from decoded yaml.Nodes
+type yamlTreeBuilder struct {
+	options   BuildOptions
+	maxDepth  int
+	redact    *keyMatcher
+	allow     *keyMatcher
+	ancestors map[*yaml.Node]bool // Containers being built, which aliases must not expand again
+	nodes     int                 // Nodes built so far
+	aliased   int                 // Nodes built as copies of aliased or merged content
+	expanding int                 // Aliases and merges being expanded around the node being built
+}
+
+// countNode counts a node about to be built, which is a copy when it is an alias or
+// merged entry or lies below one, and rejects documents whose nodes are mostly
+// copies made by expanding aliases, such as the "billion laughs" attack, with the limits
+// yaml.v3 applies when decoding into Go values
+func (b *yamlTreeBuilder) countNode(copied bool) error {
+	b.nodes++
+	if copied || b.expanding > 0 {
+		b.aliased++
+	}
+	if b.aliased > 100 && b.nodes > 1000 && float64(b.aliased)/float64(b.nodes) > allowedAliasRatio(b.nodes) {
+		return fmt.Errorf("YAML document contains excessive aliasing")
+	}
+	return nil
+}
+
+// allowedAliasRatio returns the share of aliased nodes allowed in a document of n nodes:
+// almost all of a small document, falling to a tenth of a document of millions of nodes
+func allowedAliasRatio(n int) float64 {
+	switch {
+	case n <= 400000:
+		return 0.99
+	case n >= 4000000:
+		return 0.10
+	}
+	return 0.99 - 0.89*float64(n-400000)/3600000
+}
+
+// newYAMLTreeBuilder creates a builder, compiling the redaction patterns and falling
+// back to DefaultMaxParseDepth
+func newYAMLTreeBuilder(options BuildOptions) (*yamlTreeBuilder, error) {
+	maxDepth := options.MaxParseDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxParseDepth
+	}
+
+	redact, err := newKeyMatcher(options.RedactKeys)
+	if err != nil {
+		return nil, err
+	}
+	allow, err := newKeyMatcher(options.AllowKeys)
+	if err != nil {
+		return nil, err
+	}
+	return &yamlTreeBuilder{options: options, maxDepth: maxDepth, redact: redact, allow: allow, ancestors: make(map[*yaml.Node]bool)}, nil
+}
+
+// isRedacted reports whether the content below key is redacted, given whether its parent is
+func (b *yamlTreeBuilder) isRedacted(key string, parentRedacted bool) bool {
+	if b.allow.matches(key) {
+		return false
+	}
+	return parentRedacted || b.redact.matches(key)
+}
+
+// yamlMappingEntry is a key/value pair of a mapping after merge keys are resolved
+type yamlMappingEntry struct {
+	key       *yaml.Node
+	value     *yaml.Node
+	inherited bool // Set when the entry comes from a merged mapping
+}
+
+// build recursively builds a tree structure from a decoded yaml.Node, returning the
+// plain Go value of the subtree. Aliases become leaves unless ExpandAliases is set,
+// so shared content appears only once. Aliases referring to a container being built
+// would expand forever, so they become "cycle" leaves instead. Nesting deeper than the
+// builder's maximum depth is an error. Scalars below redacted keys are marked so their
+// values are masked.
+func (b *yamlTreeBuilder) build(node *TreeNode, yamlNode *yaml.Node, depth int, redacted bool) (interface{}, error) {
+	if depth > b.maxDepth {
+		return nil, fmt.Errorf("YAML nesting exceeds maximum depth of %d", b.maxDepth)
+	}
+
+	if yamlNode.Kind == yaml.MappingNode || yamlNode.Kind == yaml.SequenceNode {
+		b.ancestors[yamlNode] = true
+		defer delete(b.ancestors, yamlNode)
+	}
+
+	switch yamlNode.Kind {
+	case yaml.MappingNode:
+		// Handle objects
+		entries := mergedMappingEntries(yamlNode, make(map[*yaml.Node]bool))
+		value := make(map[string]interface{}, len(entries))
+		for _, entry := range entries {
+			key, valueNode := entry.key.Value, entry.value
+			expanded := entry.inherited || valueNode.Kind == yaml.AliasNode
+			if err := b.countNode(expanded); err != nil {
+				return nil, err
+			}
+			if expanded {
+				b.expanding++
+			}
+			childRedacted := b.isRedacted(key, redacted)
+			child := &TreeNode{
+				Name: key,
+				Data: YAMLNode{
+					Name: key, IsDir: true, NodeType: "object", Anchor: valueNode.Anchor, Line: entry.key.Line, Column: entry.key.Column,
+					HeadComment: entry.key.HeadComment, LineComment: yamlLineComment(entry.key, valueNode), Redacted: childRedacted,
+					Inherited: entry.inherited,
+				},
+				Children: nil,
+			}
+
+			if b.isCycle(valueNode) {
+				child.Data = YAMLNode{Name: key, NodeType: "cycle", Line: entry.key.Line, Column: entry.key.Column, Redacted: childRedacted}
+				value[key] = nil
+			} else if valueNode.Kind == yaml.AliasNode && (!b.options.ExpandAliases || b.isCycle(valueNode.Alias)) {
+				alias := b.newAliasNode(valueNode)
+				child.Children = append(child.Children, alias)
+				value[key] = alias.Data.(YAMLNode).Value
+			} else {
+				childValue, err := b.build(child, resolveYAMLAlias(valueNode), depth+1, childRedacted)
+				if err != nil {
+					return nil, err
+				}
+				value[key] = childValue
+			}
+			setYAMLContainerValue(child, value[key])
+			node.Children = append(node.Children, child)
+			if expanded {
+				b.expanding--
+			}
+		}
+		return value, nil
+	case yaml.SequenceNode:
+		// Handle arrays
+		value := make([]interface{}, 0, len(yamlNode.Content))
+		for i, item := range yamlNode.Content {
+			expanded := item.Kind == yaml.AliasNode
+			if err := b.countNode(expanded); err != nil {
+				return nil, err
+			}
+			if expanded {
+				if !b.options.ExpandAliases || b.isCycle(item.Alias) {
+					alias := b.newAliasNode(item)
+					aliasData := alias.Data.(YAMLNode)
+					aliasData.Item, aliasData.Index = true, i
+					alias.Data = aliasData
+					node.Children = append(node.Children, alias)
+					value = append(value, alias.Data.(YAMLNode).Value)
+					continue
+				}
+				item = resolveYAMLAlias(item)
+				b.expanding++
+			}
+
+			var itemValue interface{}
+			if item.Kind == yaml.ScalarNode {
+				itemValue = decodeYAMLScalar(item)
+			}
+
+			itemName := yamlItemName(itemValue, i)
+			if redacted && item.Kind == yaml.ScalarNode {
+				itemName = RedactionMask // Scalar items are named after their value
+			}
+			child := &TreeNode{
+				Name: itemName,
+				Data: YAMLNode{
+					Name: itemName, Value: itemValue, IsDir: false, NodeType: "scalar", ScalarKind: yamlScalarKind(item, itemValue), Item: true, Index: i,
+					Anchor: item.Anchor, Line: item.Line, Column: item.Column, HeadComment: item.HeadComment, LineComment: item.LineComment, Redacted: redacted,
+				},
+				Children: nil,
+			}
+			// Only recursively build if the item is a complex type (map or slice)
+			if item.Kind == yaml.MappingNode || item.Kind == yaml.SequenceNode {
+				var err error
+				if itemValue, err = b.build(child, item, depth+1, redacted); err != nil {
+					return nil, err
+				}
+				yamlData := child.Data.(YAMLNode)
+				yamlData.Value, yamlData.IsDir, yamlData.NodeType, yamlData.ScalarKind = itemValue, true, "object", ""
+				child.Data = yamlData
+				setYAMLContainerValue(child, itemValue)
+			}
+			node.Children = append(node.Children, child)
+			value = append(value, itemValue)
+			if expanded {
+				b.expanding--
+			}
+		}
+		return value, nil
+	default:
+		// Handle scalar values, keeping the anchor and position recorded for the key
+		value := decodeYAMLScalar(yamlNode)
+		yamlData, _ := node.Data.(YAMLNode)
+		yamlData.Name, yamlData.Value, yamlData.IsDir, yamlData.Redacted = node.Name, value, false, redacted
+		yamlData.NodeType, yamlData.ScalarKind = "scalar", yamlScalarKind(yamlNode, value)
+		node.Data = yamlData
+		return value, nil
+	}
+}
+
+// mergedMappingEntries returns the entries of a mapping with merge keys ("<<") resolved.
+// Merged entries take the place of the merge key, local keys win over merged ones, and
+// when merging a sequence of mappings the earlier mappings take precedence. Merged
+// entries are marked as inherited. merging holds the mappings whose merges are being
+// resolved, so a mapping merging itself through an alias is skipped.
+func mergedMappingEntries(mapping *yaml.Node, merging map[*yaml.Node]bool) []yamlMappingEntry {
+	merging[mapping] = true
+	defer delete(merging, mapping)
+
+	local := make(map[string]bool)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if !isYAMLMergeKey(mapping.Content[i]) {
+			local[mapping.Content[i].Value] = true
+		}
+	}
+
+	var entries []yamlMappingEntry
+	merged := make(map[string]bool)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if !isYAMLMergeKey(key) {
+			entries = append(entries, yamlMappingEntry{key: key, value: value})
+			continue
+		}
+
+		for _, source := range yamlMergeSources(value) {
+			if merging[source] {
+				continue
+			}
+			for _, entry := range mergedMappingEntries(source, merging) {
+				if local[entry.key.Value] || merged[entry.key.Value] {
+					continue
+				}
+				merged[entry.key.Value] = true
+				entry.inherited = true
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// isYAMLMergeKey reports whether a mapping key is the "<<" merge key
+func isYAMLMergeKey(key *yaml.Node) bool {
+	return key.Kind == yaml.ScalarNode && key.Value == "<<" && key.ShortTag() == "!!merge"
+}
+
+// yamlMergeSources returns the mappings referenced by a merge key's value, which is
+// either a mapping (or alias to one) or a sequence of them
+func yamlMergeSources(value *yaml.Node) []*yaml.Node {
+	value = resolveYAMLAlias(value)
+	switch value.Kind {
+	case yaml.MappingNode:
+		return []*yaml.Node{value}
+	case yaml.SequenceNode:
+		var sources []*yaml.Node
+		for _, item := range value.Content {
+			if item = resolveYAMLAlias(item); item.Kind == yaml.MappingNode {
+				sources = append(sources, item)
+			}
+		}
+		return sources
+	default:
+		return nil
+	}
+}
+
+// resolveYAMLAlias returns the node an alias refers to, or the node itself
+func resolveYAMLAlias(yamlNode *yaml.Node) *yaml.Node {
+	if yamlNode.Kind == yaml.AliasNode && yamlNode.Alias != nil {
+		return yamlNode.Alias
+	}
+	return yamlNode
+}
+
+// isCycle reports whether yamlNode is a container being built, which expanding again
+// would recurse forever
+func (b *yamlTreeBuilder) isCycle(yamlNode *yaml.Node) bool {
+	return yamlNode != nil && b.ancestors[yamlNode]
+}
+
+// newAliasNode creates a leaf for an alias, marked as a cycle when it refers to a
+// container being built
+func (b *yamlTreeBuilder) newAliasNode(aliasNode *yaml.Node) *TreeNode {
+	alias := newYAMLAliasNode(aliasNode)
+	if b.isCycle(aliasNode.Alias) {
+		aliasData := alias.Data.(YAMLNode)
+		aliasData.NodeType, aliasData.Value = "cycle", nil
+		alias.Data = aliasData
+	}
+	return alias
+}
+
+// newYAMLAliasNode creates a leaf for an alias reference such as "*defaults"
+func newYAMLAliasNode(aliasNode *yaml.Node) *TreeNode {
+	var value interface{}
+	if aliasNode.Alias != nil {
+		aliasNode.Alias.Decode(&value)
+	}
+
+	name := "*" + aliasNode.Value
+	return &TreeNode{
+		Name:     name,
+		Data:     YAMLNode{Name: name, Value: value, IsDir: false, NodeType: "alias", Alias: aliasNode.Value, Line: aliasNode.Line, Column: aliasNode.Column},
+		Children: nil,
+	}
+}
+
+// decodeYAMLScalar resolves a scalar node into its Go value (string, int, float64, bool or nil)
+func decodeYAMLScalar(yamlNode *yaml.Node) interface{} {
+	var value interface{}
+	if err := yamlNode.Decode(&value); err != nil {
+		return yamlNode.Value
+	}
+	return value
+}
+
+// yamlLineComment returns the comment at the end of a mapping entry's line, which
+// yaml.v3 attaches to the value for scalars and to the key for containers
+func yamlLineComment(key, value *yaml.Node) string {
+	if value.LineComment != "" {
+		return value.LineComment
+	}
+	return key.LineComment
+}
+
+// yamlScalarTagKinds maps the resolved tags of YAML scalars to their kind
+var yamlScalarTagKinds = map[string]string{
+	"!!str":       "string",
+	"!!int":       "int",
+	"!!float":     "float",
+	"!!bool":      "bool",
+	"!!null":      "null",
+	"!!timestamp": "timestamp",
+}
+
+// yamlScalarKind returns the kind of a scalar from its tag, so quoted numbers and values
+// tagged !!str are strings. Custom tags fall back to the kind of the decoded value.
+func yamlScalarKind(yamlNode *yaml.Node, value interface{}) string {
+	if kind, ok := yamlScalarTagKinds[yamlNode.ShortTag()]; ok {
+		return kind
+	}
+	return scalarKind(value)
+}
+
+// setYAMLContainerValue stores the plain Go value on a container node's YAMLNode data,
+// marking containers holding a sequence as arrays
+func setYAMLContainerValue(node *TreeNode, value interface{}) {
+	if yamlData, ok := node.Data.(YAMLNode); ok && yamlData.NodeType != "scalar" {
+		yamlData.Value = value
+		if _, isArray := value.([]interface{}); isArray && yamlData.NodeType == "object" {
+			yamlData.NodeType = "array"
+		}
+		node.Data = yamlData
+	}
+}
+
+// newValueYAMLNode describes a decoded value: maps become objects, slices arrays and
+// anything else a scalar with its kind recorded
+func newValueYAMLNode(name string, value interface{}, redacted bool) YAMLNode {
+	switch value.(type) {
+	case map[string]interface{}:
+		return YAMLNode{Name: name, Value: value, IsDir: true, NodeType: "object", Redacted: redacted}
+	case []interface{}:
+		return YAMLNode{Name: name, Value: value, IsDir: true, NodeType: "array", Redacted: redacted}
+	default:
+		return YAMLNode{Name: name, Value: value, IsDir: false, NodeType: "scalar", ScalarKind: scalarKind(value), Redacted: redacted}
+	}
+}
+
+// setTreeContainerValues fills in the plain Go values of container nodes built leaf by
+// leaf: a []interface{} when the children are array items, otherwise a map keyed by
+// child name in which groups win over leaves of the same name
+func setTreeContainerValues(node *TreeNode) interface{} {
+	yamlData := node.Data.(YAMLNode)
+	if !yamlData.IsDir {
+		return yamlData.Value
+	}
+
+	if len(node.Children) > 0 && node.Children[0].Data.(YAMLNode).Item {
+		items := make([]interface{}, len(node.Children))
+		for i, child := range node.Children {
+			items[i] = setTreeContainerValues(child)
+		}
+		setYAMLContainerValue(node, items)
+		return items
+	}
+
+	value := make(map[string]interface{}, len(node.Children))
+	for _, child := range node.Children {
+		childValue := setTreeContainerValues(child)
+		if _, exists := value[child.Name]; !exists || getIsDir(child.Data) {
+			value[child.Name] = childValue
+		}
+	}
+	setYAMLContainerValue(node, value)
+	return value
+}
+
+// yamlItemName names an array item after its scalar value, falling back to its index
+func yamlItemName(item interface{}, index int) string {
+	switch itemValue := item.(type) {
+	case string:
+		return itemValue
+	case int, int64, float64, json.Number:
+		return fmt.Sprintf("%v", itemValue)
+	case bool:
+		return fmt.Sprintf("%t", itemValue)
+	default:
+		return fmt.Sprintf("[%d]", index)
+	}
+}
+
+// ShowYAMLHierarchy displays YAML content as a tree structure. Keys keep their
+// document order unless WithKeyOrder(KeyOrderAlphabetical) is given.
+func ShowYAMLHierarchy(yamlContent []byte, opts ...BuildOption) error {
+	return showYAMLHierarchy(yamlContent, "root", opts)
+}
+
+// showYAMLHierarchy parses and displays YAML content with the root named rootName
+func showYAMLHierarchy(yamlContent []byte, rootName string, opts []BuildOption) error {
+	root, err := ParseYAMLToTreeNamed(yamlContent, rootName, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	options := newBuildOptions(opts)
+	if options.KeyOrder == KeyOrderAlphabetical {
+		sortTree(root)
+	}
+	renderLimitedTree(root, options)
+	return nil
+}
+
+// ShowYAMLHierarchyFromFile reads and displays a YAML file as a tree structure, up to the WithMaxReadSize limit.
+// The root is named after the file, as shown by WithShowRoot. A path of "-" reads standard input.
+func ShowYAMLHierarchyFromFile(filePath string, opts ...BuildOption) error {
+	file, err := openInput(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read YAML file: %w", err)
+	}
+	defer file.Close()
+
+	content, err := readLimited(file, newBuildOptions(opts))
+	if err != nil {
+		return fmt.Errorf("failed to read YAML: %w", err)
+	}
+
+	rootName := "root"
+	if filePath != StdinPath {
+		rootName = filepath.Base(filePath)
+	}
+	return showYAMLHierarchy(content, rootName, opts)
+}
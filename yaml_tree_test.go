@@ -0,0 +1,819 @@
+package palantir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// findChild returns the first child of node with the given name
+func findChild(node *TreeNode, name string) *TreeNode {
+	for _, child := range node.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func TestParseYAMLToTreeAliases(t *testing.T) {
+	yamlContent := []byte(`
+defaults: &defaults
+  adapter: postgres
+  host: localhost
+development:
+  database: *defaults
+test:
+  database: *defaults
+servers:
+  - *defaults
+`)
+
+	root, err := ParseYAMLToTree(yamlContent)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	defaults := findChild(root, "defaults")
+	if defaults == nil || len(defaults.Children) != 2 {
+		t.Fatalf("Expected the anchored mapping to be expanded once, got %+v", defaults)
+	}
+
+	for _, env := range []string{"development", "test"} {
+		section := findChild(root, env)
+		if section == nil {
+			t.Fatalf("Section %q not found", env)
+		}
+		database := findChild(section, "database")
+		if database == nil || len(database.Children) != 1 {
+			t.Fatalf("Expected %s.database to hold a single alias leaf, got %+v", env, database)
+		}
+
+		alias := database.Children[0]
+		if alias.Name != "*defaults" {
+			t.Errorf("Expected alias leaf named %q, got %q", "*defaults", alias.Name)
+		}
+		if len(alias.Children) != 0 {
+			t.Errorf("Expected alias to be a leaf, got %d children", len(alias.Children))
+		}
+
+		yamlNode, ok := alias.Data.(YAMLNode)
+		if !ok {
+			t.Fatal("Expected YAMLNode data on alias leaf")
+		}
+		if yamlNode.NodeType != "alias" || yamlNode.Alias != "defaults" {
+			t.Errorf("Expected alias node referencing %q, got %+v", "defaults", yamlNode)
+		}
+		// Programmatic consumers still get the resolved value
+		if value, ok := yamlNode.Value.(map[string]interface{}); !ok || value["adapter"] != "postgres" {
+			t.Errorf("Expected alias value to resolve to the anchored mapping, got %v", yamlNode.Value)
+		}
+	}
+
+	servers := findChild(root, "servers")
+	if servers == nil || len(servers.Children) != 1 || servers.Children[0].Name != "*defaults" {
+		t.Errorf("Expected aliased array item to render as alias leaf, got %+v", servers)
+	}
+}
+
+func TestShowYAMLHierarchyAliases(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	yamlContent := []byte(`
+base: &base
+  image: alpine
+web:
+  config: *base
+worker:
+  config: *base
+`)
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(yamlContent); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	if count := strings.Count(output, "*base → (ref)"); count != 2 {
+		t.Errorf("Expected 2 alias leaves, got %d in %q", count, output)
+	}
+	if count := strings.Count(output, "image"); count != 1 {
+		t.Errorf("Expected anchored content to appear once, got %d in %q", count, output)
+	}
+}
+
+var workflowYAML = []byte(`
+name: CI
+on:
+  push:
+    branches: [main]
+  pull_request: {}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - checkout
+      - build
+env:
+  GOFLAGS: -mod=mod
+`)
+
+func TestShowYAMLHierarchyOriginalKeyOrder(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	t.Cleanup(func() { SetGlobalOutputHandler(NewDefaultOutputHandler()) })
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(workflowYAML); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "├── name\n" +
+		"├── on\n" +
+		"│   ├── push\n" +
+		"│   │   └── branches\n" +
+		"│   │       └── main\n" +
+		"│   └── pull_request\n" +
+		"├── jobs\n" +
+		"│   └── test\n" +
+		"│       ├── runs-on\n" +
+		"│       └── steps\n" +
+		"│           ├── checkout\n" +
+		"│           └── build\n" +
+		"└── env\n" +
+		"    └── GOFLAGS\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchy() output = %q, want %q", output, expected)
+	}
+}
+
+func TestShowYAMLHierarchyAlphabeticalKeyOrder(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	t.Cleanup(func() { SetGlobalOutputHandler(NewDefaultOutputHandler()) })
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(workflowYAML, WithKeyOrder(KeyOrderAlphabetical)); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	// Alphabetical order matches the historical output: containers first, then leaves,
+	// while array items keep their order
+	expected := "├── env\n" +
+		"│   └── GOFLAGS\n" +
+		"├── jobs\n" +
+		"│   └── test\n" +
+		"│       ├── steps\n" +
+		"│       │   ├── checkout\n" +
+		"│       │   └── build\n" +
+		"│       └── runs-on\n" +
+		"├── on\n" +
+		"│   ├── pull_request\n" +
+		"│   └── push\n" +
+		"│       └── branches\n" +
+		"│           └── main\n" +
+		"└── name\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchy() output = %q, want %q", output, expected)
+	}
+}
+
+func TestParseYAMLToTreePreservesKeyOrder(t *testing.T) {
+	root, err := ParseYAMLToTree(workflowYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	expectedOrder := []string{"name", "on", "jobs", "env"}
+	if len(root.Children) != len(expectedOrder) {
+		t.Fatalf("Expected %d children, got %d", len(expectedOrder), len(root.Children))
+	}
+	for i, expected := range expectedOrder {
+		if root.Children[i].Name != expected {
+			t.Errorf("Expected child %d to be %q, got %q", i, expected, root.Children[i].Name)
+		}
+	}
+}
+
+// childNames returns the names of a node's children in order
+func childNames(node *TreeNode) []string {
+	names := make([]string, len(node.Children))
+	for i, child := range node.Children {
+		names[i] = child.Name
+	}
+	return names
+}
+
+func TestSortTreeKeepsYAMLArrayOrder(t *testing.T) {
+	yamlContent := []byte(`
+zoo:
+  animals:
+    - zebra
+    - alpha
+    - mango
+  keepers:
+    - name: zed
+      shift: night
+      age: 40
+    - name: amy
+      shift: day
+  matrix:
+    - [3, 1, 2]
+    - [c, a, b]
+  config:
+    zeta: 1
+    alpha: 2
+`)
+
+	root, err := ParseYAMLToTree(yamlContent)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+	sortTree(root)
+
+	zoo := findChild(root, "zoo")
+	if zoo == nil {
+		t.Fatal("zoo not found")
+	}
+
+	// Mapping children are still sorted alphabetically
+	if got := strings.Join(childNames(zoo), ","); got != "animals,config,keepers,matrix" {
+		t.Errorf("Expected sorted mapping keys, got %s", got)
+	}
+	if got := strings.Join(childNames(findChild(zoo, "config")), ","); got != "alpha,zeta" {
+		t.Errorf("Expected sorted nested mapping keys, got %s", got)
+	}
+
+	// Sequence items keep their document order
+	if got := strings.Join(childNames(findChild(zoo, "animals")), ","); got != "zebra,alpha,mango" {
+		t.Errorf("Expected array order to be preserved, got %s", got)
+	}
+
+	keepers := findChild(zoo, "keepers")
+	if got := strings.Join(childNames(keepers), ","); got != "[0],[1]" {
+		t.Errorf("Expected array of maps order to be preserved, got %s", got)
+	}
+	// Maps inside arrays are mappings, so their keys are sorted
+	if got := strings.Join(childNames(keepers.Children[0]), ","); got != "age,name,shift" {
+		t.Errorf("Expected keys of a map inside an array to be sorted, got %s", got)
+	}
+
+	matrix := findChild(zoo, "matrix")
+	if got := strings.Join(childNames(matrix.Children[0]), ","); got != "3,1,2" {
+		t.Errorf("Expected nested array order to be preserved, got %s", got)
+	}
+	if got := strings.Join(childNames(matrix.Children[1]), ","); got != "c,a,b" {
+		t.Errorf("Expected nested array order to be preserved, got %s", got)
+	}
+}
+
+func TestShowYAMLHierarchyAlphabeticalKeepsArrayOrder(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	yamlContent := []byte(`
+tables:
+  - zebra
+  - alpha
+name: zoo
+`)
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(yamlContent, WithKeyOrder(KeyOrderAlphabetical)); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "├── tables\n│   ├── zebra\n│   └── alpha\n└── name\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchy() output = %q, want %q", output, expected)
+	}
+}
+
+var anchorsYAML = []byte(`
+defaults: &defaults
+  adapter: postgres
+  host: localhost
+  pool: 5
+development:
+  <<: *defaults
+  database: dev_db
+production:
+  <<: *defaults
+  host: db.example.com
+  database: prod_db
+replicas:
+  - *defaults
+`)
+
+func TestShowYAMLHierarchyAnchorsAndMerges(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(anchorsYAML, WithShowValues()); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "├── defaults &defaults\n" +
+		"│   ├── adapter: postgres\n" +
+		"│   ├── host: localhost\n" +
+		"│   └── pool: 5\n" +
+		"├── development\n" +
+		"│   ├── adapter: postgres\n" +
+		"│   ├── host: localhost\n" +
+		"│   ├── pool: 5\n" +
+		"│   └── database: dev_db\n" +
+		"├── production\n" +
+		"│   ├── adapter: postgres\n" +
+		"│   ├── pool: 5\n" +
+		"│   ├── host: db.example.com\n" +
+		"│   └── database: prod_db\n" +
+		"└── replicas\n" +
+		"    └── *defaults → (ref)\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchy() output = %q, want %q", output, expected)
+	}
+	if strings.Contains(output, "<<") {
+		t.Errorf("Expected merge keys to be resolved, got %q", output)
+	}
+}
+
+func TestShowYAMLHierarchyExpandAliases(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(anchorsYAML, WithExpandAliases()); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	if strings.Contains(output, "&defaults") || strings.Contains(output, "(ref)") {
+		t.Errorf("Expected no anchor or reference markers when expanding aliases, got %q", output)
+	}
+	expectedReplicas := "└── replicas\n" +
+		"    └── [0] (object, 3 keys)\n" +
+		"        ├── adapter\n" +
+		"        ├── host\n" +
+		"        └── pool\n"
+	if !strings.HasSuffix(output, expectedReplicas) {
+		t.Errorf("Expected aliased array item to be expanded, got %q", output)
+	}
+}
+
+func TestParseYAMLToTreeMergeValues(t *testing.T) {
+	root, err := ParseYAMLToTree(anchorsYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	production := findChild(root, "production")
+	if production == nil {
+		t.Fatal("production not found")
+	}
+
+	value, ok := production.Data.(YAMLNode).Value.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected production value to be a map, got %T", production.Data.(YAMLNode).Value)
+	}
+	if value["host"] != "db.example.com" {
+		t.Errorf("Expected local host to override the merged one, got %v", value["host"])
+	}
+	if value["adapter"] != "postgres" {
+		t.Errorf("Expected adapter to be merged from defaults, got %v", value["adapter"])
+	}
+
+	defaults := findChild(root, "defaults")
+	if defaults.Data.(YAMLNode).Anchor != "defaults" {
+		t.Errorf("Expected defaults to record its anchor, got %q", defaults.Data.(YAMLNode).Anchor)
+	}
+}
+
+func TestShowYAMLHierarchyMultipleMerges(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	content := []byte(`base: &base
+  region: us-east
+  retries: 3
+network: &network
+  <<: *base
+  port: 80
+limits: &limits
+  retries: 5
+  memory: 512
+service:
+  <<: [*network, *limits]
+  name: api
+  port: 8080
+`)
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(content, WithShowValues(), WithShowMergeOrigins()); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	// Earlier mappings in a merge list win, nested merges resolve first, and local keys win over both
+	expected := "└── service\n" +
+		"    ├── region: us-east (inherited)\n" +
+		"    ├── retries: 3 (inherited)\n" +
+		"    ├── memory: 512 (inherited)\n" +
+		"    ├── name: api\n" +
+		"    └── port: 8080\n"
+	if !strings.HasSuffix(output, expected) {
+		t.Errorf("ShowYAMLHierarchy() output =\n%s\nwant suffix:\n%s", output, expected)
+	}
+	if !strings.Contains(output, "│   ├── region: us-east (inherited)\n│   ├── retries: 3 (inherited)\n│   └── port: 80\n") {
+		t.Errorf("Expected network to inherit from base, got:\n%s", output)
+	}
+}
+
+func TestShowYAMLHierarchyMergeOriginsOffByDefault(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowYAMLHierarchy(anchorsYAML, WithShowValues())
+	})
+
+	if strings.Contains(output, "(inherited)") {
+		t.Errorf("Expected no merge origins without ShowMergeOrigins, got %q", output)
+	}
+
+	root, err := ParseYAMLToTree(anchorsYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+	production := findChild(root, "production")
+	if !findChild(production, "adapter").Data.(YAMLNode).Inherited {
+		t.Error("Expected merged adapter to be marked as inherited")
+	}
+	if findChild(production, "host").Data.(YAMLNode).Inherited {
+		t.Error("Expected overriding host not to be marked as inherited")
+	}
+}
+
+func TestShowYAMLHierarchySelfReferentialAnchor(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	content := []byte("node: &node\n  name: leaf\n  child: *node\nlist: &list\n  - *list\n")
+	expected := "├── node\n" +
+		"│   ├── name\n" +
+		"│   └── child\n" +
+		"│       └── *node ↩ cycle\n" +
+		"└── list\n" +
+		"    └── *list ↩ cycle\n"
+
+	for name, opts := range map[string][]BuildOption{
+		"Expanded":   {WithExpandAliases()},
+		"References": nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			output := captureOutput(func() {
+				err = ShowYAMLHierarchy(content, opts...)
+			})
+			if err != nil {
+				t.Fatalf("ShowYAMLHierarchy() error = %v", err)
+			}
+
+			output = strings.ReplaceAll(strings.ReplaceAll(output, " &node", ""), " &list", "")
+			if output != expected {
+				t.Errorf("ShowYAMLHierarchy() output = %q, want %q", output, expected)
+			}
+		})
+	}
+
+	root, err := ParseYAMLToTree(content, WithExpandAliases())
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+	if cycle := root.FindByPath("node", "child", "*node"); cycle == nil || cycle.Data.(YAMLNode).NodeType != "cycle" {
+		t.Errorf("Expected a cycle node below child, got %+v", cycle)
+	}
+}
+
+func TestParseYAMLToTreeSelfMerge(t *testing.T) {
+	content := []byte("base: &base\n  port: 80\n  nested:\n    <<: *base\n    host: local\n  <<: *base\n")
+
+	root, err := ParseYAMLToTree(content)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	if names := childNames(root.FindByPath("base")); strings.Join(names, ",") != "port,nested" {
+		t.Errorf("Expected a mapping merging itself to keep its own keys, got %v", names)
+	}
+	nested := root.FindByPath("base", "nested")
+	if names := childNames(nested); strings.Join(names, ",") != "port,nested,host" {
+		t.Errorf("Expected nested to inherit from base, got %v", names)
+	}
+	if data := nested.ChildNamed("nested").Data.(YAMLNode); data.NodeType != "cycle" {
+		t.Errorf("Expected the inherited nested key to be a cycle, got %q", data.NodeType)
+	}
+}
+
+func TestParseYAMLToTreeExcessiveAliasing(t *testing.T) {
+	// Each level refers to the previous one ten times, so expanding the last one would
+	// build 10^9 nodes
+	var content strings.Builder
+	content.WriteString("l0: &l0 [lol, lol, lol, lol, lol, lol, lol, lol, lol, lol]\n")
+	for i := 1; i < 9; i++ {
+		fmt.Fprintf(&content, "l%d: &l%d [%s]\n", i, i, strings.TrimSuffix(strings.Repeat(fmt.Sprintf("*l%d, ", i-1), 10), ", "))
+	}
+
+	_, err := ParseYAMLToTree([]byte(content.String()), WithExpandAliases())
+	if err == nil || !strings.Contains(err.Error(), "excessive aliasing") {
+		t.Errorf("Expected an excessive aliasing error, got %v", err)
+	}
+
+	if _, err := ParseYAMLToTree([]byte(content.String())); err != nil {
+		t.Errorf("Expected aliases to parse when they are not expanded, got %v", err)
+	}
+}
+
+func TestParseYAMLToTreeNamed(t *testing.T) {
+	root, err := ParseYAMLToTreeNamed([]byte("server:\n  port: 8080\n"), "values.yaml")
+	if err != nil {
+		t.Fatalf("ParseYAMLToTreeNamed() error = %v", err)
+	}
+	if root.Name != "values.yaml" || root.Data.(YAMLNode).Name != "values.yaml" {
+		t.Errorf("Expected the root to be named values.yaml, got %q", root.Name)
+	}
+	if root.FindByPath("server", "port") == nil {
+		t.Error("Expected the content below the named root")
+	}
+
+	if root, _ := ParseYAMLToTree([]byte("a: 1\n")); root.Name != "root" {
+		t.Errorf("Expected ParseYAMLToTree to keep the default root name, got %q", root.Name)
+	}
+}
+
+func TestShowYAMLHierarchyFromFileRootName(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+	filePath := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(filePath, []byte("server:\n  port: 8080\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchyFromFile(filePath, WithShowRoot()); err != nil {
+			t.Errorf("ShowYAMLHierarchyFromFile() error = %v", err)
+		}
+	})
+	if expected := "values.yaml\n└── server\n    └── port\n"; output != expected {
+		t.Errorf("ShowYAMLHierarchyFromFile() = %q, want %q", output, expected)
+	}
+
+	output = captureOutput(func() {
+		ShowYAMLHierarchy([]byte("server:\n  port: 8080\n"), WithShowRoot())
+	})
+	if !strings.HasPrefix(output, "root\n") {
+		t.Errorf("Expected ShowYAMLHierarchy to keep the default root name, got %q", output)
+	}
+}
+
+var arrayIndicesYAML = []byte(`
+tables: [users, posts]
+jobs:
+  - name: build
+    image: golang
+  - name: test
+matrix:
+  - [linux, amd64]
+`)
+
+func TestShowYAMLHierarchyArrayItems(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	tests := []struct {
+		name     string
+		opts     []BuildOption
+		expected string
+	}{
+		{
+			name: "Default",
+			expected: "├── tables\n" +
+				"│   ├── users\n" +
+				"│   └── posts\n" +
+				"├── jobs\n" +
+				"│   ├── [0] (object, 2 keys)\n" +
+				"│   │   ├── name\n" +
+				"│   │   └── image\n" +
+				"│   └── [1] (object, 1 key)\n" +
+				"│       └── name\n" +
+				"└── matrix\n" +
+				"    └── [0] (array, 2 items)\n" +
+				"        ├── linux\n" +
+				"        └── amd64\n",
+		},
+		{
+			name: "With indices",
+			opts: []BuildOption{WithShowArrayIndices(), WithShowValues()},
+			expected: "├── tables\n" +
+				"│   ├── [0] users\n" +
+				"│   └── [1] posts\n" +
+				"├── jobs\n" +
+				"│   ├── [0] (object, 2 keys)\n" +
+				"│   │   ├── name: build\n" +
+				"│   │   └── image: golang\n" +
+				"│   └── [1] (object, 1 key)\n" +
+				"│       └── name: test\n" +
+				"└── matrix\n" +
+				"    └── [0] (array, 2 items)\n" +
+				"        ├── [0] linux\n" +
+				"        └── [1] amd64\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				if err := ShowYAMLHierarchy(arrayIndicesYAML, tt.opts...); err != nil {
+					t.Errorf("ShowYAMLHierarchy() error = %v", err)
+				}
+			})
+			if output != tt.expected {
+				t.Errorf("ShowYAMLHierarchy() =\n%s\nwant:\n%s", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStyleArrayItemDimsIndex(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	item := YAMLNode{Name: "users", Value: "users", NodeType: "scalar", Item: true, Index: 3}
+	styled := styleArrayItem(item, "users", BuildOptions{ShowArrayIndices: true})
+	if expected := ColorDim + "[3]" + ColorReset + " users"; styled != expected {
+		t.Errorf("styleArrayItem() = %q, want %q", styled, expected)
+	}
+}
+
+var lineNumbersYAML = []byte(`# Deployment values
+---
+redis:
+  host: cache
+  port: 6379
+replicas:
+  - primary
+  - name: replica
+`)
+
+func TestParseYAMLToTreeLineNumbers(t *testing.T) {
+	root, err := ParseYAMLToTree(lineNumbersYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+
+	redis := findChild(root, "redis")
+	replicas := findChild(root, "replicas")
+	tests := []struct {
+		name   string
+		node   *TreeNode
+		line   int
+		column int
+	}{
+		{"Mapping key", redis, 3, 1},
+		{"Scalar key", findChild(redis, "port"), 5, 3},
+		{"Scalar item", findChild(replicas, "primary"), 7, 5},
+		{"Object item", replicas.Children[1], 8, 5},
+		{"Nested key", findChild(replicas.Children[1], "name"), 8, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.node.Data.(YAMLNode)
+			if data.Line != tt.line || data.Column != tt.column {
+				t.Errorf("Expected position %d:%d, got %d:%d", tt.line, tt.column, data.Line, data.Column)
+			}
+		})
+	}
+}
+
+func TestShowYAMLHierarchyLineNumbers(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		err := ShowYAMLHierarchy(lineNumbersYAML, WithShowLineNumbers(), WithShowValues(), WithKeyOrder(KeyOrderAlphabetical))
+		if err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "├── redis:3\n" +
+		"│   ├── host:4: cache\n" +
+		"│   └── port:5: 6379\n" +
+		"└── replicas:6\n" +
+		"    ├── primary:7\n" +
+		"    └── [1]:8 (object, 1 key)\n" +
+		"        └── name:8: replica\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchy() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+var multiDocumentYAML = []byte(`# Services
+name: api
+port: 8080
+---
+name: worker
+replicas: 3
+---
+`)
+
+func TestParseYAMLToTreeMultipleDocuments(t *testing.T) {
+	root, err := ParseYAMLToTree(multiDocumentYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("Expected an item for each non-empty document, got %d children", len(root.Children))
+	}
+
+	tests := []struct {
+		name   string
+		node   *TreeNode
+		line   int
+		column int
+	}{
+		{"First document", root.Children[0], 2, 1},
+		{"Key in the first document", findChild(root.Children[0], "port"), 3, 1},
+		{"Second document", root.Children[1], 5, 1},
+		{"Key in the second document", findChild(root.Children[1], "replicas"), 6, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.node == nil {
+				t.Fatal("Expected the node to exist")
+			}
+			data := tt.node.Data.(YAMLNode)
+			if data.Line != tt.line || data.Column != tt.column {
+				t.Errorf("Expected position %d:%d within the stream, got %d:%d", tt.line, tt.column, data.Line, data.Column)
+			}
+		})
+	}
+
+	if data := root.Data.(YAMLNode); data.NodeType != "array" {
+		t.Errorf("Expected the root to hold the documents as an array, got %q", data.NodeType)
+	}
+}
+
+var commentedYAML = []byte(`# server settings
+server: # primary
+  # do not lower below 30s
+  timeout: 30s # seconds
+  hosts:
+    # first host
+    - a # inline a
+    - b
+name: app
+`)
+
+func TestShowYAMLHierarchyComments(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(commentedYAML, WithShowComments(), WithShowValues()); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "│   # server settings\n" +
+		"├── server # primary\n" +
+		"│   │   # do not lower below 30s\n" +
+		"│   ├── timeout: 30s # seconds\n" +
+		"│   └── hosts\n" +
+		"│       │   # first host\n" +
+		"│       ├── a # inline a\n" +
+		"│       └── b\n" +
+		"└── name: app\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchy() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowYAMLHierarchyCommentsOffByDefault(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowYAMLHierarchy(commentedYAML, WithKeyOrder(KeyOrderAlphabetical))
+	})
+
+	if strings.Contains(output, "#") {
+		t.Errorf("Expected no comments without WithShowComments, got %q", output)
+	}
+
+	// Comments stay on the nodes they belong to when sorting
+	root, err := ParseYAMLToTree(commentedYAML)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+	sortTree(root)
+	timeout := findChild(findChild(root, "server"), "timeout").Data.(YAMLNode)
+	if timeout.HeadComment != "# do not lower below 30s" || timeout.LineComment != "# seconds" {
+		t.Errorf("Expected comments on the timeout node, got %+v", timeout)
+	}
+}
@@ -0,0 +1,135 @@
+package palantir
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxValueLength is the number of characters after which displayed YAML values
+// are truncated unless WithMaxValueLength says otherwise
+const DefaultMaxValueLength = 64
+
+// formatYAMLValue renders a scalar value as display text that stays on a single tree
+// line. Control characters are stripped, newlines are escaped, and values longer than
+// maxLength are truncated with a "… (+N chars)" suffix. Multiline values whose escaped
+// form would be truncated are summarized as "(multiline, N lines)" instead.
+func formatYAMLValue(value interface{}, maxLength int) string {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		if v == "" {
+			return `""`
+		}
+		text = stripControlChars(v)
+	case time.Time:
+		text = formatTimeValue(v)
+	default:
+		text = stripControlChars(fmt.Sprintf("%v", v))
+	}
+
+	if strings.Contains(text, "\n") {
+		escaped := strings.ReplaceAll(text, "\n", `\n`)
+		if maxLength > 0 && utf8.RuneCountInString(escaped) > maxLength {
+			lines := strings.Count(strings.TrimSuffix(text, "\n"), "\n") + 1
+			return fmt.Sprintf("(multiline, %d lines)", lines)
+		}
+		text = escaped
+	}
+
+	if maxLength > 0 {
+		runes := []rune(text)
+		if len(runes) > maxLength {
+			text = fmt.Sprintf("%s… (+%d chars)", string(runes[:maxLength]), len(runes)-maxLength)
+		}
+	}
+	return text
+}
+
+// formatTimeValue formats a datetime as RFC 3339. TOML local dates, times and datetimes
+// decode with marker locations and are shown without the parts they lack.
+func formatTimeValue(t time.Time) string {
+	switch t.Location().String() {
+	case "date-local":
+		return t.Format(time.DateOnly)
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+// stripControlChars removes control characters such as ANSI escape bytes so values
+// cannot corrupt the terminal. Newlines are kept, and tabs become spaces.
+func stripControlChars(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n':
+			return r
+		case r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		default:
+			return r
+		}
+	}, text)
+}
+
+// scalarKind names the kind of a decoded scalar value, or returns an empty string for
+// values of other types
+func scalarKind(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "int"
+	case float32, float64:
+		return "float"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "float"
+		}
+		return "int"
+	case time.Time:
+		return "timestamp"
+	default:
+		return ""
+	}
+}
+
+// yamlValueColor picks the display color for a scalar value based on its type
+func yamlValueColor(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return ColorDim
+	case string:
+		return ColorGreen
+	case bool:
+		return ColorYellow
+	case time.Time:
+		return ColorPurple
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return ColorCyan
+	default:
+		return ""
+	}
+}
+
+// styleYAMLValue formats and colors a scalar value according to OutputConfig
+func styleYAMLValue(value interface{}, options BuildOptions) string {
+	text := formatYAMLValue(value, options.MaxValueLength)
+
+	return styleTreeText(text, yamlValueColor(value), false)
+}
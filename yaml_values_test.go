@@ -0,0 +1,267 @@
+package palantir
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// assertGolden compares output against testdata/<name>.golden, rewriting it when -update is set
+func assertGolden(t *testing.T, name string, output string) {
+	t.Helper()
+	goldenPath := filepath.Join("testdata", name+".golden")
+
+	if *updateGolden {
+		if err := os.WriteFile(goldenPath, []byte(output), 0644); err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if output != string(expected) {
+		t.Errorf("Output does not match %s\ngot:\n%s\nwant:\n%s", goldenPath, output, expected)
+	}
+}
+
+var valuesYAML = []byte(`
+server:
+  host: localhost
+  port: 8080
+  ratio: 0.75
+  debug: true
+  owner: ~
+  empty: ""
+  banner: |
+    Welcome
+    to palantir
+  features:
+    - authentication
+    - logging
+`)
+
+func TestShowYAMLHierarchyValues(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(valuesYAML, WithShowValues()); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	assertGolden(t, "yaml_values", output)
+}
+
+func TestShowYAMLHierarchyValuesTruncated(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(valuesYAML, WithShowValues(), WithMaxValueLength(10)); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	assertGolden(t, "yaml_values_truncated", output)
+}
+
+func TestShowYAMLHierarchyKeysOnlyByDefault(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	output := captureOutput(func() {
+		ShowYAMLHierarchy(valuesYAML)
+	})
+
+	if strings.Contains(output, "localhost") || strings.Contains(output, ":") {
+		t.Errorf("Expected keys-only output by default, got %q", output)
+	}
+}
+
+func TestStyleYAMLValueColors(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"String", "localhost", ColorGreen + "localhost" + ColorReset},
+		{"Integer", 8080, ColorCyan + "8080" + ColorReset},
+		{"Float", 0.75, ColorCyan + "0.75" + ColorReset},
+		{"Boolean", true, ColorYellow + "true" + ColorReset},
+		{"Null", nil, ColorDim + "null" + ColorReset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := styleYAMLValue(tt.value, BuildOptions{}); result != tt.expected {
+				t.Errorf("styleYAMLValue(%v) = %q, want %q", tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatYAMLValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		maxLength int
+		expected  string
+	}{
+		{"Plain string", "hello", 0, "hello"},
+		{"Empty string", "", 0, `""`},
+		{"Multiline string", "line one\nline two\n", 0, `line one\nline two\n`},
+		{"Truncated", "abcdefghij", 4, "abcd… (+6 chars)"},
+		{"Exact length", "abcd", 4, "abcd"},
+		{"Multibyte truncation", "héllo wörld", 5, "héllo… (+6 chars)"},
+		{"Null", nil, 0, "null"},
+		{"Multiline placeholder", "one\ntwo\nthree\n", 8, "(multiline, 3 lines)"},
+		{"Control characters", "a\x1b[2Jb\x07\tc", 0, "a[2Jb c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := formatYAMLValue(tt.value, tt.maxLength); result != tt.expected {
+				t.Errorf("formatYAMLValue() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShowYAMLHierarchySanitizesValues(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	blob := strings.Repeat("QUJDREVGR0hJSktMTU5PUFFSU1RVVldYWVo=", 143)[:5120]
+	certLines := strings.Repeat("    MIIBszCCAVmgAwIBAgIUFakeCertificateLine\n", 14)
+	content := []byte("cert: |\n" + certLines + "blob: " + blob + "\ncolor: \"\\e[31mred\\e[0m\"\n")
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(content, WithShowValues()); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := []string{
+		"cert: (multiline, 14 lines)",
+		"blob: " + blob[:DefaultMaxValueLength] + "… (+5056 chars)",
+		"color: [31mred[0m",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+	if strings.Contains(output, "\x1b") {
+		t.Errorf("Expected control characters to be stripped, got %q", output)
+	}
+
+	// Consumers of the tree still see the full values
+	root, err := ParseYAMLToTree(content)
+	if err != nil {
+		t.Fatalf("ParseYAMLToTree() error = %v", err)
+	}
+	if value := findChild(root, "blob").Data.(YAMLNode).Value; value != blob {
+		t.Errorf("Expected the full 5 KB value on the YAMLNode, got %d bytes", len(value.(string)))
+	}
+	if value := findChild(root, "color").Data.(YAMLNode).Value; value != "\x1b[31mred\x1b[0m" {
+		t.Errorf("Expected the raw value on the YAMLNode, got %q", value)
+	}
+}
+
+func TestShowYAMLHierarchyAlignValues(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	content := []byte("database:\n  host: localhost\n  port: 5432\n  max_connections: 100\n  pool:\n    idle: 5\n    lifetime_seconds: 300\nname: app\n")
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(content, WithShowValues(), WithAlignValues()); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "├── database\n" +
+		"│   ├── host:            localhost\n" +
+		"│   ├── port:            5432\n" +
+		"│   ├── max_connections: 100\n" +
+		"│   └── pool\n" +
+		"│       ├── idle:             5\n" +
+		"│       └── lifetime_seconds: 300\n" +
+		"└── name: app\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchy() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowYAMLHierarchyAlignValuesIgnoresANSI(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	output := captureOutput(func() {
+		ShowYAMLHierarchy([]byte("id: 1\nhostname: box\n"), WithShowValues(), WithAlignValues())
+	})
+
+	lines := strings.Split(strings.TrimSpace(stripANSI(output)), "\n")
+	if len(lines) != 2 || strings.Index(lines[0], "1") != strings.Index(lines[1], "box") {
+		t.Errorf("Expected values in one column, got:\n%s", stripANSI(output))
+	}
+}
+
+func TestShowYAMLHierarchyTypes(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: false}))
+
+	content := []byte(`port: 5432
+quoted: "5432"
+tagged: !!str 5432
+ratio: 0.5
+debug: false
+owner: ~
+created: 2024-05-01T12:00:00Z
+name: app
+tags: [web, 8080]
+`)
+
+	output := captureOutput(func() {
+		if err := ShowYAMLHierarchy(content, WithShowValues(), WithShowTypes()); err != nil {
+			t.Errorf("ShowYAMLHierarchy() error = %v", err)
+		}
+	})
+
+	expected := "├── port: 5432 (int)\n" +
+		"├── quoted: 5432 (string)\n" +
+		"├── tagged: 5432 (string)\n" +
+		"├── ratio: 0.5 (float)\n" +
+		"├── debug: false (bool)\n" +
+		"├── owner: null (null)\n" +
+		"├── created: 2024-05-01T12:00:00Z (timestamp)\n" +
+		"├── name: app (string)\n" +
+		"└── tags\n" +
+		"    ├── web (string)\n" +
+		"    └── 8080 (int)\n"
+	if output != expected {
+		t.Errorf("ShowYAMLHierarchy() =\n%s\nwant:\n%s", output, expected)
+	}
+}
+
+func TestShowYAMLHierarchyTypesWithoutValues(t *testing.T) {
+	SetGlobalOutputHandler(NewOutputHandler(&OutputConfig{UseColors: true}))
+	t.Cleanup(func() {
+		SetGlobalOutputHandler(NewDefaultOutputHandler())
+	})
+
+	output := captureOutput(func() {
+		ShowYAMLHierarchy([]byte("port: \"5432\"\n"), WithShowTypes())
+	})
+
+	if !strings.Contains(output, ColorDim+"(string)"+ColorReset) || strings.Contains(output, "5432") {
+		t.Errorf("Expected only a dimmed type after the key, got %q", output)
+	}
+}